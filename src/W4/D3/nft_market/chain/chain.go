@@ -0,0 +1,200 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"nftmarket/contract"
+	"nftmarket/internal/erc1271"
+	"nftmarket/internal/ethrpc"
+	"nftmarket/utils"
+	"nftmarket/wallet"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownChain 请求的链未配置
+var ErrUnknownChain = errors.New("unsupported chain")
+
+// MinFeeBumpPercent 节点接受同nonce替换交易要求的最低手续费涨幅
+const MinFeeBumpPercent = 10
+
+// Chain 单条链的节点、NFTMarket合约和结算钱包池
+type Chain struct {
+	ID              int64
+	Default         bool   // 默认链，未指定chain_id的请求和历史订单(chain_id为0)属于默认链
+	Confirmations   uint64 // 交易上链后需要等待的确认区块数
+	OwnerPrivateKey string // 合约owner私钥，用于管理白名单
+	Client          *ethrpc.Client
+	MarketAddress   common.Address
+	Market          *contract.NFTMarket
+	SignerPool      *wallet.Pool
+	Signatures      *erc1271.Verifier // 合约钱包签名校验，结果短时间缓存
+	StuckTxTimeout  time.Duration     // 结算交易未上链超过此时间后提高手续费替换
+	FeeBumpPercent  int64             // 替换交易的手续费涨幅(%)
+	MaxGasFeeCap    *big.Int          // 结算交易GasFeeCap上限，nil表示不限制
+}
+
+// CapFees 将手续费限制在MaxGasFeeCap以内，GasTipCap不超过GasFeeCap
+func (c *Chain) CapFees(gasFeeCap, gasTipCap *big.Int) (*big.Int, *big.Int) {
+	if c.MaxGasFeeCap != nil && gasFeeCap.Cmp(c.MaxGasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(c.MaxGasFeeCap)
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasFeeCap, gasTipCap
+}
+
+// Scope 将查询限定在本链的记录上，默认链同时包含chain_id为0的历史记录
+func (c *Chain) Scope(db *gorm.DB) *gorm.DB {
+	if c.Default {
+		return db.Where("chain_id IN ?", []int64{0, c.ID})
+	}
+	return db.Where("chain_id = ?", c.ID)
+}
+
+// Includes 判断chain_id为chainId的记录是否属于本链，默认链同时包含chain_id为0的历史记录
+func (c *Chain) Includes(chainId int64) bool {
+	return chainId == c.ID || (c.Default && chainId == 0)
+}
+
+// VerifySignature 校验signer对digest的签名，EOA通过ecrecover校验
+// 恢复出的地址不是signer且signer上有合约代码时(多签、智能合约钱包)，回退调用signer的ERC-1271 isValidSignature
+func (c *Chain) VerifySignature(ctx context.Context, signer common.Address, digest common.Hash, signatureHex string) (bool, error) {
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return false, err
+	}
+	if recovered, err := utils.RecoverHash(digest.Bytes(), signatureHex); err == nil && recovered == signer {
+		return true, nil
+	}
+	isContract, err := c.Signatures.IsContract(ctx, signer)
+	if err != nil || !isContract {
+		return false, err
+	}
+	return c.Signatures.IsValidSignature(ctx, signer, digest, signature)
+}
+
+// BlockByTxHash 等待交易上链并达到配置的确认数后返回所在区块
+func (c *Chain) BlockByTxHash(hash string) (*types.Block, error) {
+	txHash := common.HexToHash(hash)
+
+	// 设置最大重试次数和每次重试之间的等待时间,保证交易能够正常执行完毕再获取数据
+	maxRetries := 10
+	retryInterval := 5 * time.Second
+	for i := 0; i < maxRetries; i++ {
+		_, isPending, err := c.Client.TransactionByHash(context.Background(), txHash)
+		if err != nil {
+			log.Printf("Failed to get transaction by hash: %v", err)
+		}
+		if isPending {
+			log.Println("Transaction is not yet mined into a block")
+			time.Sleep(retryInterval)
+			continue
+		}
+		// 获取交易收据
+		receipt, err := c.Client.TransactionReceipt(context.Background(), txHash)
+		if err != nil {
+			if err == ethereum.NotFound {
+				log.Printf("Transaction receipt not found. Retrying in %v...", retryInterval)
+				time.Sleep(retryInterval)
+				continue
+			}
+			log.Printf("Failed to get transaction receipt: %v", err)
+			return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+		}
+		if err := c.WaitConfirmations(context.Background(), receipt); err != nil {
+			return nil, err
+		}
+		// 获取区块信息
+		block, err := c.Client.BlockByHash(context.Background(), receipt.BlockHash)
+		if err != nil {
+			log.Printf("Failed to get block by hash: %v", err)
+			return nil, fmt.Errorf("failed to get block by hash: %w", err)
+		}
+		return block, nil
+	}
+	return nil, fmt.Errorf("transaction %s not mined after %d retries", hash, maxRetries)
+}
+
+// WaitConfirmations 等待收据所在区块之后产生足够的确认区块，Confirmations为0或1时不等待
+func (c *Chain) WaitConfirmations(ctx context.Context, receipt *types.Receipt) error {
+	if c.Confirmations <= 1 {
+		return nil
+	}
+	target := receipt.BlockNumber.Uint64() + c.Confirmations - 1
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		head, err := c.Client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get block number: %w", err)
+		}
+		if head >= target {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Registry 按chain id索引已配置的链
+type Registry struct {
+	chains       map[int64]*Chain
+	defaultChain *Chain
+}
+
+// NewRegistry 创建链注册表，第一条链为默认链
+func NewRegistry(chains []*Chain) (*Registry, error) {
+	if len(chains) == 0 {
+		return nil, errors.New("no chain configured")
+	}
+	registry := &Registry{chains: make(map[int64]*Chain), defaultChain: chains[0]}
+	for i, c := range chains {
+		if _, ok := registry.chains[c.ID]; ok {
+			return nil, fmt.Errorf("duplicate chain id %d", c.ID)
+		}
+		c.Default = i == 0
+		registry.chains[c.ID] = c
+	}
+	return registry, nil
+}
+
+// Get 按chain id查找链，0表示默认链
+func (r *Registry) Get(id int64) (*Chain, error) {
+	if id == 0 {
+		return r.defaultChain, nil
+	}
+	c, ok := r.chains[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownChain, id)
+	}
+	return c, nil
+}
+
+// Default 默认链
+func (r *Registry) Default() *Chain {
+	return r.defaultChain
+}
+
+// All 按chain id排序的所有链
+func (r *Registry) All() []*Chain {
+	chains := make([]*Chain, 0, len(r.chains))
+	for _, c := range r.chains {
+		chains = append(chains, c)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ID < chains[j].ID })
+	return chains
+}
@@ -0,0 +1,113 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"nftmarket/internal/erc1271"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// 模拟合约钱包：32字节调用写入slot 0，其余调用返回slot 0，见internal/erc1271的测试
+const walletRuntime = "0x3660201460125760005460005260206000f35b60003560005500"
+
+// 任何调用都revert的合约
+const revertRuntime = "0x60006000fd"
+
+func TestVerifySignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eoa := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{eoa: {Balance: big.NewInt(1e18)}})
+	defer backend.Close()
+	client := backend.Client()
+	chainId, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nonce uint64
+	send := func(to *common.Address, data []byte) {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainId), &types.DynamicFeeTx{
+			ChainID: chainId, Nonce: nonce, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(1e11), Gas: 200000, To: to, Data: data,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.SendTransaction(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+		backend.Commit()
+		nonce++
+	}
+	deploy := func(runtime string) common.Address {
+		code := hexutil.MustDecode(runtime)
+		address := crypto.CreateAddress(eoa, nonce)
+		send(nil, append([]byte{0x60, byte(len(code)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}, code...))
+		return address
+	}
+	setResult := func(wallet common.Address, result [4]byte) {
+		word := make([]byte, 32)
+		copy(word, result[:])
+		send(&wallet, word)
+	}
+
+	wallet := deploy(walletRuntime)
+	setResult(wallet, erc1271.MagicValue)
+	wrongWallet := deploy(walletRuntime)
+	setResult(wrongWallet, [4]byte{0xff, 0xff, 0xff, 0xff})
+	reverting := deploy(revertRuntime)
+
+	digest := common.BytesToHash(accounts.TextHash([]byte("nftmarket bid\norder_id: 5\namount: 1000")))
+	signature, err := crypto.Sign(digest.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 钱包返回的v值为27/28
+	signature[crypto.RecoveryIDOffset] += 27
+	eoaSignature := hexutil.Encode(signature)
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		signer    common.Address
+		signature string
+		want      bool
+	}{
+		{"eoa", eoa, eoaSignature, true},
+		{"eoa wrong signer", crypto.PubkeyToAddress(other.PublicKey), eoaSignature, false},
+		// ecrecover恢复出eoa而不是钱包地址，回退到钱包的isValidSignature
+		{"contract magic value", wallet, eoaSignature, true},
+		{"contract non ecdsa signature", wallet, "0x0102", true},
+		{"contract wrong value", wrongWallet, eoaSignature, false},
+		{"contract revert", reverting, eoaSignature, false},
+	}
+	c := &Chain{Signatures: erc1271.NewVerifier(client, time.Minute)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := c.VerifySignature(context.Background(), tt.signer, digest, tt.signature)
+			if err != nil {
+				t.Fatalf("VerifySignature: %v", err)
+			}
+			if valid != tt.want {
+				t.Fatalf("valid = %v, want %v", valid, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.VerifySignature(context.Background(), eoa, digest, "not hex"); err == nil {
+		t.Fatal("VerifySignature accepted a malformed signature")
+	}
+}
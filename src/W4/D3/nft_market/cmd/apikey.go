@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"nftmarket/service"
+	"strconv"
+	"strings"
+)
+
+const apiKeyUsage = "usage: nftmarket apikey create <name> | apikey revoke <id> | apikey list"
+
+// runAPIKey 管理调用方API key
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+	switch args[0] {
+	case "create":
+		if len(args) < 2 {
+			return errors.New(apiKeyUsage)
+		}
+		key, apiKey, err := service.CreateAPIKey(strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created api key %d for %s\n%s\n(the key is shown only once, store it safely)\n", apiKey.Id, apiKey.Name, key)
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New(apiKeyUsage)
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errors.New(apiKeyUsage)
+		}
+		if err := service.RevokeAPIKey(id); err != nil {
+			return err
+		}
+		fmt.Printf("revoked api key %d\n", id)
+	case "list":
+		keys, err := service.ListAPIKeys()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-6s %-14s %-8s %s\n", "ID", "PREFIX", "STATUS", "NAME")
+		for _, key := range keys {
+			fmt.Printf("%-6d %-14s %-8s %s\n", key.Id, key.KeyPrefix, key.Status, key.Name)
+		}
+	default:
+		return errors.New(apiKeyUsage)
+	}
+	return nil
+}
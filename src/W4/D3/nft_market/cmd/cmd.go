@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 子命令名称 -> 执行函数
+var commands = map[string]func(args []string) error{
+	"whitelist": runWhiteList,
+	"openapi":   runOpenAPI,
+	"apikey":    runAPIKey,
+	"merkle":    runMerkle,
+	"migrate":   runMigrate,
+	"paytoken":  runPayToken,
+	"pubkey":    runPubKey,
+}
+
+// Execute 执行命令行子命令，args为去掉程序名后的参数
+func Execute(args []string) error {
+	run, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, available commands: %s", args[0], commandNames())
+	}
+	return run(args[1:])
+}
+
+func commandNames() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
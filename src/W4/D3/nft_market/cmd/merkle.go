@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nftmarket/internal/merkle"
+	"nftmarket/service"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const merkleUsage = "usage: nftmarket merkle build <addresses.csv> [out.json] | merkle import <dump.json> | merkle export <root> [out.json] | merkle proof <root> <address>"
+
+// runMerkle 管理白名单折扣的默克尔树，导入导出格式与OpenZeppelin StandardMerkleTree一致
+func runMerkle(args []string) error {
+	if len(args) == 0 {
+		return errors.New(merkleUsage)
+	}
+	switch args[0] {
+	case "build":
+		if len(args) != 2 && len(args) != 3 {
+			return errors.New(merkleUsage)
+		}
+		file, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		addresses, err := merkle.ParseAddresses(file)
+		if err != nil {
+			return err
+		}
+		tree, err := merkle.Build(addresses)
+		if err != nil {
+			return err
+		}
+		record, err := service.SaveMerkleTree(tree)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("saved merkle tree %s with %d addresses\n", record.Root, record.Size)
+		if len(args) == 3 {
+			return writeMerkleTree(args[2], tree)
+		}
+	case "import":
+		if len(args) != 2 {
+			return errors.New(merkleUsage)
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		tree, err := merkle.Load(data)
+		if err != nil {
+			return err
+		}
+		record, err := service.SaveMerkleTree(tree)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported merkle tree %s with %d addresses\n", record.Root, record.Size)
+	case "export":
+		if len(args) != 2 && len(args) != 3 {
+			return errors.New(merkleUsage)
+		}
+		tree, err := service.LoadMerkleTree(args[1])
+		if err != nil {
+			return err
+		}
+		out := ""
+		if len(args) == 3 {
+			out = args[2]
+		}
+		return writeMerkleTree(out, tree)
+	case "proof":
+		if len(args) != 3 || !common.IsHexAddress(args[2]) {
+			return errors.New(merkleUsage)
+		}
+		tree, err := service.LoadMerkleTree(args[1])
+		if err != nil {
+			return err
+		}
+		proof, err := tree.Proof(common.HexToAddress(args[2]))
+		if err != nil {
+			return err
+		}
+		for _, node := range proof {
+			fmt.Println(node.Hex())
+		}
+	default:
+		return errors.New(merkleUsage)
+	}
+	return nil
+}
+
+// writeMerkleTree 输出StandardMerkleTree格式的JSON，out为空时输出到标准输出
+func writeMerkleTree(out string, tree *merkle.Tree) error {
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", out)
+	return nil
+}
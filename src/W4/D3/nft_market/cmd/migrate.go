@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"nftmarket/db"
+	"nftmarket/global"
+	"strconv"
+	"time"
+)
+
+const migrateUsage = "usage: nftmarket migrate up | migrate down [steps] | migrate status | migrate to <version>"
+
+// runMigrate 执行嵌入的版本化SQL迁移，down默认回滚1个版本，to 0回滚全部
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := db.MigrateUp(global.DBEngine); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) == 2 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return errors.New(migrateUsage)
+			}
+		} else if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := db.MigrateDown(global.DBEngine, steps); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return errors.New(migrateUsage)
+		}
+		if err := db.MigrateTo(global.DBEngine, version); err != nil {
+			return err
+		}
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		statuses, err := db.Statuses(global.DBEngine)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-8s %-32s %-8s %s\n", "VERSION", "NAME", "STATUS", "APPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state = "applied"
+				appliedAt = time.Unix(status.AppliedAt, 0).Format(time.RFC3339)
+			}
+			// 数据库中存在而当前代码中没有的迁移
+			if status.Applied && status.Up == "" {
+				state = "unknown"
+			}
+			fmt.Printf("%-8d %-32s %-8s %s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+	version, err := db.CurrentVersion(global.DBEngine)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("database schema version: %d\n", version)
+	return nil
+}
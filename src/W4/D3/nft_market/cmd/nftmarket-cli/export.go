@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// runExport 按区块时间范围流式导出成交记录，写入文件或标准输出，用于对账
+func runExport(args []string) error {
+	fs, opts := newFlagSet("export")
+	chainId := fs.Int64("chain-id", 0, "只导出该链的成交")
+	from := fs.String("from", "", "开始日期(YYYY-MM-DD，UTC，含)或unix秒")
+	to := fs.String("to", "", "结束日期(YYYY-MM-DD，UTC，不含)或unix秒")
+	format := fs.String("format", "csv", "导出格式csv|jsonl")
+	fiat := fs.String("fiat", "", "按服务端配置的价格表换算法币价值，例如USD")
+	nft := fs.String("nft", "", "NFT合约地址")
+	payToken := fs.String("pay-token", "", "支付代币地址")
+	after := fs.String("after", "", "从该游标之后继续导出，格式为{block_timestamp}:{fill_id}")
+	out := fs.String("out", "", "输出文件，默认标准输出")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || *from == "" || *to == "" {
+		return errors.New("usage: nftmarket-cli export --from <date> --to <date> [--format csv|jsonl] [--fiat <currency>] [--out <file>]")
+	}
+	fromUnix, err := parseTime(*from)
+	if err != nil {
+		return err
+	}
+	toUnix, err := parseTime(*to)
+	if err != nil {
+		return err
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	query := url.Values{"from": {strconv.FormatInt(fromUnix, 10)}, "to": {strconv.FormatInt(toUnix, 10)}, "format": {*format}}
+	setQuery(query, "fiat", *fiat)
+	setQuery(query, "nft", *nft)
+	setQuery(query, "pay_token", *payToken)
+	setQuery(query, "after", *after)
+	if *chainId != 0 {
+		query.Set("chain_id", strconv.FormatInt(*chainId, 10))
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := c.ExportTrades(query, w); err != nil {
+		return fmt.Errorf("%w (resume with --after set to the last exported block_timestamp:fill_id)", err)
+	}
+	return nil
+}
+
+// parseTime 解析YYYY-MM-DD(UTC)格式的日期或unix秒
+func parseTime(value string) (int64, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return unix, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or unix seconds", value)
+	}
+	return date.Unix(), nil
+}
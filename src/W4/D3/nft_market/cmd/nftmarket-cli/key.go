@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// loadKey 从hex私钥或keystore文件读取签名私钥，同时指定时优先使用keystore
+func (o *options) loadKey() (*ecdsa.PrivateKey, common.Address, error) {
+	switch {
+	case o.keystore != "":
+		data, err := os.ReadFile(o.keystore)
+		if err != nil {
+			return nil, common.Address{}, fmt.Errorf("failed to read keystore: %w", err)
+		}
+		password, err := o.password()
+		if err != nil {
+			return nil, common.Address{}, err
+		}
+		key, err := keystore.DecryptKey(data, password)
+		if err != nil {
+			return nil, common.Address{}, fmt.Errorf("failed to decrypt keystore: %w", err)
+		}
+		return key.PrivateKey, key.Address, nil
+	case o.key != "":
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(o.key), "0x"))
+		if err != nil {
+			return nil, common.Address{}, fmt.Errorf("invalid private key: %w", err)
+		}
+		return key, crypto.PubkeyToAddress(key.PublicKey), nil
+	default:
+		return nil, common.Address{}, errors.New("a signing key is required, use --keystore or --key (NFTMARKET_KEYSTORE / NFTMARKET_PRIVATE_KEY)")
+	}
+}
+
+// password keystore密码，优先读取--password-file，去掉末尾换行
+func (o *options) password() (string, error) {
+	if o.passwordFile != "" {
+		data, err := os.ReadFile(o.passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	password, ok := os.LookupEnv("NFTMARKET_KEYSTORE_PASSWORD")
+	if !ok {
+		return "", errors.New("keystore password is required, use --password-file or NFTMARKET_KEYSTORE_PASSWORD")
+	}
+	return password, nil
+}
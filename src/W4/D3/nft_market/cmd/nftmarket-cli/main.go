@@ -0,0 +1,130 @@
+// nftmarket-cli nft_market接口的命令行客户端，订单和授权签名都在本地用keystore或hex私钥完成，私钥不会发送到服务端
+//
+//	go build -o nftmarket-cli ./cmd/nftmarket-cli
+//	nftmarket-cli list --chain-id 31337
+//	nftmarket-cli create --keystore ./seller.json --chain-id 31337 --nft 0x... --token-id 1 --pay-token 0x... --price 1000 --watch
+//	nftmarket-cli buy 2 --key 0x... --watch
+//	nftmarket-cli export --from 2025-03-01 --to 2025-04-01 --fiat USD --out trades.csv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"nftmarket/internal/client"
+	"os"
+	"sort"
+	"strings"
+)
+
+const usage = `usage: nftmarket-cli <command> [flags] [args]
+
+commands:
+  list      展示上架中的订单
+  order     查询单个订单
+  create    本地签名并上架订单
+  buy       购买订单
+  cancel    卖家签名撤单
+  trades    查询成交记录
+  export    导出成交记录(CSV或JSON Lines)
+
+通用参数(也可通过环境变量设置):
+  --server         服务地址，NFTMARKET_SERVER，默认http://127.0.0.1:8080
+  --api-key        API key，NFTMARKET_API_KEY
+  --output         输出格式table|json
+  --key            hex私钥，NFTMARKET_PRIVATE_KEY
+  --keystore       keystore文件，NFTMARKET_KEYSTORE
+  --password-file  keystore密码文件，未指定时读取NFTMARKET_KEYSTORE_PASSWORD
+
+执行 nftmarket-cli <command> --help 查看各命令的参数`
+
+// 子命令名称 -> 执行函数
+var commands = map[string]func(args []string) error{
+	"list":   runList,
+	"order":  runOrder,
+	"create": runCreate,
+	"buy":    runBuy,
+	"cancel": runCancel,
+	"trades": runTrades,
+	"export": runExport,
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q, available commands: %s\n", os.Args[1], commandNames())
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func commandNames() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// options 所有子命令共用的参数
+type options struct {
+	server       string
+	apiKey       string
+	output       string
+	key          string
+	keystore     string
+	passwordFile string
+}
+
+// newFlagSet 创建子命令的参数集合并注册通用参数
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts := &options{}
+	fs.StringVar(&opts.server, "server", envOr("NFTMARKET_SERVER", "http://127.0.0.1:8080"), "服务地址")
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("NFTMARKET_API_KEY"), "API key")
+	fs.StringVar(&opts.output, "output", "table", "输出格式table|json")
+	fs.StringVar(&opts.key, "key", os.Getenv("NFTMARKET_PRIVATE_KEY"), "hex私钥")
+	fs.StringVar(&opts.keystore, "keystore", os.Getenv("NFTMARKET_KEYSTORE"), "keystore文件")
+	fs.StringVar(&opts.passwordFile, "password-file", "", "keystore密码文件")
+	return fs, opts
+}
+
+// parseArgs 解析参数，允许参数出现在位置参数之后，例如 buy 2 --watch
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func (o *options) client() (*client.Client, error) {
+	if o.output != "table" && o.output != "json" {
+		return nil, fmt.Errorf("invalid output format %q, expected table or json", o.output)
+	}
+	return client.New(o.server, o.apiKey), nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
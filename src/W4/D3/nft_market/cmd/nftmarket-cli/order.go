@@ -0,0 +1,412 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"nftmarket/internal/client"
+	"nftmarket/internal/model"
+	"nftmarket/internal/permit"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// runList 展示上架中的订单
+func runList(args []string) error {
+	fs, opts := newFlagSet("list")
+	chainId := fs.Int64("chain-id", 0, "只展示该链的订单")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errors.New("usage: nftmarket-cli list [--chain-id <id>]")
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	orders, err := c.ListOrders(*chainId)
+	if err != nil {
+		return err
+	}
+	return printOrders(opts.output, orders)
+}
+
+// runOrder 查询单个订单，--watch时跟踪订单状态直到终态
+func runOrder(args []string) error {
+	fs, opts := newFlagSet("order")
+	watch := fs.Bool("watch", false, "跟踪订单状态直到成交、过期、失效或撤单")
+	interval := fs.Duration("interval", 5*time.Second, "--watch的轮询间隔")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: nftmarket-cli order <order id> [--watch]")
+	}
+	orderId, err := parseOrderId(positional[0])
+	if err != nil {
+		return err
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	if *watch {
+		return watchOrder(c, opts.output, orderId, *interval)
+	}
+	order, err := c.GetOrder(orderId)
+	if err != nil {
+		return err
+	}
+	return printOrder(opts.output, order)
+}
+
+// runCreate 在本地生成订单签名密钥对并签名订单，卖家地址同时对订单消息personal_sign，只提交签名和公钥
+func runCreate(args []string) error {
+	fs, opts := newFlagSet("create")
+	chainId := fs.Int64("chain-id", 0, "订单所在链(必填)，纳入订单签名")
+	nft := fs.String("nft", "", "NFT合约地址")
+	tokenId := fs.Int64("token-id", 0, "NFT编号")
+	payToken := fs.String("pay-token", "", "支付代币合约地址，ETH支付为"+validate.ETHFlag.Hex())
+	price := fs.String("price", "", "一口价订单价格，价格参数均可填写最小单位的整数或带代币符号的数量(如\"1.5 USDC\")")
+	deadline := fs.Int64("deadline", 0, "截止时间(unix秒)，不填时为当前时间加--expires")
+	expires := fs.Duration("expires", 24*time.Hour, "未指定--deadline时订单的有效时长")
+	orderType := fs.String("type", "", "订单类型fixed|dutch|english")
+	startPrice := fs.String("start-price", "", "荷兰拍起始价/英式拍保留价")
+	endPrice := fs.String("end-price", "", "荷兰拍最低价")
+	startTime := fs.Int64("start-time", 0, "拍卖开始时间(unix秒)")
+	endTime := fs.Int64("end-time", 0, "拍卖结束时间(unix秒)")
+	merkleRoot := fs.String("merkle-root", "", "白名单默克尔树根")
+	discountPrice := fs.String("discount-price", "", "白名单折扣价")
+	tokenStandard := fs.String("token-standard", "", "NFT标准erc721|erc1155，需与服务端ERC165检测结果一致，指定--amount时默认为erc1155")
+	amount := fs.Int64("amount", 0, "ERC1155订单的上架数量，--price和--discount-price为单价")
+	watch := fs.Bool("watch", false, "上架后跟踪订单状态直到终态")
+	interval := fs.Duration("interval", 5*time.Second, "--watch的轮询间隔")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errors.New("usage: nftmarket-cli create --chain-id <id> --nft <address> --token-id <id> --pay-token <address> --price <amount> [flags]")
+	}
+	// 服务端会把未指定的chain_id替换为默认链id，本地签名必须使用实际的chain_id
+	if *chainId <= 0 {
+		return errors.New("--chain-id is required, the sell order signature covers the chain id")
+	}
+	if !common.IsHexAddress(*nft) || !common.IsHexAddress(*payToken) {
+		return errors.New("--nft and --pay-token must be addresses")
+	}
+	if *merkleRoot != "" && *discountPrice == "" {
+		return errors.New("--discount-price is required with --merkle-root")
+	}
+	if *tokenStandard == "" && *amount > 0 {
+		*tokenStandard = model.TokenStandardERC1155
+	}
+	if *tokenStandard == model.TokenStandardERC1155 && *amount <= 0 {
+		return errors.New("--amount is required for erc1155 orders")
+	}
+	key, seller, err := opts.loadKey()
+	if err != nil {
+		return err
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	if *deadline == 0 {
+		*deadline = time.Now().Add(*expires).Unix()
+	}
+	// 本地签名的订单内容为最小单位的价格
+	if err := resolvePrices(c, *chainId, *payToken, price, startPrice, endPrice, discountPrice); err != nil {
+		return err
+	}
+	var rawPrice int64
+	if *price != "" {
+		if rawPrice, err = strconv.ParseInt(*price, 10, 64); err != nil {
+			return fmt.Errorf("invalid --price: %w", err)
+		}
+	}
+
+	// 与服务端CreateOrder组装SellOrder的规则保持一致，保证签名内容相同
+	sellOrder := model.SellOrder{
+		Seller:   seller.Hex(),
+		Nft:      common.HexToAddress(*nft).Hex(),
+		TokenId:  *tokenId,
+		PayToken: common.HexToAddress(*payToken).Hex(),
+		Price:    rawPrice,
+		Deadline: *deadline,
+		ChainId:  *chainId,
+	}
+	if *orderType != "" && *orderType != model.OrderTypeFixed {
+		sellOrder.OrderType = *orderType
+		sellOrder.StartPrice = *startPrice
+		sellOrder.EndPrice = *endPrice
+		sellOrder.StartTime = *startTime
+		sellOrder.EndTime = *endTime
+	}
+	if *merkleRoot != "" {
+		sellOrder.MerkleRoot = common.HexToHash(*merkleRoot).Hex()
+		sellOrder.DiscountPrice = *discountPrice
+	}
+	if *tokenStandard == model.TokenStandardERC1155 {
+		sellOrder.TokenStandard = *tokenStandard
+		sellOrder.Amount = *amount
+	}
+	sellerSignature, err := utils.SignPersonal(sellOrder.Message(), key)
+	if err != nil {
+		return err
+	}
+	// 订单签名密钥对只用于买家校验订单内容，每个订单在本地重新生成，私钥用后即丢弃
+	privateKey, publicKey, err := utils.GenKeyPair()
+	if err != nil {
+		return err
+	}
+	orderJson, err := json.Marshal(sellOrder)
+	if err != nil {
+		return err
+	}
+	signature, err := utils.Sign(string(orderJson), privateKey)
+	if err != nil {
+		return err
+	}
+
+	order, err := c.CreateOrder(model.SellOrderRequest{
+		PublicKey:       publicKey,
+		Signature:       signature,
+		Seller:          sellOrder.Seller,
+		NFT:             sellOrder.Nft,
+		TokenID:         sellOrder.TokenId,
+		PayToken:        sellOrder.PayToken,
+		Price:           sellOrder.Price,
+		Deadline:        sellOrder.Deadline,
+		OrderType:       *orderType,
+		StartPrice:      *startPrice,
+		EndPrice:        *endPrice,
+		StartTime:       *startTime,
+		EndTime:         *endTime,
+		ChainId:         sellOrder.ChainId,
+		MerkleRoot:      sellOrder.MerkleRoot,
+		DiscountPrice:   sellOrder.DiscountPrice,
+		SellerSignature: sellerSignature,
+		TokenStandard:   *tokenStandard,
+		Amount:          sellOrder.Amount,
+	})
+	if err != nil {
+		return err
+	}
+	if err := printOrder(opts.output, order); err != nil {
+		return err
+	}
+	if *watch {
+		return watchOrder(c, opts.output, order.OrderId, *interval)
+	}
+	return nil
+}
+
+// resolvePrices 把带代币符号的价格(如"1.5 USDC")按服务端登记的symbol和decimals换算为最小单位，整数价格保持不变
+func resolvePrices(c *client.Client, chainId int64, payToken string, prices ...*string) error {
+	var token *model.PayToken
+	for _, price := range prices {
+		if *price == "" {
+			continue
+		}
+		if _, ok := new(big.Int).SetString(*price, 10); ok {
+			continue
+		}
+		if token == nil {
+			tokens, err := c.PayTokens(chainId)
+			if err != nil {
+				return err
+			}
+			for i := range tokens {
+				if common.HexToAddress(tokens[i].Address) == common.HexToAddress(payToken) {
+					token = &tokens[i]
+				}
+			}
+			if token == nil {
+				return fmt.Errorf("pay token %s is not registered on chain %d", payToken, chainId)
+			}
+		}
+		amount, symbol, found := strings.Cut(strings.TrimSpace(*price), " ")
+		if !found || !strings.EqualFold(strings.TrimSpace(symbol), token.Symbol) {
+			return fmt.Errorf("price %q must be an integer in the smallest unit or an amount such as \"1.5 %s\"", *price, token.Symbol)
+		}
+		raw, err := utils.ParseUnits(amount, token.Decimals)
+		if err != nil {
+			return err
+		}
+		*price = raw.String()
+	}
+	return nil
+}
+
+// runBuy 购买订单，订单带白名单折扣时自动查询买家的默克尔证明，--permit2时在本地签名Permit2授权
+func runBuy(args []string) error {
+	fs, opts := newFlagSet("buy")
+	usePermit2 := fs.Bool("permit2", false, "附带本地签名的Permit2授权，买家无需提前approve市场合约")
+	market := fs.String("market", "", "订单所在链的NFTMarket合约地址，--permit2时必填")
+	chainId := fs.Int64("chain-id", 0, "订单所在链的chain id，多链之前的历史订单使用--permit2时必填")
+	permitTTL := fs.Duration("permit-ttl", 30*time.Minute, "Permit2签名的有效时长")
+	amount := fs.Int64("amount", 1, "ERC1155订单的购买数量")
+	watch := fs.Bool("watch", false, "结算中时跟踪订单状态直到终态")
+	interval := fs.Duration("interval", 5*time.Second, "--watch的轮询间隔")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: nftmarket-cli buy <order id> [--amount <n>] [--permit2 --market <address>] [--watch]")
+	}
+	orderId, err := parseOrderId(positional[0])
+	if err != nil {
+		return err
+	}
+	key, buyer, err := opts.loadKey()
+	if err != nil {
+		return err
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	order, err := c.GetOrder(orderId)
+	if err != nil {
+		return err
+	}
+	sellOrder := order.SellOrder
+	if *amount <= 0 || (!sellOrder.IsERC1155() && *amount != 1) {
+		return errors.New("--amount must be positive and is only supported for erc1155 orders")
+	}
+	request := client.BuyRequest{Buyer: buyer.Hex(), OrderId: orderId}
+	if sellOrder.IsERC1155() {
+		request.Amount = *amount
+	}
+
+	// 买家在白名单中时按折扣价成交，不在白名单中时按原价购买
+	if sellOrder.MerkleRoot != "" {
+		proof, err := c.MerkleProof(sellOrder.MerkleRoot, buyer.Hex())
+		switch {
+		case err == nil:
+			request.Proof = proof.Proof
+		case !client.IsNotFound(err):
+			return err
+		}
+	}
+	// 服务端按成交总价报出中继费用，签名确认后Permit2授权的金额为总价加中继费用
+	quote, err := c.Quote(model.QuoteRequest{Buyer: request.Buyer, OrderId: int(orderId), Amount: request.Amount, Proof: request.Proof})
+	if err != nil {
+		return err
+	}
+	quoteSignature, err := utils.SignPersonal(quote.Message, key)
+	if err != nil {
+		return err
+	}
+	request.Quote = &model.BuyQuote{Id: quote.Quote.Id, Signature: quoteSignature}
+	if quote.Quote.RelayerFee != "0" {
+		fmt.Fprintf(os.Stderr, "relayer fee %s (%s), total %s\n", quote.Quote.RelayerFee, quote.Quote.FeeMode, quote.Total)
+	}
+	price, _ := new(big.Int).SetString(quote.Total, 10)
+
+	if *usePermit2 {
+		// 荷兰拍的成交价格在结算时才确定，无法提前签名等额的授权
+		if sellOrder.OrderType != "" && sellOrder.OrderType != model.OrderTypeFixed {
+			return fmt.Errorf("--permit2 is only supported for fixed price orders, approve the pay token for %s orders instead", sellOrder.OrderType)
+		}
+		if common.HexToAddress(sellOrder.PayToken) == validate.ETHFlag {
+			return errors.New("--permit2 is not supported for ETH orders")
+		}
+		if !common.IsHexAddress(*market) {
+			return errors.New("--market is required with --permit2")
+		}
+		orderChainId := sellOrder.ChainId
+		if orderChainId == 0 {
+			orderChainId = *chainId
+		}
+		if orderChainId == 0 {
+			return errors.New("order has no chain_id, --chain-id is required with --permit2")
+		}
+		// Permit2使用无序nonce，随机生成即可
+		nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 248))
+		if err != nil {
+			return err
+		}
+		deadline := time.Now().Add(*permitTTL).Unix()
+		digest := permit.Permit2Digest(big.NewInt(orderChainId), common.HexToAddress(sellOrder.PayToken), price,
+			common.HexToAddress(*market), nonce, big.NewInt(deadline))
+		signature, err := utils.SignHash(digest.Bytes(), key)
+		if err != nil {
+			return err
+		}
+		request.Permit = &model.BuyPermit{Type: permit.TypePermit2, Deadline: deadline, Nonce: nonce.String(), Signature: signature}
+	}
+
+	order, err = c.Buy(request)
+	if err != nil {
+		return err
+	}
+	if err := printOrder(opts.output, order); err != nil {
+		return err
+	}
+	if *watch && !isFinal(order.Status) {
+		return watchOrder(c, opts.output, orderId, *interval)
+	}
+	return nil
+}
+
+// runCancel 卖家对撤单消息签名后撤单
+func runCancel(args []string) error {
+	fs, opts := newFlagSet("cancel")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("usage: nftmarket-cli cancel <order id>")
+	}
+	orderId, err := parseOrderId(positional[0])
+	if err != nil {
+		return err
+	}
+	key, seller, err := opts.loadKey()
+	if err != nil {
+		return err
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	order, err := c.GetOrder(orderId)
+	if err != nil {
+		return err
+	}
+	if common.HexToAddress(order.SellOrder.Seller) != seller {
+		fmt.Fprintf(os.Stderr, "warning: signing key %s is not the seller %s, the server may reject the cancel\n", seller.Hex(), order.SellOrder.Seller)
+	}
+	signature, err := utils.SignPersonal(order.CancelMessage(), key)
+	if err != nil {
+		return err
+	}
+	order, err = c.CancelOrder(orderId, signature)
+	if err != nil {
+		return err
+	}
+	return printOrder(opts.output, order)
+}
+
+func parseOrderId(value string) (int64, error) {
+	orderId, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || orderId <= 0 {
+		return 0, fmt.Errorf("invalid order id %q", value)
+	}
+	return orderId, nil
+}
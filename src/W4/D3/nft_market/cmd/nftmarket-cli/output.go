@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"nftmarket/internal/client"
+	"nftmarket/internal/model"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// isFinal 订单是否已处于终态，settling仍可能回滚为open
+func isFinal(status string) bool {
+	switch status {
+	case model.OrderStatusFilled, model.OrderStatusExpired, model.OrderStatusInvalidated, model.OrderStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// watchOrder 轮询订单状态，状态或ERC1155订单的剩余数量变化时输出，直到订单进入终态
+// json输出时每次变化输出一行订单JSON，便于管道处理
+func watchOrder(c *client.Client, output string, orderId int64, interval time.Duration) error {
+	var last string
+	for {
+		order, err := c.GetOrder(orderId)
+		if err != nil {
+			return err
+		}
+		current := order.Status
+		if order.SellOrder.IsERC1155() {
+			current = fmt.Sprintf("%s (%d/%d remaining)", order.Status, order.Remaining, order.SellOrder.Amount)
+		}
+		if current != last {
+			if output == "json" {
+				if err := json.NewEncoder(os.Stdout).Encode(order); err != nil {
+					return err
+				}
+			} else {
+				line := fmt.Sprintf("%s order %d %s", time.Now().Format(time.RFC3339), order.OrderId, current)
+				if order.FilledTxHash != nil {
+					line += " tx " + *order.FilledTxHash
+				}
+				if order.InvalidReason != "" {
+					line += ": " + order.InvalidReason
+				}
+				fmt.Println(line)
+			}
+			last = current
+		}
+		if isFinal(order.Status) {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+func printOrders(output string, orders []model.Order) error {
+	if output == "json" {
+		return printJSON(orders)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCHAIN\tTYPE\tNFT\tTOKEN\tAMOUNT\tPAY TOKEN\tPRICE\tSTATUS\tDEADLINE")
+	for _, order := range orders {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", order.OrderId, order.SellOrder.ChainId, orderType(order.SellOrder),
+			order.SellOrder.Nft, order.SellOrder.TokenId, orderAmount(order), order.SellOrder.PayToken, orderPrice(order), order.Status,
+			formatTime(order.SellOrder.Deadline))
+	}
+	return w.Flush()
+}
+
+func printOrder(output string, order *model.Order) error {
+	if output == "json" {
+		return printJSON(order)
+	}
+	sellOrder := order.SellOrder
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "order_id\t%d\n", order.OrderId)
+	fmt.Fprintf(w, "status\t%s\n", order.Status)
+	if order.InvalidReason != "" {
+		fmt.Fprintf(w, "invalid_reason\t%s\n", order.InvalidReason)
+	}
+	fmt.Fprintf(w, "chain_id\t%d\n", sellOrder.ChainId)
+	fmt.Fprintf(w, "type\t%s\n", orderType(sellOrder))
+	fmt.Fprintf(w, "seller\t%s\n", sellOrder.Seller)
+	fmt.Fprintf(w, "nft\t%s #%d\n", sellOrder.Nft, sellOrder.TokenId)
+	if sellOrder.IsERC1155() {
+		fmt.Fprintf(w, "token_standard\t%s\n", sellOrder.TokenStandard)
+		fmt.Fprintf(w, "amount\t%s\n", orderAmount(*order))
+	}
+	fmt.Fprintf(w, "pay_token\t%s\n", sellOrder.PayToken)
+	fmt.Fprintf(w, "price\t%s\n", orderPrice(*order))
+	if sellOrder.MerkleRoot != "" {
+		discountPrice := sellOrder.DiscountPrice
+		if order.PriceDisplay != nil {
+			discountPrice = displayOr(order.PriceDisplay.DiscountPrice, discountPrice)
+		}
+		fmt.Fprintf(w, "discount_price\t%s (merkle_root %s)\n", discountPrice, sellOrder.MerkleRoot)
+	}
+	if sellOrder.OrderType != "" && sellOrder.OrderType != model.OrderTypeFixed {
+		fmt.Fprintf(w, "start_time\t%s\n", formatTime(sellOrder.StartTime))
+		fmt.Fprintf(w, "end_time\t%s\n", formatTime(sellOrder.EndTime))
+	}
+	fmt.Fprintf(w, "deadline\t%s\n", formatTime(sellOrder.Deadline))
+	if order.Buyer != nil {
+		fmt.Fprintf(w, "buyer\t%s\n", *order.Buyer)
+	}
+	if order.FilledPrice != nil {
+		filledPrice := *order.FilledPrice
+		if order.PriceDisplay != nil {
+			filledPrice = displayOr(order.PriceDisplay.FilledPrice, filledPrice)
+		}
+		fmt.Fprintf(w, "filled_price\t%s\n", filledPrice)
+	}
+	if order.FilledTxHash != nil {
+		fmt.Fprintf(w, "filled_tx_hash\t%s\n", *order.FilledTxHash)
+	}
+	return w.Flush()
+}
+
+func printTrades(output string, trades []model.Trade) error {
+	if output == "json" {
+		return printJSON(trades)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ORDER\tCHAIN\tNFT\tTOKEN\tAMOUNT\tSELLER\tBUYER\tPRICE\tTX\tTIME")
+	for _, trade := range trades {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", trade.OrderId, trade.ChainId, trade.Nft, trade.TokenId, trade.Amount,
+			trade.Seller, trade.Buyer, trade.Price, trade.TxHash, formatTime(trade.BlockTimestamp))
+	}
+	return w.Flush()
+}
+
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func orderType(order model.SellOrder) string {
+	if order.OrderType == "" {
+		return model.OrderTypeFixed
+	}
+	return order.OrderType
+}
+
+// orderPrice 上架中的订单展示当前价格，其余展示挂单价格
+// orderPrice 当前价格，支付代币已登记时显示为"1.5 USDC"
+func orderPrice(order model.Order) string {
+	display := order.PriceDisplay
+	if display == nil {
+		display = &model.PriceDisplay{}
+	}
+	if order.CurrentPrice != "" {
+		return displayOr(display.CurrentPrice, order.CurrentPrice)
+	}
+	if order.SellOrder.OrderType != "" && order.SellOrder.OrderType != model.OrderTypeFixed {
+		return displayOr(display.StartPrice, order.SellOrder.StartPrice)
+	}
+	return displayOr(display.Price, fmt.Sprint(order.SellOrder.Price))
+}
+
+// displayOr 优先显示换算后的价格
+func displayOr(human, raw string) string {
+	if human != "" {
+		return human
+	}
+	return raw
+}
+
+// orderAmount ERC1155订单显示剩余数量/上架数量，ERC721订单为1
+func orderAmount(order model.Order) string {
+	if !order.SellOrder.IsERC1155() {
+		return "1"
+	}
+	return fmt.Sprintf("%d/%d", order.Remaining, order.SellOrder.Amount)
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).Format(time.RFC3339)
+}
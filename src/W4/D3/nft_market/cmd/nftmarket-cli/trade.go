@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// runTrades 查询成交记录，参数与/market/trades的查询参数一致
+func runTrades(args []string) error {
+	fs, opts := newFlagSet("trades")
+	chainId := fs.Int64("chain-id", 0, "只查询该链的成交")
+	nft := fs.String("nft", "", "NFT合约地址")
+	seller := fs.String("seller", "", "卖家地址")
+	buyer := fs.String("buyer", "", "买家地址")
+	payToken := fs.String("pay-token", "", "支付代币地址")
+	from := fs.Int64("from", 0, "区块时间下限(unix秒，含)")
+	to := fs.Int64("to", 0, "区块时间上限(unix秒，不含)")
+	limit := fs.Int("limit", 0, "返回条数，默认50，最大500")
+	offset := fs.Int("offset", 0, "偏移量")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errors.New("usage: nftmarket-cli trades [--chain-id <id>] [--nft <address>] [--seller <address>] [--buyer <address>] [--limit <n>]")
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	setQuery(query, "nft", *nft)
+	setQuery(query, "seller", *seller)
+	setQuery(query, "buyer", *buyer)
+	setQuery(query, "pay_token", *payToken)
+	for name, value := range map[string]int64{"chain_id": *chainId, "from": *from, "to": *to, "limit": int64(*limit), "offset": int64(*offset)} {
+		if value != 0 {
+			query.Set(name, strconv.FormatInt(value, 10))
+		}
+	}
+	trades, err := c.ListTrades(query)
+	if err != nil {
+		return err
+	}
+	return printTrades(opts.output, trades)
+}
+
+func setQuery(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"nftmarket/doc"
+	routers "nftmarket/routes"
+	"os"
+)
+
+const openAPIUsage = "usage: nftmarket openapi check|print"
+
+// runOpenAPI 核对或输出OpenAPI接口文档
+func runOpenAPI(args []string) error {
+	if len(args) != 1 {
+		return errors.New(openAPIUsage)
+	}
+	switch args[0] {
+	case "check":
+		if err := doc.CheckRoutes(routers.NewRouter().Routes()); err != nil {
+			return err
+		}
+		fmt.Println("openapi.yaml matches registered routes")
+	case "print":
+		_, err := os.Stdout.Write(doc.OpenAPI)
+		return err
+	default:
+		return errors.New(openAPIUsage)
+	}
+	return nil
+}
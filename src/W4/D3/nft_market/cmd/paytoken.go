@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nftmarket/global"
+	"nftmarket/service"
+	"strconv"
+)
+
+const payTokenUsage = "usage: nftmarket paytoken [--chain <chain id>] add|remove <token address> | paytoken [--chain <chain id>] list"
+
+// runPayToken 管理允许上架的支付代币，登记时从ERC20合约读取symbol和decimals
+// 未指定--chain时操作默认链
+func runPayToken(args []string) error {
+	var chainId int64
+	if len(args) >= 2 && args[0] == "--chain" {
+		var err error
+		if chainId, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return fmt.Errorf("invalid chain id %q", args[1])
+		}
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return errors.New(payTokenUsage)
+	}
+	tokenChain, err := global.Chains.Get(chainId)
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "add":
+		if len(args) != 2 {
+			return errors.New(payTokenUsage)
+		}
+		token, err := service.RegisterPayToken(context.Background(), tokenChain, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("registered %s (%s, %d decimals) on chain %d\n", token.Address, token.Symbol, token.Decimals, tokenChain.ID)
+	case "remove":
+		if len(args) != 2 {
+			return errors.New(payTokenUsage)
+		}
+		token, err := service.UnregisterPayToken(tokenChain, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("removed %s (%s) on chain %d\n", token.Address, token.Symbol, tokenChain.ID)
+	case "list":
+		tokens, err := service.PayTokens(tokenChain)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-44s %-10s %s\n", "ADDRESS", "SYMBOL", "DECIMALS")
+		for _, token := range tokens {
+			fmt.Printf("%-44s %-10s %d\n", token.Address, token.Symbol, token.Decimals)
+		}
+	default:
+		return errors.New(payTokenUsage)
+	}
+	return nil
+}
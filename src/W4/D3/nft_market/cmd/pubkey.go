@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"nftmarket/service"
+	"nftmarket/utils"
+)
+
+const pubKeyUsage = "usage: nftmarket pubkey migrate [--format <format>] [--dry-run] | pubkey convert [--format <format>] <public key>\n" +
+	"formats: pem (default), jwk, sec1, sec1-compressed, legacy"
+
+// runPubKey 转换卖家公钥的格式：migrate将订单中已保存的公钥转为统一格式，convert转换单个公钥
+func runPubKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New(pubKeyUsage)
+	}
+	command, args := args[0], args[1:]
+	formatName, dryRun := "", false
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			if i+1 >= len(args) {
+				return errors.New(pubKeyUsage)
+			}
+			formatName = args[i+1]
+			i++
+		case "--dry-run":
+			dryRun = true
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	format, err := utils.ParseKeyFormat(formatName)
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "migrate":
+		if len(rest) != 0 {
+			return errors.New(pubKeyUsage)
+		}
+		result, err := service.MigrateSellerPubKeys(format, dryRun)
+		if err != nil {
+			return err
+		}
+		action := "converted"
+		if dryRun {
+			action = "would convert"
+		}
+		fmt.Printf("scanned %d orders, %s %d to %s, %d invalid\n", result.Scanned, action, result.Converted, format, len(result.Invalid))
+		if len(result.Invalid) > 0 {
+			fmt.Printf("orders with unparseable public keys: %v\n", result.Invalid)
+		}
+	case "convert":
+		if len(rest) != 1 || dryRun {
+			return errors.New(pubKeyUsage)
+		}
+		pub, err := utils.ParsePublicKey(rest[0])
+		if err != nil {
+			return err
+		}
+		converted, err := utils.FormatPublicKey(pub, format)
+		if err != nil {
+			return err
+		}
+		fmt.Println(converted)
+	default:
+		return errors.New(pubKeyUsage)
+	}
+	return nil
+}
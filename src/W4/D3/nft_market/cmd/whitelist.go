@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"nftmarket/global"
+	"nftmarket/service"
+	"strconv"
+)
+
+const whiteListUsage = "usage: nftmarket whitelist [--chain <chain id>] add|remove <client address> | whitelist [--chain <chain id>] list"
+
+// runWhiteList 管理NFTMarket合约白名单，发送交易前会校验配置的私钥是否为合约Owner()
+// 未指定--chain时操作默认链
+func runWhiteList(args []string) error {
+	var chainId int64
+	if len(args) >= 2 && args[0] == "--chain" {
+		var err error
+		if chainId, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return fmt.Errorf("invalid chain id %q", args[1])
+		}
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return errors.New(whiteListUsage)
+	}
+	whiteListChain, err := global.Chains.Get(chainId)
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "add":
+		if len(args) != 2 {
+			return errors.New(whiteListUsage)
+		}
+		record, err := service.AddWhiteListClient(whiteListChain, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("added %s on chain %d, tx: %s\n", record.Client, whiteListChain.ID, record.AddTxHash)
+	case "remove":
+		if len(args) != 2 {
+			return errors.New(whiteListUsage)
+		}
+		record, err := service.RemoveWhiteListClient(whiteListChain, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("removed %s on chain %d, tx: %s\n", record.Client, whiteListChain.ID, record.RemoveTxHash)
+	case "list":
+		records, err := service.ListWhiteListClients(whiteListChain)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-44s %-8s %-8s %s\n", "CLIENT", "STATUS", "ONCHAIN", "INDEX")
+		for _, record := range records {
+			fmt.Printf("%-44s %-8s %-8t %d\n", record.Client, record.Status, record.OnChainActive, record.OnChainIndex)
+		}
+	default:
+		return errors.New(whiteListUsage)
+	}
+	return nil
+}
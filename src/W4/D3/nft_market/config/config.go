@@ -1,10 +1,21 @@
 package config
 
 import (
+	"context"
 	"log"
+	"math/big"
+	"nftmarket/chain"
 	"nftmarket/db"
 	"nftmarket/global"
+	"nftmarket/internal/ethrpc"
+	"nftmarket/internal/model"
+	"nftmarket/internal/pow"
+	"nftmarket/internal/validate"
+	"nftmarket/job"
+	"nftmarket/service"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/viper"
 )
 
@@ -20,20 +31,237 @@ func SetupDBEngine() {
 	}
 }
 
-func SetupEthClient() {
-	var err error
-	global.EthRpcClient, err = NewEthRpcClient()
+// SetupChains 连接所有配置的链，节点chain id与配置不一致时拒绝启动
+func SetupChains() {
+	chains, err := NewChains()
+	if err != nil {
+		log.Panic("config.NewChains error : ", err)
+	}
+	global.Chains, err = chain.NewRegistry(chains)
+	if err != nil {
+		log.Panic("chain.NewRegistry error : ", err)
+	}
+	// 多链之前登记的白名单属于默认链
+	err = global.DBEngine.Model(&model.WhiteList{}).Where("chain_id = 0").
+		Update("chain_id", global.Chains.Default().ID).Error
 	if err != nil {
-		log.Panic("config.NewEthRpcClient error : ", err)
+		log.Panic("backfill white list chain id error : ", err)
 	}
 }
 
-func SetupNFTMarketContract() {
-	var err error
-	global.Market, err = NewMarketContract()
+func SetupValidator() {
+	if err := validate.Register(); err != nil {
+		log.Panic("validate.Register error : ", err)
+	}
+}
+
+// SetupSignerPool 启动各链结算钱包池的余额监控
+func SetupSignerPool() {
+	interval := time.Duration(global.BlockChainConfig.SignerBalanceCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	for _, c := range global.Chains.All() {
+		go c.SignerPool.MonitorBalances(context.Background(), interval)
+	}
+}
+
+// RpcOptions 节点切换参数，未配置的使用ethrpc的默认值
+func RpcOptions() ethrpc.Options {
+	conf := global.BlockChainConfig
+	return ethrpc.Options{
+		Timeout:  time.Duration(conf.RpcTimeout) * time.Second,
+		Cooldown: time.Duration(conf.RpcCooldown) * time.Second,
+		MaxLag:   conf.RpcMaxLag,
+	}
+}
+
+// SetupRpcHealthCheck 定时检查每条链所有节点的区块高度和延迟，未配置间隔时默认15秒
+func SetupRpcHealthCheck() {
+	interval := time.Duration(global.BlockChainConfig.RpcHealthCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	for _, c := range global.Chains.All() {
+		go c.Client.MonitorHealth(context.Background(), interval)
+	}
+}
+
+// SetupPayTokens 将Market.PayTokens中配置的代币登记到每条链的支付代币表，已登记的跳过
+func SetupPayTokens() {
+	if global.MarketConfig == nil {
+		return
+	}
+	service.ImportPayTokens(context.Background(), global.MarketConfig.PayTokens)
+}
+
+// SetupSweeper 启动过期/失效订单清理任务
+func SetupSweeper() {
+	batchSize := 100
+	if conf := global.SweeperConfig; conf != nil && conf.BatchSize > 0 {
+		batchSize = conf.BatchSize
+	}
+	// 每条链独立清理本链的订单
+	for _, c := range global.Chains.All() {
+		sweeper, err := job.NewSweeper(global.DBEngine, c, SweeperInterval(), batchSize)
+		if err != nil {
+			log.Panic("job.NewSweeper error : ", err)
+		}
+		go sweeper.Run(context.Background())
+	}
+}
+
+// SweeperInterval 后台任务扫描间隔，未配置时默认60秒
+func SweeperInterval() time.Duration {
+	if conf := global.SweeperConfig; conf != nil && conf.Interval > 0 {
+		return time.Duration(conf.Interval) * time.Second
+	}
+	return 60 * time.Second
+}
+
+// SignatureCacheTTL 合约钱包签名校验结果的缓存时间，未配置时默认30秒，配置为负数时不缓存
+func SignatureCacheTTL() time.Duration {
+	if conf := global.MarketConfig; conf != nil && conf.SignatureCacheTTL != 0 {
+		return time.Duration(conf.SignatureCacheTTL) * time.Second
+	}
+	return 30 * time.Second
+}
+
+// StuckTxTimeout 结算交易未上链多久后提高手续费替换，未配置时默认180秒
+func StuckTxTimeout() time.Duration {
+	if conf := global.BlockChainConfig; conf != nil && conf.StuckTxTimeout > 0 {
+		return time.Duration(conf.StuckTxTimeout) * time.Second
+	}
+	return 180 * time.Second
+}
+
+// FeeBumpPercent 替换交易的手续费涨幅，节点要求替换交易的手续费至少提高10%
+func FeeBumpPercent() int64 {
+	if conf := global.BlockChainConfig; conf != nil && conf.FeeBumpPercent > chain.MinFeeBumpPercent {
+		return int64(conf.FeeBumpPercent)
+	}
+	return chain.MinFeeBumpPercent
+}
+
+// GrpcAddr gRPC服务监听地址，未配置时默认:9090
+func GrpcAddr() string {
+	if conf := global.GrpcConfig; conf != nil && conf.Addr != "" {
+		return conf.Addr
+	}
+	return ":9090"
+}
+
+// WebhookOptions webhook投递参数，未配置的项使用默认值：每10秒扫描，超时10秒，最多投递8次，重试间隔30秒起每次翻倍、最长1小时
+func WebhookOptions() service.WebhookOptions {
+	options := service.WebhookOptions{
+		Interval:    10 * time.Second,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 8,
+		RetryBase:   30 * time.Second,
+		RetryMax:    time.Hour,
+	}
+	conf := global.WebhookConfig
+	if conf == nil {
+		return options
+	}
+	if conf.Interval > 0 {
+		options.Interval = time.Duration(conf.Interval) * time.Second
+	}
+	if conf.Timeout > 0 {
+		options.Timeout = time.Duration(conf.Timeout) * time.Second
+	}
+	if conf.MaxAttempts > 0 {
+		options.MaxAttempts = conf.MaxAttempts
+	}
+	if conf.RetryBase > 0 {
+		options.RetryBase = time.Duration(conf.RetryBase) * time.Second
+	}
+	if conf.RetryMax > 0 {
+		options.RetryMax = time.Duration(conf.RetryMax) * time.Second
+	}
+	return options
+}
+
+// RelayerFeePolicy 中继费用策略，未配置时不收取；gas_plus模式默认预估200000 gas、按USD价格换算ERC20支付代币，报价默认有效2分钟
+func RelayerFeePolicy() service.RelayerFeePolicy {
+	policy := service.RelayerFeePolicy{Mode: model.RelayerFeeNone, GasEstimate: 200000, QuoteTTL: 2 * time.Minute, PriceCurrency: "USD"}
+	conf := global.RelayerFeeConfig
+	if conf == nil {
+		return policy
+	}
+	switch conf.Mode {
+	case "", model.RelayerFeeNone:
+	case model.RelayerFeeFlat, model.RelayerFeePercent, model.RelayerFeeGasPlus:
+		policy.Mode = conf.Mode
+	default:
+		log.Panicf("invalid RelayerFee.Mode %q", conf.Mode)
+	}
+	if conf.Flat != "" {
+		flat, err := parseWei(conf.Flat)
+		if err != nil {
+			log.Panic("invalid RelayerFee.Flat : ", err)
+		}
+		policy.Flat = flat
+	}
+	if conf.PercentBps < 0 || conf.PercentBps > 10000 || conf.GasMarkupBps < 0 {
+		log.Panic("invalid RelayerFee bps")
+	}
+	policy.PercentBps, policy.GasMarkupBps = conf.PercentBps, conf.GasMarkupBps
+	if conf.GasEstimate > 0 {
+		policy.GasEstimate = conf.GasEstimate
+	}
+	if conf.QuoteTTL > 0 {
+		policy.QuoteTTL = time.Duration(conf.QuoteTTL) * time.Second
+	}
+	if conf.PriceCurrency != "" {
+		policy.PriceCurrency = conf.PriceCurrency
+	}
+	return policy
+}
+
+// FiatPrices 成交导出使用的法币价格表，配置无效时拒绝启动
+func FiatPrices() service.FiatPriceTable {
+	table := make(service.FiatPriceTable, 0, len(global.FiatPriceConfig))
+	for _, conf := range global.FiatPriceConfig {
+		if conf.Currency == "" || !common.IsHexAddress(conf.PayToken) {
+			log.Panicf("invalid FiatPrices entry %+v", conf)
+		}
+		price, ok := new(big.Rat).SetString(conf.Price)
+		if !ok || price.Sign() < 0 {
+			log.Panicf("invalid FiatPrices.Price %q", conf.Price)
+		}
+		entry := service.FiatPrice{Currency: conf.Currency, ChainId: conf.ChainId, PayToken: common.HexToAddress(conf.PayToken), Price: price}
+		if conf.From != "" {
+			from, err := time.Parse(time.DateOnly, conf.From)
+			if err != nil {
+				log.Panic("invalid FiatPrices.From : ", err)
+			}
+			entry.From = from.Unix()
+		}
+		table = append(table, entry)
+	}
+	return table
+}
+
+// ProofOfWork 工作量证明签发器，未开启时返回nil
+func ProofOfWork() *pow.Issuer {
+	conf := global.PowConfig
+	if conf == nil || !conf.Enabled {
+		return nil
+	}
+	issuer, err := pow.NewIssuer([]byte(conf.Secret), pow.Options{
+		TTL:            time.Duration(conf.TTL) * time.Second,
+		BaseDifficulty: conf.BaseDifficulty,
+		MaxDifficulty:  conf.MaxDifficulty,
+		Window:         time.Duration(conf.Window) * time.Second,
+		Threshold:      conf.Threshold,
+		Step:           conf.Step,
+		Store:          service.PowStampStore{},
+	})
 	if err != nil {
-		log.Panic("config.NewEthRpcClient error : ", err)
+		log.Panic("pow.NewIssuer error : ", err)
 	}
+	return issuer
 }
 
 func SetupConfig() {
@@ -49,6 +277,38 @@ func SetupConfig() {
 	if err != nil {
 		log.Panic("ReadSection - BlockChain error : ", err)
 	}
+	err = conf.ReadSection("Sweeper", &global.SweeperConfig)
+	if err != nil {
+		log.Panic("ReadSection - Sweeper error : ", err)
+	}
+	err = conf.ReadSection("Market", &global.MarketConfig)
+	if err != nil {
+		log.Panic("ReadSection - Market error : ", err)
+	}
+	err = conf.ReadSection("RateLimit", &global.RateLimitConfig)
+	if err != nil {
+		log.Panic("ReadSection - RateLimit error : ", err)
+	}
+	err = conf.ReadSection("Grpc", &global.GrpcConfig)
+	if err != nil {
+		log.Panic("ReadSection - Grpc error : ", err)
+	}
+	err = conf.ReadSection("Webhook", &global.WebhookConfig)
+	if err != nil {
+		log.Panic("ReadSection - Webhook error : ", err)
+	}
+	err = conf.ReadSection("RelayerFee", &global.RelayerFeeConfig)
+	if err != nil {
+		log.Panic("ReadSection - RelayerFee error : ", err)
+	}
+	err = conf.ReadSection("FiatPrices", &global.FiatPriceConfig)
+	if err != nil {
+		log.Panic("ReadSection - FiatPrices error : ", err)
+	}
+	err = conf.ReadSection("Pow", &global.PowConfig)
+	if err != nil {
+		log.Panic("ReadSection - Pow error : ", err)
+	}
 }
 
 func NewConfig() (*Config, error) {
@@ -3,68 +3,143 @@ package config
 import (
 	"context"
 	"fmt"
-	"log"
+	"math/big"
+	"nftmarket/chain"
+	"nftmarket/config/setting"
 	"nftmarket/contract"
 	"nftmarket/global"
-	"time"
+	"nftmarket/internal/erc1271"
+	"nftmarket/internal/ethrpc"
+	"nftmarket/wallet"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-func NewEthRpcClient() (*ethclient.Client, error) {
-	client, err := ethclient.Dial(global.BlockChainConfig.RpcUrl)
+// chainConfigs 多链配置，未配置Chains时使用BlockChain中的单链配置
+func chainConfigs() []setting.ChainConfig {
+	conf := global.BlockChainConfig
+	if len(conf.Chains) > 0 {
+		return conf.Chains
+	}
+	return []setting.ChainConfig{{
+		ChainId:         conf.ChainId,
+		RpcUrl:          conf.RpcUrl,
+		RpcUrls:         conf.RpcUrls,
+		ContractAddress: conf.ContractAddress,
+		PrivateKey:      conf.PrivateKey,
+		OwnerPrivateKey: conf.OwnerPrivateKey,
+		Signers:         conf.Signers,
+		Confirmations:   conf.Confirmations,
+		MaxGasFeeCap:    conf.MaxGasFeeCap,
+	}}
+}
+
+// NewChains 连接所有配置的链，并核对节点返回的chain id与配置一致
+func NewChains() ([]*chain.Chain, error) {
+	var chains []*chain.Chain
+	for _, chainConf := range chainConfigs() {
+		c, err := NewChain(chainConf)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, c)
+	}
+	return chains, nil
+}
+
+// NewChain 根据单条链的配置创建节点连接、NFTMarket合约对象和结算钱包池
+// RpcUrl和RpcUrls中的节点只要有一个可以访问即可启动，返回的chain id与配置不一致时拒绝启动
+func NewChain(chainConf setting.ChainConfig) (*chain.Chain, error) {
+	var urls []string
+	for _, url := range append([]string{chainConf.RpcUrl}, chainConf.RpcUrls...) {
+		if url != "" {
+			urls = append(urls, url)
+		}
+	}
+	client, err := ethrpc.Dial(context.Background(), urls, RpcOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rpc of chain %d: %w", chainConf.ChainId, err)
+	}
+	chainID, err := client.VerifyChainID(context.Background(), chainConf.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	marketAddress := common.HexToAddress(chainConf.ContractAddress)
+	market, err := contract.NewNFTMarket(marketAddress, client)
 	if err != nil {
-		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+		return nil, fmt.Errorf("failed to instantiate NFTMarket contract on chain %s: %w", chainID, err)
 	}
-	return client, nil
+	pool, err := NewSignerPool(client, chainID, chainConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer pool on chain %s: %w", chainID, err)
+	}
+	maxGasFeeCap := chainConf.MaxGasFeeCap
+	if maxGasFeeCap == "" {
+		maxGasFeeCap = global.BlockChainConfig.MaxGasFeeCap
+	}
+	var feeCap *big.Int
+	if maxGasFeeCap != "" {
+		if feeCap, err = parseWei(maxGasFeeCap); err != nil {
+			return nil, fmt.Errorf("invalid MaxGasFeeCap of chain %s: %w", chainID, err)
+		}
+	}
+	ownerKey := chainConf.OwnerPrivateKey
+	if ownerKey == "" {
+		ownerKey = global.BlockChainConfig.OwnerPrivateKey
+	}
+	if ownerKey == "" {
+		ownerKey = privateKeyOf(chainConf)
+	}
+	return &chain.Chain{
+		ID:              chainID.Int64(),
+		Confirmations:   chainConf.Confirmations,
+		OwnerPrivateKey: ownerKey,
+		Client:          client,
+		MarketAddress:   marketAddress,
+		Market:          market,
+		SignerPool:      pool,
+		Signatures:      erc1271.NewVerifier(client, SignatureCacheTTL()),
+		StuckTxTimeout:  StuckTxTimeout(),
+		FeeBumpPercent:  FeeBumpPercent(),
+		MaxGasFeeCap:    feeCap,
+	}, nil
 }
 
-func NewMarketContract() (*contract.NFTMarket, error) {
-	market, err := contract.NewNFTMarket(common.HexToAddress(global.BlockChainConfig.ContractAddress), global.EthRpcClient)
+// NewSignerPool 创建结算钱包池，未配置Signers时使用PrivateKey作为唯一钱包
+func NewSignerPool(client *ethrpc.Client, chainID *big.Int, chainConf setting.ChainConfig) (*wallet.Pool, error) {
+	keys := chainConf.Signers
+	if len(keys) == 0 {
+		keys = global.BlockChainConfig.Signers
+	}
+	if len(keys) == 0 {
+		keys = []string{privateKeyOf(chainConf)}
+	}
+	minBalance, err := parseWei(global.BlockChainConfig.SignerMinBalance)
 	if err != nil {
-		log.Fatalf("Failed to instantiate NFTMarket contract: %v", err)
+		return nil, fmt.Errorf("invalid SignerMinBalance: %w", err)
 	}
-	return market, nil
+	refillThreshold, err := parseWei(global.BlockChainConfig.SignerRefillThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SignerRefillThreshold: %w", err)
+	}
+	return wallet.NewPool(client, chainID, keys, minBalance, refillThreshold)
 }
 
-func GetBlockByTxHash(hash string) (*types.Block, error) {
-	client := global.EthRpcClient
-	txHash := common.HexToHash(hash)
+func privateKeyOf(chainConf setting.ChainConfig) string {
+	if chainConf.PrivateKey != "" {
+		return chainConf.PrivateKey
+	}
+	return global.BlockChainConfig.PrivateKey
+}
 
-	// 设置最大重试次数和每次重试之间的等待时间,保证交易能够正常执行完毕再获取数据
-	maxRetries := 10
-	retryInterval := 5 * time.Second
-	var block *types.Block
-	for i := 0; i < maxRetries; i++ {
-		_, isPending, err := client.TransactionByHash(context.Background(), txHash)
-		if err != nil {
-			log.Printf("Failed to get transaction by hash: %v", err)
-		}
-		if isPending {
-			log.Println("Transaction is not yet mined into a block")
-			time.Sleep(retryInterval)
-			continue
-		}
-		// 获取交易收据
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err != nil {
-			if err == ethereum.NotFound {
-				log.Printf("Transaction receipt not found. Retrying in %v...", retryInterval)
-				time.Sleep(retryInterval)
-				continue
-			}
-			log.Printf("Failed to get transaction receipt: %v", err)
-			return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
-		}
-		// 获取区块信息
-		block, err = client.BlockByHash(context.Background(), receipt.BlockHash)
-		if err != nil {
-			log.Printf("Failed to get block by hash: %v", err)
-			return nil, fmt.Errorf("failed to get block by hash: %w", err)
-		}
+// parseWei 解析十进制wei数量，空字符串视为0
+func parseWei(value string) (*big.Int, error) {
+	if value == "" {
+		return new(big.Int), nil
+	}
+	wei, ok := new(big.Int).SetString(value, 10)
+	if !ok || wei.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not a valid wei amount", value)
 	}
-	return block, nil
+	return wei, nil
 }
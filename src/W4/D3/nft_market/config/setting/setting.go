@@ -9,10 +9,117 @@ type DbConfig struct {
 	Pwd      string
 	Sslmode  string
 	TimeZone string
+	// 启动时自动执行未执行的迁移，仅建议开发环境开启，生产环境通过migrate子命令执行
+	AutoMigrate bool
 }
 type BlockChainConfig struct {
 	RpcUrl          string
+	RpcUrls         []string // 备用节点，与RpcUrl一起按健康度切换
 	Address         string
 	PrivateKey      string
 	ContractAddress string
+	OwnerPrivateKey string // 合约owner私钥，用于管理白名单，为空时使用PrivateKey
+
+	Signers                    []string // 多个白名单后端钱包私钥，为空时使用PrivateKey
+	SignerMinBalance           string   // 钱包最低余额(wei)，低于此值不参与结算
+	SignerRefillThreshold      string   // 钱包充值告警阈值(wei)
+	SignerBalanceCheckInterval int      // 钱包余额检查间隔(秒)
+
+	StuckTxTimeout int    // 结算交易未上链超过此时间(秒)后提高手续费替换
+	FeeBumpPercent int    // 替换交易的手续费涨幅(%)，不低于节点要求的10%
+	MaxGasFeeCap   string // 结算交易GasFeeCap上限(wei)，为空不限制
+
+	RpcTimeout             int    // 单个节点读请求超时(秒)，超时后换节点重试
+	RpcCooldown            int    // 节点出错后暂停使用的时间(秒)，连续出错时翻倍
+	RpcMaxLag              uint64 // 节点区块高度落后超过此值时降低优先级
+	RpcHealthCheckInterval int    // 节点健康检查间隔(秒)
+
+	ChainId       int64         // 单链配置的chain id，为0时不校验
+	Confirmations uint64        // 单链配置的交易确认区块数
+	Chains        []ChainConfig // 多链配置，为空时使用上面的单链配置，第一条为默认链
+}
+
+// ChainConfig 单条链的配置，私钥为空时使用BlockChain中的同名配置
+type ChainConfig struct {
+	ChainId         int64 // 启动时与节点返回的chain id核对
+	RpcUrl          string
+	RpcUrls         []string // 备用节点
+	ContractAddress string
+	PrivateKey      string
+	OwnerPrivateKey string
+	Signers         []string
+	Confirmations   uint64 // 交易上链后需要等待的确认区块数
+	MaxGasFeeCap    string // 为空时使用BlockChain.MaxGasFeeCap
+}
+
+type SweeperConfig struct {
+	Interval  int // 扫描间隔(秒)
+	BatchSize int // 每批校验的订单数量
+}
+
+type MarketConfig struct {
+	MaxDeadlineDays int      // 订单截止时间距当前时间的最大天数
+	PayTokens       []string // 启动时登记到支付代币表的代币合约地址，ETH_FLAG始终支持
+	// 合约钱包ERC-1271签名校验结果的缓存时间(秒)，为0时使用默认值30秒，小于0时不缓存
+	SignatureCacheTTL int
+}
+
+type RateLimitConfig struct {
+	RequireAPIKey bool         // 是否要求调用方携带X-API-Key
+	Default       RouteLimit   // 未单独配置的接口使用的限流规则
+	Routes        []RouteLimit // 按接口配置的限流规则
+	// 可信反向代理的IP或CIDR，只有来自这些地址的请求才按X-Forwarded-For/X-Real-IP取客户端IP，为空时使用连接的对端IP
+	TrustedProxies []string
+}
+
+// RouteLimit 单个接口的令牌桶限流规则，Rate为每秒生成的令牌数，0表示不限流
+type RouteLimit struct {
+	Path     string
+	KeyRate  float64 // 每个API key的限流
+	KeyBurst int
+	IPRate   float64 // 每个IP的限流
+	IPBurst  int
+}
+
+type GrpcConfig struct {
+	Addr string // gRPC服务监听地址，与REST接口使用不同端口
+}
+
+type WebhookConfig struct {
+	Interval    int // 扫描待重试投递的间隔(秒)
+	Timeout     int // 单次投递的请求超时(秒)
+	MaxAttempts int // 最大投递次数，用完后移入死信表
+	RetryBase   int // 第一次重试的等待时间(秒)，之后每次翻倍
+	RetryMax    int // 重试等待时间上限(秒)
+}
+
+type RelayerFeeConfig struct {
+	Mode          string // none、flat、percent或gas_plus
+	Flat          string // flat模式的每笔费用
+	PercentBps    int64  // percent模式按成交价格收取的万分比
+	GasMarkupBps  int64  // gas_plus模式在预估gas费用上加收的万分比
+	GasEstimate   uint64 // gas_plus模式预估的结算交易gas用量
+	QuoteTTL      int    // 报价有效期(秒)
+	PriceCurrency string // gas_plus模式换算ERC20支付代币使用的FiatPrices币种，默认USD
+}
+
+// PowConfig 上架和生成密钥对接口的工作量证明，难度为SHA-256十六进制哈希开头0的个数
+type PowConfig struct {
+	Enabled        bool
+	Secret         string // 挑战MAC密钥，为空时每次启动随机生成，多实例部署时需配置相同的值
+	TTL            int    // 挑战有效期(秒)
+	BaseDifficulty int    // 基础难度
+	MaxDifficulty  int    // 难度上限
+	Window         int    // 统计每个调用方已使用印章数的时间窗口(秒)
+	Threshold      int    // 窗口内使用的印章超过此数量后提高难度
+	Step           int    // 超过Threshold后每多使用Step个印章难度加1
+}
+
+// FiatPriceConfig 成交导出时换算法币价值使用的价格，同一币种和代币可配置多条，按生效日期取成交时最新的一条
+type FiatPriceConfig struct {
+	Currency string // 法币币种，如USD
+	ChainId  int64  // 为0时适用于所有链
+	PayToken string // 支付代币地址，ETH为零地址
+	Price    string // 每个完整代币的价格(十进制小数)
+	From     string // 生效日期(YYYY-MM-DD，UTC)，为空时始终生效
 }
@@ -0,0 +1,7 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+// WETH9中需要用到的方法，ETH订单从买家的WETH扣款后解包
+interface IWETH {
+    function withdraw(uint256 amount) external;
+}
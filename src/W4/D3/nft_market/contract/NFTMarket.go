@@ -29,9 +29,24 @@ var (
 	_ = abi.ConvertType
 )
 
+// NFTMarketPermit2Signature is an auto generated low-level Go binding around an user-defined struct.
+type NFTMarketPermit2Signature struct {
+	Nonce     *big.Int
+	Deadline  *big.Int
+	Signature []byte
+}
+
+// NFTMarketPermitSignature is an auto generated low-level Go binding around an user-defined struct.
+type NFTMarketPermitSignature struct {
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
 // NFTMarketMetaData contains all meta data concerning the NFTMarket contract.
 var NFTMarketMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"SafeERC20FailedOperation\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"ETH_FLAG\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"}],\"name\":\"buyNFTForOffline\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"client\",\"type\":\"address\"}],\"name\":\"cancelWhiteListSigner\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"client\",\"type\":\"address\"}],\"name\":\"setWhiteList\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"whiteList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"weth\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token\",\"type\":\"address\"}],\"name\":\"SafeERC20FailedOperation\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"ETH_FLAG\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"PERMIT2\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"WETH\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"relayerFee\",\"type\":\"uint256\"}],\"name\":\"buyERC1155ForOffline\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"relayerFee\",\"type\":\"uint256\"},{\"components\":[{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}],\"internalType\":\"structNFTMarket.PermitSignature\",\"name\":\"permit\",\"type\":\"tuple\"}],\"name\":\"buyERC1155WithPermit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"relayerFee\",\"type\":\"uint256\"},{\"components\":[{\"internalType\":\"uint256\",\"name\":\"nonce\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"}],\"internalType\":\"structNFTMarket.Permit2Signature\",\"name\":\"permit\",\"type\":\"tuple\"}],\"name\":\"buyERC1155WithPermit2\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"relayerFee\",\"type\":\"uint256\"}],\"name\":\"buyNFTForOffline\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"relayerFee\",\"type\":\"uint256\"},{\"components\":[{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}],\"internalType\":\"structNFTMarket.PermitSignature\",\"name\":\"permit\",\"type\":\"tuple\"}],\"name\":\"buyNFTWithPermit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"buyer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"seller\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"nft\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"payToken\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"price\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"relayerFee\",\"type\":\"uint256\"},{\"components\":[{\"internalType\":\"uint256\",\"name\":\"nonce\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"}],\"internalType\":\"structNFTMarket.Permit2Signature\",\"name\":\"permit\",\"type\":\"tuple\"}],\"name\":\"buyNFTWithPermit2\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"client\",\"type\":\"address\"}],\"name\":\"cancelWhiteListSigner\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"client\",\"type\":\"address\"}],\"name\":\"setWhiteList\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"whiteList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"stateMutability\":\"payable\",\"type\":\"receive\"}]",
 }
 
 // NFTMarketABI is the input ABI used to generate the binding from.
@@ -211,6 +226,68 @@ func (_NFTMarket *NFTMarketCallerSession) ETHFLAG() (common.Address, error) {
 	return _NFTMarket.Contract.ETHFLAG(&_NFTMarket.CallOpts)
 }
 
+// PERMIT2 is a free data retrieval call binding the contract method 0x6afdd850.
+//
+// Solidity: function PERMIT2() view returns(address)
+func (_NFTMarket *NFTMarketCaller) PERMIT2(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _NFTMarket.contract.Call(opts, &out, "PERMIT2")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// PERMIT2 is a free data retrieval call binding the contract method 0x6afdd850.
+//
+// Solidity: function PERMIT2() view returns(address)
+func (_NFTMarket *NFTMarketSession) PERMIT2() (common.Address, error) {
+	return _NFTMarket.Contract.PERMIT2(&_NFTMarket.CallOpts)
+}
+
+// PERMIT2 is a free data retrieval call binding the contract method 0x6afdd850.
+//
+// Solidity: function PERMIT2() view returns(address)
+func (_NFTMarket *NFTMarketCallerSession) PERMIT2() (common.Address, error) {
+	return _NFTMarket.Contract.PERMIT2(&_NFTMarket.CallOpts)
+}
+
+// WETH is a free data retrieval call binding the contract method 0xad5c4648.
+//
+// Solidity: function WETH() view returns(address)
+func (_NFTMarket *NFTMarketCaller) WETH(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _NFTMarket.contract.Call(opts, &out, "WETH")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// WETH is a free data retrieval call binding the contract method 0xad5c4648.
+//
+// Solidity: function WETH() view returns(address)
+func (_NFTMarket *NFTMarketSession) WETH() (common.Address, error) {
+	return _NFTMarket.Contract.WETH(&_NFTMarket.CallOpts)
+}
+
+// WETH is a free data retrieval call binding the contract method 0xad5c4648.
+//
+// Solidity: function WETH() view returns(address)
+func (_NFTMarket *NFTMarketCallerSession) WETH() (common.Address, error) {
+	return _NFTMarket.Contract.WETH(&_NFTMarket.CallOpts)
+}
+
 // Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
 //
 // Solidity: function owner() view returns(address)
@@ -273,25 +350,130 @@ func (_NFTMarket *NFTMarketCallerSession) WhiteList(arg0 common.Address) (*big.I
 	return _NFTMarket.Contract.WhiteList(&_NFTMarket.CallOpts, arg0)
 }
 
-// BuyNFTForOffline is a paid mutator transaction binding the contract method 0xcb20252b.
+// BuyERC1155ForOffline is a paid mutator transaction binding the contract method 0x98034ab7.
+//
+// Solidity: function buyERC1155ForOffline(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee) returns()
+func (_NFTMarket *NFTMarketTransactor) BuyERC1155ForOffline(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int) (*types.Transaction, error) {
+	return _NFTMarket.contract.Transact(opts, "buyERC1155ForOffline", buyer, seller, nft, tokenId, amount, payToken, price, relayerFee)
+}
+
+// BuyERC1155ForOffline is a paid mutator transaction binding the contract method 0x98034ab7.
+//
+// Solidity: function buyERC1155ForOffline(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee) returns()
+func (_NFTMarket *NFTMarketSession) BuyERC1155ForOffline(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyERC1155ForOffline(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, amount, payToken, price, relayerFee)
+}
+
+// BuyERC1155ForOffline is a paid mutator transaction binding the contract method 0x98034ab7.
 //
-// Solidity: function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price) payable returns()
-func (_NFTMarket *NFTMarketTransactor) BuyNFTForOffline(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int) (*types.Transaction, error) {
-	return _NFTMarket.contract.Transact(opts, "buyNFTForOffline", buyer, seller, nft, tokenId, payToken, price)
+// Solidity: function buyERC1155ForOffline(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee) returns()
+func (_NFTMarket *NFTMarketTransactorSession) BuyERC1155ForOffline(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyERC1155ForOffline(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, amount, payToken, price, relayerFee)
 }
 
-// BuyNFTForOffline is a paid mutator transaction binding the contract method 0xcb20252b.
+// BuyERC1155WithPermit is a paid mutator transaction binding the contract method 0x46749255.
 //
-// Solidity: function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price) payable returns()
-func (_NFTMarket *NFTMarketSession) BuyNFTForOffline(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int) (*types.Transaction, error) {
-	return _NFTMarket.Contract.BuyNFTForOffline(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price)
+// Solidity: function buyERC1155WithPermit(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, (uint256,uint8,bytes32,bytes32) permit) returns()
+func (_NFTMarket *NFTMarketTransactor) BuyERC1155WithPermit(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermitSignature) (*types.Transaction, error) {
+	return _NFTMarket.contract.Transact(opts, "buyERC1155WithPermit", buyer, seller, nft, tokenId, amount, payToken, price, relayerFee, permit)
 }
 
-// BuyNFTForOffline is a paid mutator transaction binding the contract method 0xcb20252b.
+// BuyERC1155WithPermit is a paid mutator transaction binding the contract method 0x46749255.
 //
-// Solidity: function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price) payable returns()
-func (_NFTMarket *NFTMarketTransactorSession) BuyNFTForOffline(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int) (*types.Transaction, error) {
-	return _NFTMarket.Contract.BuyNFTForOffline(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price)
+// Solidity: function buyERC1155WithPermit(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, (uint256,uint8,bytes32,bytes32) permit) returns()
+func (_NFTMarket *NFTMarketSession) BuyERC1155WithPermit(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermitSignature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyERC1155WithPermit(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, amount, payToken, price, relayerFee, permit)
+}
+
+// BuyERC1155WithPermit is a paid mutator transaction binding the contract method 0x46749255.
+//
+// Solidity: function buyERC1155WithPermit(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, (uint256,uint8,bytes32,bytes32) permit) returns()
+func (_NFTMarket *NFTMarketTransactorSession) BuyERC1155WithPermit(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermitSignature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyERC1155WithPermit(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, amount, payToken, price, relayerFee, permit)
+}
+
+// BuyERC1155WithPermit2 is a paid mutator transaction binding the contract method 0x6fd25f21.
+//
+// Solidity: function buyERC1155WithPermit2(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, (uint256,uint256,bytes) permit) returns()
+func (_NFTMarket *NFTMarketTransactor) BuyERC1155WithPermit2(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermit2Signature) (*types.Transaction, error) {
+	return _NFTMarket.contract.Transact(opts, "buyERC1155WithPermit2", buyer, seller, nft, tokenId, amount, payToken, price, relayerFee, permit)
+}
+
+// BuyERC1155WithPermit2 is a paid mutator transaction binding the contract method 0x6fd25f21.
+//
+// Solidity: function buyERC1155WithPermit2(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, (uint256,uint256,bytes) permit) returns()
+func (_NFTMarket *NFTMarketSession) BuyERC1155WithPermit2(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermit2Signature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyERC1155WithPermit2(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, amount, payToken, price, relayerFee, permit)
+}
+
+// BuyERC1155WithPermit2 is a paid mutator transaction binding the contract method 0x6fd25f21.
+//
+// Solidity: function buyERC1155WithPermit2(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, (uint256,uint256,bytes) permit) returns()
+func (_NFTMarket *NFTMarketTransactorSession) BuyERC1155WithPermit2(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, amount *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermit2Signature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyERC1155WithPermit2(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, amount, payToken, price, relayerFee, permit)
+}
+
+// BuyNFTForOffline is a paid mutator transaction binding the contract method 0xa5425391.
+//
+// Solidity: function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee) returns()
+func (_NFTMarket *NFTMarketTransactor) BuyNFTForOffline(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int) (*types.Transaction, error) {
+	return _NFTMarket.contract.Transact(opts, "buyNFTForOffline", buyer, seller, nft, tokenId, payToken, price, relayerFee)
+}
+
+// BuyNFTForOffline is a paid mutator transaction binding the contract method 0xa5425391.
+//
+// Solidity: function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee) returns()
+func (_NFTMarket *NFTMarketSession) BuyNFTForOffline(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyNFTForOffline(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price, relayerFee)
+}
+
+// BuyNFTForOffline is a paid mutator transaction binding the contract method 0xa5425391.
+//
+// Solidity: function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee) returns()
+func (_NFTMarket *NFTMarketTransactorSession) BuyNFTForOffline(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyNFTForOffline(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price, relayerFee)
+}
+
+// BuyNFTWithPermit is a paid mutator transaction binding the contract method 0x133a3e5a.
+//
+// Solidity: function buyNFTWithPermit(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, (uint256,uint8,bytes32,bytes32) permit) returns()
+func (_NFTMarket *NFTMarketTransactor) BuyNFTWithPermit(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermitSignature) (*types.Transaction, error) {
+	return _NFTMarket.contract.Transact(opts, "buyNFTWithPermit", buyer, seller, nft, tokenId, payToken, price, relayerFee, permit)
+}
+
+// BuyNFTWithPermit is a paid mutator transaction binding the contract method 0x133a3e5a.
+//
+// Solidity: function buyNFTWithPermit(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, (uint256,uint8,bytes32,bytes32) permit) returns()
+func (_NFTMarket *NFTMarketSession) BuyNFTWithPermit(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermitSignature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyNFTWithPermit(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price, relayerFee, permit)
+}
+
+// BuyNFTWithPermit is a paid mutator transaction binding the contract method 0x133a3e5a.
+//
+// Solidity: function buyNFTWithPermit(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, (uint256,uint8,bytes32,bytes32) permit) returns()
+func (_NFTMarket *NFTMarketTransactorSession) BuyNFTWithPermit(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermitSignature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyNFTWithPermit(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price, relayerFee, permit)
+}
+
+// BuyNFTWithPermit2 is a paid mutator transaction binding the contract method 0x5461a1a0.
+//
+// Solidity: function buyNFTWithPermit2(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, (uint256,uint256,bytes) permit) returns()
+func (_NFTMarket *NFTMarketTransactor) BuyNFTWithPermit2(opts *bind.TransactOpts, buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermit2Signature) (*types.Transaction, error) {
+	return _NFTMarket.contract.Transact(opts, "buyNFTWithPermit2", buyer, seller, nft, tokenId, payToken, price, relayerFee, permit)
+}
+
+// BuyNFTWithPermit2 is a paid mutator transaction binding the contract method 0x5461a1a0.
+//
+// Solidity: function buyNFTWithPermit2(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, (uint256,uint256,bytes) permit) returns()
+func (_NFTMarket *NFTMarketSession) BuyNFTWithPermit2(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermit2Signature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyNFTWithPermit2(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price, relayerFee, permit)
+}
+
+// BuyNFTWithPermit2 is a paid mutator transaction binding the contract method 0x5461a1a0.
+//
+// Solidity: function buyNFTWithPermit2(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, (uint256,uint256,bytes) permit) returns()
+func (_NFTMarket *NFTMarketTransactorSession) BuyNFTWithPermit2(buyer common.Address, seller common.Address, nft common.Address, tokenId *big.Int, payToken common.Address, price *big.Int, relayerFee *big.Int, permit NFTMarketPermit2Signature) (*types.Transaction, error) {
+	return _NFTMarket.Contract.BuyNFTWithPermit2(&_NFTMarket.TransactOpts, buyer, seller, nft, tokenId, payToken, price, relayerFee, permit)
 }
 
 // CancelWhiteListSigner is a paid mutator transaction binding the contract method 0x8db9385b.
@@ -335,3 +517,24 @@ func (_NFTMarket *NFTMarketSession) SetWhiteList(client common.Address) (*types.
 func (_NFTMarket *NFTMarketTransactorSession) SetWhiteList(client common.Address) (*types.Transaction, error) {
 	return _NFTMarket.Contract.SetWhiteList(&_NFTMarket.TransactOpts, client)
 }
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_NFTMarket *NFTMarketTransactor) Receive(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _NFTMarket.contract.RawTransact(opts, nil) // calldata is disallowed for receive function
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_NFTMarket *NFTMarketSession) Receive() (*types.Transaction, error) {
+	return _NFTMarket.Contract.Receive(&_NFTMarket.TransactOpts)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_NFTMarket *NFTMarketTransactorSession) Receive() (*types.Transaction, error) {
+	return _NFTMarket.Contract.Receive(&_NFTMarket.TransactOpts)
+}
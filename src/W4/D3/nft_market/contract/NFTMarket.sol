@@ -3,16 +3,41 @@ pragma solidity ^0.8.20;
 
 import {SafeERC20} from "@openzeppelin/contracts/token/ERC20/utils/SafeERC20.sol";
 import {IERC20} from "@openzeppelin/contracts/token/ERC20/IERC20.sol";
+import {IERC20Permit} from "@openzeppelin/contracts/token/ERC20/extensions/IERC20Permit.sol";
 import {IERC721} from "@openzeppelin/contracts/token/ERC721/IERC721.sol";
+import {IERC1155} from "@openzeppelin/contracts/token/ERC1155/IERC1155.sol";
+import {IPermit2} from "./IPermit2.sol";
+import {IWETH} from "./IWETH.sol";
 
 contract NFTMarket {
     address public constant ETH_FLAG = address(0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE);
+    // Uniswap Permit2 在各链上的统一部署地址
+    address public constant PERMIT2 = address(0x000000000022D473030F116dDEE9F6B43aC78BA3);
     address public immutable owner; // 市场合约拥有者（部署者）
+    // ETH支付的订单从买家的WETH中扣款，解包后以ETH支付给卖家和白名单钱包，白名单钱包不需要携带msg.value
+    address public immutable WETH;
     uint256 private whiteListIndex;
     mapping(address => uint256) public whiteList; // 后端client地址 -> index
 
-    constructor() {
+    // 买家对市场合约的EIP-2612 permit签名，授权额度为成交价格加中继费用
+    struct PermitSignature {
+        uint256 deadline;
+        uint8 v;
+        bytes32 r;
+        bytes32 s;
+    }
+
+    // 买家的Permit2 SignatureTransfer签名，spender为市场合约，转账额度为成交价格加中继费用
+    struct Permit2Signature {
+        uint256 nonce;
+        uint256 deadline;
+        bytes signature;
+    }
+
+    constructor(address weth) {
+        require(weth != address(0), "MKT: zero address");
         owner = msg.sender;
+        WETH = weth;
         whiteListIndex = 1; // 0值会作为判断条件，表示无效
     }
 
@@ -21,25 +46,101 @@ contract NFTMarket {
         _;
     }
 
+    // 只接收WETH解包转入的ETH
+    receive() external payable {
+        require(msg.sender == WETH, "MKT: eth not accepted");
+    }
+
     // 购买 NFT，校验逻辑通过后端离线验证，链上只做资产(NFT和Token)转移，用户需要提前授权给NFTMarket相应转移权限即可
-    function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price) external payable {
+    // relayerFee为买家签名确认的中继费用，与成交价格使用同一种代币，支付给发送交易的白名单钱包以抵扣gas
+    // ETH支付时买家需提前将WETH授权给NFTMarket
+    function buyNFTForOffline(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee) external {
         // 验证调用方是否为白名单用户
         require(whiteList[msg.sender] != 0, "MKT: not whiteList client");
         // 转移 NFT 给买家
         IERC721(nft).safeTransferFrom(seller, buyer, tokenId);
-        // 转移代币给卖家
+        // 转移代币给卖家，中继费用给白名单钱包
+        _pay(payToken, buyer, seller, price, relayerFee);
+    }
+
+    // 使用买家的EIP-2612 permit签名授权并购买 NFT，授权与购买在同一笔交易中完成，买家无需提前approve
+    function buyNFTWithPermit(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, PermitSignature calldata permit) external {
+        require(whiteList[msg.sender] != 0, "MKT: not whiteList client");
+        // permit可能已被他人抢先提交，此时授权已生效，失败后继续依靠已有授权转账
+        try IERC20Permit(payToken).permit(buyer, address(this), price + relayerFee, permit.deadline, permit.v, permit.r, permit.s) {} catch {}
+        IERC721(nft).safeTransferFrom(seller, buyer, tokenId);
+        _pay(payToken, buyer, seller, price, relayerFee);
+    }
+
+    // 使用买家的Permit2签名转账购买 NFT，买家只需对Permit2合约授权一次
+    function buyNFTWithPermit2(address buyer, address seller, address nft, uint256 tokenId, address payToken, uint256 price, uint256 relayerFee, Permit2Signature calldata permit) external {
+        require(whiteList[msg.sender] != 0, "MKT: not whiteList client");
+        IERC721(nft).safeTransferFrom(seller, buyer, tokenId);
+        _payWithPermit2(buyer, seller, payToken, price, relayerFee, permit);
+    }
+
+    // 购买 ERC1155 NFT，amount为本次成交的数量，price为本次成交的总价，同一订单可以分多次成交
+    function buyERC1155ForOffline(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee) external {
+        require(whiteList[msg.sender] != 0, "MKT: not whiteList client");
+        IERC1155(nft).safeTransferFrom(seller, buyer, tokenId, amount, "");
+        _pay(payToken, buyer, seller, price, relayerFee);
+    }
+
+    // 使用买家的EIP-2612 permit签名授权并购买 ERC1155 NFT
+    function buyERC1155WithPermit(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, PermitSignature calldata permit) external {
+        require(whiteList[msg.sender] != 0, "MKT: not whiteList client");
+        try IERC20Permit(payToken).permit(buyer, address(this), price + relayerFee, permit.deadline, permit.v, permit.r, permit.s) {} catch {}
+        IERC1155(nft).safeTransferFrom(seller, buyer, tokenId, amount, "");
+        _pay(payToken, buyer, seller, price, relayerFee);
+    }
+
+    // 使用买家的Permit2签名转账购买 ERC1155 NFT
+    function buyERC1155WithPermit2(address buyer, address seller, address nft, uint256 tokenId, uint256 amount, address payToken, uint256 price, uint256 relayerFee, Permit2Signature calldata permit) external {
+        require(whiteList[msg.sender] != 0, "MKT: not whiteList client");
+        IERC1155(nft).safeTransferFrom(seller, buyer, tokenId, amount, "");
+        _payWithPermit2(buyer, seller, payToken, price, relayerFee, permit);
+    }
+
+    // 买家支付成交价格给卖家、中继费用给发送交易的白名单钱包
+    // ETH支付时一次性从买家转入两者之和的WETH并解包，再分别以ETH转出
+    function _pay(address payToken, address buyer, address seller, uint256 price, uint256 relayerFee) private {
+        if (payToken == ETH_FLAG) {
+            uint256 total = price + relayerFee;
+            SafeERC20.safeTransferFrom(IERC20(WETH), buyer, address(this), total);
+            IWETH(WETH).withdraw(total);
+        }
         _transferToken(payToken, buyer, seller, price);
+        if (relayerFee > 0) {
+            _transferToken(payToken, buyer, msg.sender, relayerFee);
+        }
+    }
+
+    // Permit2只支持单一收款方，有中继费用时先转入市场合约再分别转给卖家和白名单钱包
+    function _payWithPermit2(address buyer, address seller, address payToken, uint256 price, uint256 relayerFee, Permit2Signature calldata permit) private {
+        uint256 total = price + relayerFee;
+        IPermit2(PERMIT2).permitTransferFrom(
+            IPermit2.PermitTransferFrom({
+                permitted: IPermit2.TokenPermissions({token: payToken, amount: total}),
+                nonce: permit.nonce,
+                deadline: permit.deadline
+            }),
+            IPermit2.SignatureTransferDetails({to: relayerFee == 0 ? seller : address(this), requestedAmount: total}),
+            buyer,
+            permit.signature
+        );
+        if (relayerFee > 0) {
+            SafeERC20.safeTransfer(IERC20(payToken), seller, price);
+            SafeERC20.safeTransfer(IERC20(payToken), msg.sender, relayerFee);
+        }
     }
 
-    // 代币转移，支持ETH和ERC20代币
+    // 代币转移，支持ETH和ERC20代币，ETH从合约中已解包的WETH余额支付
     function _transferToken(address token, address from, address to, uint256 amount) private {
         // eth支付
         if (token == ETH_FLAG) {
-            require(msg.value == amount, "MKT: wrong eth value");
             (bool success,) = to.call{value: amount}("");
             require(success, "MKT: transfer failed");
         } else { // 处理ERC20代币
-            require(msg.value == 0, "MKT: wrong eth value");
             SafeERC20.safeTransferFrom(IERC20(token), from, to, amount);
         }
     }
@@ -0,0 +1,23 @@
+package contract
+
+// ERC1155ABI 后端校验订单时用到的ERC1155只读方法
+const ERC1155ABI = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"isApprovedForAll","stateMutability":"view","inputs":[{"name":"account","type":"address"},{"name":"operator","type":"address"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// ERC1155ErrorsABI OpenZeppelin v5 IERC1155Errors自定义错误，用于解码结算模拟的revert原因
+const ERC1155ErrorsABI = `[
+	{"type":"error","name":"ERC1155InsufficientBalance","inputs":[{"name":"sender","type":"address"},{"name":"balance","type":"uint256"},{"name":"needed","type":"uint256"},{"name":"tokenId","type":"uint256"}]},
+	{"type":"error","name":"ERC1155InvalidSender","inputs":[{"name":"sender","type":"address"}]},
+	{"type":"error","name":"ERC1155InvalidReceiver","inputs":[{"name":"receiver","type":"address"}]},
+	{"type":"error","name":"ERC1155MissingApprovalForAll","inputs":[{"name":"operator","type":"address"},{"name":"owner","type":"address"}]},
+	{"type":"error","name":"ERC1155InvalidApprover","inputs":[{"name":"approver","type":"address"}]},
+	{"type":"error","name":"ERC1155InvalidOperator","inputs":[{"name":"operator","type":"address"}]},
+	{"type":"error","name":"ERC1155InvalidArrayLength","inputs":[{"name":"idsLength","type":"uint256"},{"name":"valuesLength","type":"uint256"}]}
+]`
+
+// ERC165ABI 上架时检测NFT合约标准用到的ERC165方法
+const ERC165ABI = `[
+	{"type":"function","name":"supportsInterface","stateMutability":"view","inputs":[{"name":"interfaceId","type":"bytes4"}],"outputs":[{"name":"","type":"bool"}]}
+]`
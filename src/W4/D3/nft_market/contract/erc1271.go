@@ -0,0 +1,6 @@
+package contract
+
+// ERC1271ABI 合约钱包(多签、智能合约钱包)校验签名的EIP-1271接口
+const ERC1271ABI = `[
+	{"type":"function","name":"isValidSignature","stateMutability":"view","inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"outputs":[{"name":"magicValue","type":"bytes4"}]}
+]`
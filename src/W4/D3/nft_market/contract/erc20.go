@@ -0,0 +1,29 @@
+package contract
+
+// ERC20ABI 后端校验买家支付能力时用到的ERC20只读方法
+const ERC20ABI = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// ERC20MetadataABI 登记支付代币时读取的symbol和decimals
+const ERC20MetadataABI = `[
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]}
+]`
+
+// ERC20ErrorsABI OpenZeppelin v5 IERC20Errors自定义错误，用于解码结算模拟的revert原因
+const ERC20ErrorsABI = `[
+	{"type":"error","name":"ERC20InsufficientBalance","inputs":[{"name":"sender","type":"address"},{"name":"balance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+	{"type":"error","name":"ERC20InvalidSender","inputs":[{"name":"sender","type":"address"}]},
+	{"type":"error","name":"ERC20InvalidReceiver","inputs":[{"name":"receiver","type":"address"}]},
+	{"type":"error","name":"ERC20InsufficientAllowance","inputs":[{"name":"spender","type":"address"},{"name":"allowance","type":"uint256"},{"name":"needed","type":"uint256"}]},
+	{"type":"error","name":"ERC20InvalidApprover","inputs":[{"name":"approver","type":"address"}]},
+	{"type":"error","name":"ERC20InvalidSpender","inputs":[{"name":"spender","type":"address"}]}
+]`
+
+// ERC20PermitABI EIP-2612 permit离线校验用到的只读方法
+const ERC20PermitABI = `[
+	{"type":"function","name":"DOMAIN_SEPARATOR","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]},
+	{"type":"function","name":"nonces","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
@@ -0,0 +1,20 @@
+package contract
+
+// ERC721ABI 后端校验订单时用到的ERC721只读方法
+const ERC721ABI = `[
+	{"type":"function","name":"ownerOf","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"getApproved","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"isApprovedForAll","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"operator","type":"address"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// ERC721ErrorsABI OpenZeppelin v5 IERC721Errors自定义错误，用于解码结算模拟的revert原因
+const ERC721ErrorsABI = `[
+	{"type":"error","name":"ERC721InvalidOwner","inputs":[{"name":"owner","type":"address"}]},
+	{"type":"error","name":"ERC721NonexistentToken","inputs":[{"name":"tokenId","type":"uint256"}]},
+	{"type":"error","name":"ERC721IncorrectOwner","inputs":[{"name":"sender","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"owner","type":"address"}]},
+	{"type":"error","name":"ERC721InvalidSender","inputs":[{"name":"sender","type":"address"}]},
+	{"type":"error","name":"ERC721InvalidReceiver","inputs":[{"name":"receiver","type":"address"}]},
+	{"type":"error","name":"ERC721InsufficientApproval","inputs":[{"name":"operator","type":"address"},{"name":"tokenId","type":"uint256"}]},
+	{"type":"error","name":"ERC721InvalidApprover","inputs":[{"name":"approver","type":"address"}]},
+	{"type":"error","name":"ERC721InvalidOperator","inputs":[{"name":"operator","type":"address"}]}
+]`
@@ -0,0 +1,13 @@
+package contract
+
+// Permit2ABI Permit2离线校验用到的只读方法，以及SignatureTransfer的自定义错误
+const Permit2ABI = `[
+	{"type":"function","name":"nonceBitmap","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"wordPos","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"error","name":"InvalidAmount","inputs":[{"name":"maxAmount","type":"uint256"}]},
+	{"type":"error","name":"InvalidNonce","inputs":[]},
+	{"type":"error","name":"SignatureExpired","inputs":[{"name":"signatureDeadline","type":"uint256"}]},
+	{"type":"error","name":"InvalidSignature","inputs":[]},
+	{"type":"error","name":"InvalidSignatureLength","inputs":[]},
+	{"type":"error","name":"InvalidSigner","inputs":[]},
+	{"type":"error","name":"InvalidContractSignature","inputs":[]}
+]`
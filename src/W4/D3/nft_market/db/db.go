@@ -3,8 +3,6 @@ package db
 import (
 	"fmt"
 	"nftmarket/config/setting"
-	"nftmarket/global"
-	"nftmarket/internal/model"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -31,11 +29,3 @@ func NewDBEngine(dbConfig *setting.DbConfig) (*gorm.DB, error) {
 func GetDB() *gorm.DB {
 	return Db
 }
-
-// MigrateDb 初始化数据库表
-func MigrateDb() error {
-	if err := global.DBEngine.AutoMigrate(&model.Order{}); err != nil {
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,274 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 迁移文件命名为 {版本号}_{名称}.up.sql / {版本号}_{名称}.down.sql，版本号递增
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// 迁移期间持有的postgres advisory lock，防止多个实例同时迁移
+const migrationLockKey = 7_150_301
+
+// ErrSchemaTooNew 数据库中已执行了当前代码不认识的迁移，通常是回滚到了旧版本的代码
+var ErrSchemaTooNew = errors.New("database schema is newer than this build")
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   int64  `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string `gorm:"column:name"`
+	AppliedAt int64  `gorm:"column:applied_at"`
+}
+
+func (m *SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration 一个版本的升级和回滚SQL
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移及其执行状态
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt int64
+}
+
+// Migrations 读取嵌入的迁移文件，按版本号排序，每个版本必须同时有up和down
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// LatestVersion 当前代码中最新的迁移版本
+func LatestVersion() (int64, error) {
+	migrations, err := Migrations()
+	if err != nil || len(migrations) == 0 {
+		return 0, err
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// Statuses 所有迁移的执行状态，数据库中存在而代码中没有的版本也会列出(Up/Down为空)
+func Statuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []MigrationStatus
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if record, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: record.Version, Name: record.Name},
+			Applied:   true,
+			AppliedAt: record.AppliedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// CurrentVersion 数据库当前的schema版本，即已执行的最大迁移版本，未执行过迁移时为0
+func CurrentVersion(db *gorm.DB) (int64, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// CheckSchemaVersion 启动前校验数据库schema版本，比代码新时拒绝启动，有未执行的迁移时提示执行migrate up
+func CheckSchemaVersion(db *gorm.DB) error {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return err
+	}
+	var pending []int64
+	for _, status := range statuses {
+		if status.Applied && status.Up == "" {
+			return fmt.Errorf("%w: migration %d_%s is not known to this build", ErrSchemaTooNew, status.Version, status.Name)
+		}
+		if !status.Applied {
+			pending = append(pending, status.Version)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database has pending migrations %v, run `nftmarket migrate up` first", pending)
+	}
+	return nil
+}
+
+// MigrateUp 执行所有未执行的迁移
+func MigrateUp(db *gorm.DB) error {
+	latest, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+	return MigrateTo(db, latest)
+}
+
+// MigrateDown 按版本从新到旧回滚steps个已执行的迁移
+func MigrateDown(db *gorm.DB, steps int) error {
+	statuses, err := Statuses(db)
+	if err != nil {
+		return err
+	}
+	var applied []int64
+	for _, status := range statuses {
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+	if steps <= 0 || len(applied) == 0 {
+		return nil
+	}
+	target := int64(0)
+	if steps < len(applied) {
+		target = applied[len(applied)-steps-1]
+	}
+	return MigrateTo(db, target)
+}
+
+// MigrateTo 迁移到指定版本：执行版本不大于target的未执行迁移，回滚版本大于target的已执行迁移
+// 每个迁移在独立的事务中执行，失败时该迁移整体回滚，之前完成的迁移保留
+func MigrateTo(db *gorm.DB, target int64) error {
+	err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint PRIMARY KEY, name text, applied_at bigint)").Error
+	if err != nil {
+		return err
+	}
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		statuses, err := Statuses(conn)
+		if err != nil {
+			return err
+		}
+		// 回滚从新到旧，升级从旧到新
+		for i := len(statuses) - 1; i >= 0; i-- {
+			status := statuses[i]
+			if !status.Applied || status.Version <= target {
+				continue
+			}
+			if status.Down == "" {
+				return fmt.Errorf("%w: cannot roll back migration %d_%s", ErrSchemaTooNew, status.Version, status.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(status.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, status.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", status.Version, status.Name, err)
+			}
+			log.Printf("rolled back migration %d_%s", status.Version, status.Name)
+		}
+		for _, status := range statuses {
+			if status.Applied || status.Version > target {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(status.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: status.Version, Name: status.Name, AppliedAt: time.Now().Unix()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", status.Version, status.Name, err)
+			}
+			log.Printf("applied migration %d_%s", status.Version, status.Name)
+		}
+		return nil
+	})
+}
+
+// appliedMigrations 已执行的迁移，版本表不存在时视为没有执行过迁移
+func appliedMigrations(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	applied := make(map[int64]SchemaMigration)
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+	var records []SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
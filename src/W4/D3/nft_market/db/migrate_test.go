@@ -0,0 +1,116 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSN 测试使用的postgres连接串(key=value格式)，未设置时跳过迁移测试
+const testDSN = "NFTMARKET_TEST_POSTGRES_DSN"
+
+// openTestSchema 在独立的schema中打开数据库，测试结束后删除该schema
+func openTestSchema(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(testDSN)
+	if dsn == "" {
+		t.Skipf("%s not set", testDSN)
+	}
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// countRows 统计表中满足条件的行数
+func countRows(t *testing.T, db *gorm.DB, table, where string) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Table(table).Where(where).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// TestMigrateUpDownUp 从AutoMigrate建好的旧订单表升级、全部回滚后再次升级
+func TestMigrateUpDownUp(t *testing.T) {
+	db := openTestSchema(t)
+	// 最初版本AutoMigrate生成的订单表，没有status字段
+	err := db.Exec(`CREATE TABLE "order" (
+		order_id bigserial PRIMARY KEY,
+		seller text, nft text, token_id bigint, pay_token text, price bigint, deadline bigint,
+		seller_pub_key text, signature text, filled_tx_hash text, block_number bigint, block_timestamp bigint
+	)`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec(`INSERT INTO "order" (seller, price, filled_tx_hash, block_number, block_timestamp) VALUES
+		('0x70997970C51812dc3A010C7d01b50e0d17dc79C8', 1000, NULL, NULL, NULL),
+		('0x70997970C51812dc3A010C7d01b50e0d17dc79C8', 2000, '0x5ecfb746f7fee86a512bda3bd62ab7a38cb4c744240a92abef3659146b0a6d78', 22008143, 1741609950)`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectUp := func(step string) {
+		t.Helper()
+		if version, err := CurrentVersion(db); err != nil || version != latest {
+			t.Fatalf("%s: version = %d, %v; want %d", step, version, err, latest)
+		}
+		if err := CheckSchemaVersion(db); err != nil {
+			t.Fatalf("%s: %v", step, err)
+		}
+		if got := countRows(t, db, `"order"`, "status = 'open' AND remaining = 1 AND filled_tx_hash IS NULL"); got != 1 {
+			t.Fatalf("%s: %d open orders, want 1", step, got)
+		}
+		if got := countRows(t, db, `"order"`, "status = 'filled' AND filled_tx_hash IS NOT NULL"); got != 1 {
+			t.Fatalf("%s: %d filled orders, want 1", step, got)
+		}
+		if got := countRows(t, db, "order_fill", "price = '2000'"); got != 1 {
+			t.Fatalf("%s: %d fills, want 1", step, got)
+		}
+	}
+
+	if err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	expectUp("up")
+
+	// 回滚全部迁移后订单表和数据保留，后续版本新建的表被删除
+	if err := MigrateTo(db, 0); err != nil {
+		t.Fatal(err)
+	}
+	if version, err := CurrentVersion(db); err != nil || version != 0 {
+		t.Fatalf("down: version = %d, %v", version, err)
+	}
+	if got := countRows(t, db, `"order"`, "1 = 1"); got != 2 {
+		t.Fatalf("down: %d orders, want 2", got)
+	}
+	if db.Migrator().HasTable("order_fill") || db.Migrator().HasTable("webhook") {
+		t.Fatal("down: tables created by later migrations were not dropped")
+	}
+
+	if err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	expectUp("up again")
+}
@@ -0,0 +1,2 @@
+-- 0001_init接管的是此前AutoMigrate建好并已有数据的表，回滚时不删除任何表和数据
+-- 需要清空数据库时手动删除这些表
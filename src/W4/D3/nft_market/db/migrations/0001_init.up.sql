@@ -0,0 +1,110 @@
+-- 初始表结构，与此前AutoMigrate生成的表一致
+-- 表和字段均使用IF NOT EXISTS，已由AutoMigrate建表的数据库执行后只补齐缺少的字段和索引
+
+CREATE TABLE IF NOT EXISTS "order" (order_id bigserial PRIMARY KEY);
+ALTER TABLE "order"
+    ADD COLUMN IF NOT EXISTS seller text,
+    ADD COLUMN IF NOT EXISTS nft text,
+    ADD COLUMN IF NOT EXISTS token_id bigint,
+    ADD COLUMN IF NOT EXISTS pay_token text,
+    ADD COLUMN IF NOT EXISTS price bigint,
+    ADD COLUMN IF NOT EXISTS deadline bigint,
+    ADD COLUMN IF NOT EXISTS order_type text,
+    ADD COLUMN IF NOT EXISTS start_price text,
+    ADD COLUMN IF NOT EXISTS end_price text,
+    ADD COLUMN IF NOT EXISTS start_time bigint,
+    ADD COLUMN IF NOT EXISTS end_time bigint,
+    ADD COLUMN IF NOT EXISTS chain_id bigint,
+    ADD COLUMN IF NOT EXISTS merkle_root text,
+    ADD COLUMN IF NOT EXISTS discount_price text,
+    ADD COLUMN IF NOT EXISTS seller_pub_key text,
+    ADD COLUMN IF NOT EXISTS signature text,
+    ADD COLUMN IF NOT EXISTS seller_signature text,
+    ADD COLUMN IF NOT EXISTS filled_tx_hash text,
+    ADD COLUMN IF NOT EXISTS block_number bigint,
+    ADD COLUMN IF NOT EXISTS block_timestamp bigint,
+    ADD COLUMN IF NOT EXISTS buyer text,
+    ADD COLUMN IF NOT EXISTS filled_price text,
+    ADD COLUMN IF NOT EXISTS status text DEFAULT 'open',
+    ADD COLUMN IF NOT EXISTS invalid_reason text;
+CREATE INDEX IF NOT EXISTS idx_order_nft ON "order" (nft);
+CREATE INDEX IF NOT EXISTS idx_order_chain_id ON "order" (chain_id);
+CREATE INDEX IF NOT EXISTS idx_order_block_timestamp ON "order" (block_timestamp);
+CREATE INDEX IF NOT EXISTS idx_order_status ON "order" (status);
+-- 增加status字段之前的订单以filled_tx_hash表示已成交，补充字段时默认值为open
+UPDATE "order" SET status = 'filled' WHERE filled_tx_hash IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS white_list (id bigserial PRIMARY KEY);
+ALTER TABLE white_list
+    ADD COLUMN IF NOT EXISTS chain_id bigint,
+    ADD COLUMN IF NOT EXISTS client text,
+    ADD COLUMN IF NOT EXISTS status text,
+    ADD COLUMN IF NOT EXISTS add_tx_hash text,
+    ADD COLUMN IF NOT EXISTS remove_tx_hash text,
+    ADD COLUMN IF NOT EXISTS created_at bigint,
+    ADD COLUMN IF NOT EXISTS updated_at bigint;
+-- 白名单按链登记后，同一client可以出现在多条链上
+DROP INDEX IF EXISTS idx_white_list_client;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_white_list_chain_client ON white_list (chain_id, client);
+
+CREATE TABLE IF NOT EXISTS bid (bid_id bigserial PRIMARY KEY);
+ALTER TABLE bid
+    ADD COLUMN IF NOT EXISTS order_id bigint,
+    ADD COLUMN IF NOT EXISTS bidder text,
+    ADD COLUMN IF NOT EXISTS amount text,
+    ADD COLUMN IF NOT EXISTS signature text,
+    ADD COLUMN IF NOT EXISTS status text DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS created_at bigint;
+CREATE INDEX IF NOT EXISTS idx_bid_order_id ON bid (order_id);
+
+CREATE TABLE IF NOT EXISTS api_key (id bigserial PRIMARY KEY);
+ALTER TABLE api_key
+    ADD COLUMN IF NOT EXISTS name text,
+    ADD COLUMN IF NOT EXISTS key_prefix text,
+    ADD COLUMN IF NOT EXISTS key_hash text,
+    ADD COLUMN IF NOT EXISTS status text DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS created_at bigint,
+    ADD COLUMN IF NOT EXISTS revoked_at bigint;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_key_key_hash ON api_key (key_hash);
+
+CREATE TABLE IF NOT EXISTS merkle_tree (root text PRIMARY KEY);
+ALTER TABLE merkle_tree
+    ADD COLUMN IF NOT EXISTS size bigint,
+    ADD COLUMN IF NOT EXISTS dump text,
+    ADD COLUMN IF NOT EXISTS created_at bigint;
+
+CREATE TABLE IF NOT EXISTS outbox (id bigserial PRIMARY KEY);
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS chain_id bigint,
+    ADD COLUMN IF NOT EXISTS order_id bigint,
+    ADD COLUMN IF NOT EXISTS bid_id bigint,
+    ADD COLUMN IF NOT EXISTS buyer text,
+    ADD COLUMN IF NOT EXISTS price text,
+    ADD COLUMN IF NOT EXISTS signer text,
+    ADD COLUMN IF NOT EXISTS nonce bigint,
+    ADD COLUMN IF NOT EXISTS tx_hash text,
+    ADD COLUMN IF NOT EXISTS raw_tx text,
+    ADD COLUMN IF NOT EXISTS gas_fee_cap text,
+    ADD COLUMN IF NOT EXISTS gas_tip_cap text,
+    ADD COLUMN IF NOT EXISTS replacements bigint,
+    ADD COLUMN IF NOT EXISTS sent_at bigint,
+    ADD COLUMN IF NOT EXISTS status text DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS attempts bigint,
+    ADD COLUMN IF NOT EXISTS last_error text,
+    ADD COLUMN IF NOT EXISTS broadcast_at bigint,
+    ADD COLUMN IF NOT EXISTS created_at bigint,
+    ADD COLUMN IF NOT EXISTS updated_at bigint;
+CREATE INDEX IF NOT EXISTS idx_outbox_order_id ON outbox (order_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox (status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_tx_hash ON outbox (tx_hash);
+
+CREATE TABLE IF NOT EXISTS outbox_tx (id bigserial PRIMARY KEY);
+ALTER TABLE outbox_tx
+    ADD COLUMN IF NOT EXISTS outbox_id bigint,
+    ADD COLUMN IF NOT EXISTS tx_hash text,
+    ADD COLUMN IF NOT EXISTS raw_tx text,
+    ADD COLUMN IF NOT EXISTS gas_fee_cap text,
+    ADD COLUMN IF NOT EXISTS gas_tip_cap text,
+    ADD COLUMN IF NOT EXISTS created_at bigint;
+CREATE INDEX IF NOT EXISTS idx_outbox_tx_outbox_id ON outbox_tx (outbox_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_tx_tx_hash ON outbox_tx (tx_hash);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS webhook_dead_letter;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook;
@@ -0,0 +1,45 @@
+-- 订单事件webhook订阅、投递队列和死信表
+
+CREATE TABLE webhook (
+    id bigserial PRIMARY KEY,
+    api_key_id bigint NOT NULL DEFAULT 0,
+    url text NOT NULL,
+    events text,
+    nft text,
+    seller text,
+    secret text NOT NULL,
+    created_at bigint
+);
+CREATE INDEX idx_webhook_api_key_id ON webhook (api_key_id);
+
+CREATE TABLE webhook_delivery (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint NOT NULL,
+    event_id text,
+    event_type text,
+    payload text,
+    status text DEFAULT 'pending',
+    attempts bigint DEFAULT 0,
+    next_attempt_at bigint,
+    last_status_code bigint,
+    last_error text,
+    delivered_at bigint,
+    created_at bigint
+);
+CREATE INDEX idx_webhook_delivery_webhook_id ON webhook_delivery (webhook_id);
+-- 投递任务只扫描待投递的记录
+CREATE INDEX idx_webhook_delivery_pending ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE webhook_dead_letter (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint NOT NULL,
+    event_id text,
+    event_type text,
+    payload text,
+    attempts bigint,
+    last_status_code bigint,
+    last_error text,
+    replayed_at bigint,
+    created_at bigint
+);
+CREATE INDEX idx_webhook_dead_letter_webhook_id ON webhook_dead_letter (webhook_id);
@@ -0,0 +1,7 @@
+-- ERC1155订单的部分成交无法在订单表中表示，回滚前需确认没有ERC1155订单
+DROP TABLE IF EXISTS order_fill;
+ALTER TABLE outbox DROP COLUMN IF EXISTS amount;
+ALTER TABLE "order"
+    DROP COLUMN IF EXISTS remaining,
+    DROP COLUMN IF EXISTS amount,
+    DROP COLUMN IF EXISTS token_standard;
@@ -0,0 +1,35 @@
+-- ERC1155订单按数量上架、分多次成交，成交记录从订单表拆分到order_fill表
+
+ALTER TABLE "order"
+    ADD COLUMN token_standard text,
+    ADD COLUMN amount bigint,
+    ADD COLUMN remaining bigint NOT NULL DEFAULT 0;
+-- 历史订单均为ERC721，上架中的订单剩余1个，结算中的订单回滚时由发件箱的amount加回
+UPDATE "order" SET remaining = 1 WHERE status = 'open' AND filled_tx_hash IS NULL;
+
+-- 历史发件箱记录均为ERC721的单个成交
+ALTER TABLE outbox ADD COLUMN amount bigint NOT NULL DEFAULT 1;
+
+CREATE TABLE order_fill (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL,
+    outbox_id bigint NOT NULL DEFAULT 0,
+    buyer text,
+    amount bigint NOT NULL,
+    price text NOT NULL,
+    tx_hash text NOT NULL,
+    block_number bigint,
+    block_timestamp bigint,
+    created_at bigint
+);
+CREATE INDEX idx_order_fill_order_id ON order_fill (order_id);
+CREATE UNIQUE INDEX idx_order_fill_tx_hash ON order_fill (tx_hash);
+CREATE INDEX idx_order_fill_block_timestamp ON order_fill (block_timestamp);
+
+-- 已成交的历史订单各生成一条成交记录，没有记录成交价格的使用一口价价格
+INSERT INTO order_fill (order_id, outbox_id, buyer, amount, price, tx_hash, block_number, block_timestamp, created_at)
+SELECT o.order_id, COALESCE(ob.id, 0), COALESCE(o.buyer, ''), 1, COALESCE(o.filled_price, o.price::text), o.filled_tx_hash,
+       o.block_number, o.block_timestamp, COALESCE(o.block_timestamp, 0)
+FROM "order" o
+LEFT JOIN outbox ob ON ob.tx_hash = o.filled_tx_hash
+WHERE o.filled_tx_hash IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS relayer_quote;
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS quote_id,
+    DROP COLUMN IF EXISTS relayer_fee;
+ALTER TABLE order_fill
+    DROP COLUMN IF EXISTS relayer_fee,
+    DROP COLUMN IF EXISTS gas_cost,
+    DROP COLUMN IF EXISTS effective_gas_price,
+    DROP COLUMN IF EXISTS gas_used;
@@ -0,0 +1,28 @@
+-- 结算交易的gas消耗、买家支付的中继费用和购买前的中继费用报价
+
+-- 迁移前的历史成交没有记录gas消耗，统计时单独计数
+ALTER TABLE order_fill
+    ADD COLUMN gas_used bigint,
+    ADD COLUMN effective_gas_price text,
+    ADD COLUMN gas_cost text,
+    ADD COLUMN relayer_fee text NOT NULL DEFAULT '0';
+
+ALTER TABLE outbox
+    ADD COLUMN relayer_fee text NOT NULL DEFAULT '0',
+    ADD COLUMN quote_id bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE relayer_quote (
+    id bigserial PRIMARY KEY,
+    chain_id bigint,
+    order_id bigint NOT NULL,
+    buyer text NOT NULL,
+    amount bigint NOT NULL,
+    price text NOT NULL,
+    relayer_fee text NOT NULL,
+    fee_mode text,
+    gas_cost text,
+    expires_at bigint NOT NULL,
+    used_at bigint NOT NULL DEFAULT 0,
+    created_at bigint
+);
+CREATE INDEX idx_relayer_quote_order_id ON relayer_quote (order_id);
@@ -0,0 +1,12 @@
+-- 支付代币登记表，ETH为内置代币不在表中登记
+-- 之前配置在Market.PayTokens中的代币在服务启动时从合约读取symbol和decimals后自动登记
+
+CREATE TABLE pay_token (
+    id bigserial PRIMARY KEY,
+    chain_id bigint NOT NULL,
+    address text NOT NULL,
+    symbol text NOT NULL,
+    decimals smallint NOT NULL,
+    created_at bigint
+);
+CREATE UNIQUE INDEX idx_pay_token_chain_address ON pay_token (chain_id, address);
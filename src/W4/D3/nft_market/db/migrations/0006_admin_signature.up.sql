@@ -0,0 +1,7 @@
+-- 已使用的管理员签名，按签名消息的摘要去重，有效期内同一签名只能使用一次
+
+CREATE TABLE admin_signature (
+    digest text PRIMARY KEY,
+    expires_at bigint NOT NULL
+);
+CREATE INDEX idx_admin_signature_expires_at ON admin_signature (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE bid
+    DROP COLUMN IF EXISTS relayer_fee;
@@ -0,0 +1,4 @@
+-- 英式拍出价时由出价人签名确认的中继费用，历史出价不收取
+
+ALTER TABLE bid
+    ADD COLUMN relayer_fee text NOT NULL DEFAULT '0';
@@ -0,0 +1,10 @@
+-- 已使用的工作量证明印章，nonce唯一防止重放，按调用方和使用时间统计频率以计算难度
+
+CREATE TABLE pow_stamp (
+    nonce text PRIMARY KEY,
+    client text NOT NULL,
+    used_at bigint NOT NULL,
+    expires_at bigint NOT NULL
+);
+CREATE INDEX idx_pow_stamp_client_used_at ON pow_stamp (client, used_at);
+CREATE INDEX idx_pow_stamp_expires_at ON pow_stamp (expires_at);
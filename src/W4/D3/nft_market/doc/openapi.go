@@ -0,0 +1,65 @@
+package doc
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPI 嵌入二进制的OpenAPI 3接口文档
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
+
+// CheckRoutes 核对接口文档与实际注册的路由是否一致，返回所有不一致的接口
+func CheckRoutes(routes gin.RoutesInfo) error {
+	var spec struct {
+		Paths map[string]map[string]interface{} `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(OpenAPI, &spec); err != nil {
+		return fmt.Errorf("failed to parse openapi.yaml: %w", err)
+	}
+
+	documented := make(map[string]bool)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		registered[route.Method+" "+openAPIPath(route.Path)] = true
+	}
+
+	var problems []string
+	for route := range registered {
+		if !documented[route] {
+			problems = append(problems, "undocumented route: "+route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			problems = append(problems, "documented route not registered: "+route)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi.yaml is out of date:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// openAPIPath 将gin路径参数(:id、*path)转换为OpenAPI格式({id})
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -1,17 +1,23 @@
 package global
 
 import (
+	"nftmarket/chain"
 	"nftmarket/config/setting"
-	"nftmarket/contract"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"gorm.io/gorm"
 )
 
 var (
 	DbConfig         *setting.DbConfig
 	BlockChainConfig *setting.BlockChainConfig
+	SweeperConfig    *setting.SweeperConfig
+	MarketConfig     *setting.MarketConfig
+	RateLimitConfig  *setting.RateLimitConfig
+	GrpcConfig       *setting.GrpcConfig
+	WebhookConfig    *setting.WebhookConfig
+	RelayerFeeConfig *setting.RelayerFeeConfig
+	FiatPriceConfig  []setting.FiatPriceConfig
+	PowConfig        *setting.PowConfig
 	DBEngine         *gorm.DB
-	EthRpcClient     *ethclient.Client
-	Market           *contract.NFTMarket
+	Chains           *chain.Registry
 )
@@ -0,0 +1,397 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/events"
+	"nftmarket/internal/model"
+	"nftmarket/internal/pow"
+	"nftmarket/internal/validate"
+	"nftmarket/marketpb"
+	"nftmarket/service"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// 订单事件订阅的缓冲事件数，写满时断开订阅，客户端需重新订阅
+const eventBuffer = 256
+
+// Server Market gRPC服务，业务逻辑与REST接口共用service层
+type Server struct {
+	marketpb.UnimplementedMarketServer
+}
+
+// Run 在addr上启动gRPC服务
+func Run(addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Panic("grpc listen error : ", err)
+	}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryAPIKeyAuth),
+		grpc.StreamInterceptor(streamAPIKeyAuth),
+	)
+	marketpb.RegisterMarketServer(server, &Server{})
+	log.Printf("grpc server listening on %s", addr)
+	if err := server.Serve(listener); err != nil {
+		log.Panic("grpc serve error : ", err)
+	}
+}
+
+// GetPowChallenge 获取工作量证明挑战
+func (s *Server) GetPowChallenge(ctx context.Context, req *marketpb.PowChallengeRequest) (*marketpb.PowChallenge, error) {
+	if req.Resource != service.PowResourceCreate {
+		return nil, status.Error(codes.InvalidArgument, "resource must be create")
+	}
+	challenge, err := service.IssuePowChallenge(ctx, req.Resource, powClient(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &marketpb.PowChallenge{Required: challenge.Required}
+	if challenge.Challenge != nil {
+		resp.Resource = challenge.Resource
+		resp.Difficulty = int32(challenge.Difficulty)
+		resp.ExpiresAt = challenge.ExpiresAt
+		resp.Nonce = challenge.Nonce
+		resp.Stamp = challenge.Stamp
+	}
+	return resp, nil
+}
+
+// CreateOrder 上架订单
+func (s *Server) CreateOrder(ctx context.Context, req *marketpb.CreateOrderRequest) (*marketpb.Order, error) {
+	var stamp string
+	if stamps := metadata.ValueFromIncomingContext(ctx, strings.ToLower(pow.StampHeader)); len(stamps) > 0 {
+		stamp = stamps[0]
+	}
+	if err := service.VerifyPowStamp(ctx, stamp, service.PowResourceCreate, powClient(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+	request := model.SellOrderRequest{
+		PrivateKey:      req.PrivateKey,
+		PublicKey:       req.PublicKey,
+		Signature:       req.Signature,
+		Seller:          req.Seller,
+		NFT:             req.Nft,
+		TokenID:         req.TokenId,
+		PayToken:        req.PayToken,
+		Price:           req.Price,
+		Deadline:        req.Deadline,
+		OrderType:       req.OrderType,
+		StartPrice:      req.StartPrice,
+		EndPrice:        req.EndPrice,
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		ChainId:         req.ChainId,
+		MerkleRoot:      req.MerkleRoot,
+		DiscountPrice:   req.DiscountPrice,
+		SellerSignature: req.SellerSignature,
+		TokenStandard:   req.TokenStandard,
+		Amount:          req.Amount,
+		// 按支付代币单位填写的价格
+		HumanPrice:         req.HumanPrice,
+		HumanStartPrice:    req.HumanStartPrice,
+		HumanEndPrice:      req.HumanEndPrice,
+		HumanDiscountPrice: req.HumanDiscountPrice,
+	}
+	if err := validate.Struct(&request); err != nil {
+		return nil, invalidArgument(err)
+	}
+	order, err := service.CreateSellOrder(request)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(order), nil
+}
+
+// ListOrders 上架中的订单
+func (s *Server) ListOrders(ctx context.Context, req *marketpb.ListOrdersRequest) (*marketpb.ListOrdersResponse, error) {
+	orderChain, err := lookupChain(req.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := service.OpenSellOrders(orderChain)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &marketpb.ListOrdersResponse{Orders: make([]*marketpb.Order, len(orders))}
+	for i := range orders {
+		resp.Orders[i] = toOrder(&orders[i])
+	}
+	return resp, nil
+}
+
+// GetOrder 单个订单
+func (s *Server) GetOrder(ctx context.Context, req *marketpb.GetOrderRequest) (*marketpb.Order, error) {
+	if req.OrderId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "order_id must be greater than 0")
+	}
+	order, err := service.FindOrder(req.OrderId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(order), nil
+}
+
+// QuoteBuy 购买前获取中继费用报价
+func (s *Server) QuoteBuy(ctx context.Context, req *marketpb.QuoteBuyRequest) (*marketpb.QuoteBuyResponse, error) {
+	request := model.QuoteRequest{Buyer: req.Buyer, OrderId: int(req.OrderId), Amount: req.Amount, Proof: req.Proof}
+	if err := validate.Struct(&request); err != nil {
+		return nil, invalidArgument(err)
+	}
+	quote, err := service.CreateQuote(ctx, request)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &marketpb.QuoteBuyResponse{
+		Quote: &marketpb.RelayerQuote{
+			Id:         quote.Quote.Id,
+			ChainId:    quote.Quote.ChainId,
+			OrderId:    quote.Quote.OrderId,
+			Buyer:      quote.Quote.Buyer,
+			Amount:     quote.Quote.Amount,
+			Price:      quote.Quote.Price,
+			RelayerFee: quote.Quote.RelayerFee,
+			FeeMode:    quote.Quote.FeeMode,
+			GasCost:    quote.Quote.GasCost,
+			ExpiresAt:  quote.Quote.ExpiresAt,
+		},
+		Total:   quote.Total,
+		Message: quote.Message,
+	}, nil
+}
+
+// BuyNFT 购买，交易广播后不等待上链，返回settling的订单，ERC1155订单部分购买时仍为open
+func (s *Server) BuyNFT(ctx context.Context, req *marketpb.BuyNFTRequest) (*marketpb.Order, error) {
+	request := model.BuyRequest{Buyer: req.Buyer, OrderId: int(req.OrderId), Amount: req.Amount, Proof: req.Proof}
+	if req.Permit != nil {
+		request.Permit = &model.BuyPermit{
+			Type:      req.Permit.Type,
+			Deadline:  req.Permit.Deadline,
+			Nonce:     req.Permit.Nonce,
+			Signature: req.Permit.Signature,
+		}
+	}
+	if req.Quote != nil {
+		request.Quote = &model.BuyQuote{Id: req.Quote.Id, Signature: req.Quote.Signature}
+	}
+	if err := validate.Struct(&request); err != nil {
+		return nil, invalidArgument(err)
+	}
+	order, _, err := service.Buy(request)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(order), nil
+}
+
+// CancelOrder 卖家撤单
+func (s *Server) CancelOrder(ctx context.Context, req *marketpb.CancelOrderRequest) (*marketpb.Order, error) {
+	if req.OrderId <= 0 || req.Signature == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id and signature are required")
+	}
+	order, err := service.CancelSellOrder(ctx, req.OrderId, req.Signature)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(order), nil
+}
+
+// WatchOrderEvents 推送订单状态变化事件，直到客户端断开或消费过慢被断开
+func (s *Server) WatchOrderEvents(req *marketpb.WatchOrderEventsRequest, stream marketpb.Market_WatchOrderEventsServer) error {
+	orderChain, err := lookupChain(req.ChainId)
+	if err != nil {
+		return err
+	}
+	orderIds := make(map[int64]bool, len(req.OrderIds))
+	for _, orderId := range req.OrderIds {
+		orderIds[orderId] = true
+	}
+
+	subscription := events.Subscribe(eventBuffer)
+	defer subscription.Close()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case event, ok := <-subscription.C:
+			if !ok {
+				if subscription.Dropped() {
+					return status.Error(codes.ResourceExhausted, "event stream fell behind, resubscribe and reload the orders")
+				}
+				return status.Error(codes.Unavailable, "event stream closed")
+			}
+			if orderChain != nil && !orderChain.Includes(event.Order.SellOrder.ChainId) {
+				continue
+			}
+			if len(orderIds) > 0 && !orderIds[event.Order.OrderId] {
+				continue
+			}
+			err := stream.Send(&marketpb.OrderEvent{Type: event.Type, Order: toOrder(&event.Order), Time: event.Time})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// lookupChain 解析可选的chain_id，为0时返回nil
+func lookupChain(chainId int64) (*chain.Chain, error) {
+	if chainId == 0 {
+		return nil, nil
+	}
+	orderChain, err := global.Chains.Get(chainId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return orderChain, nil
+}
+
+// unaryAPIKeyAuth 与REST接口相同，RateLimit.RequireAPIKey为true时要求metadata携带x-api-key
+func unaryAPIKeyAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func streamAPIKeyAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if _, err := authenticate(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// apiKeyIdKey context中保存当前API key id的键
+type apiKeyIdKey struct{}
+
+// authenticate 校验API key，通过后返回携带API key id的context
+func authenticate(ctx context.Context) (context.Context, error) {
+	if global.RateLimitConfig == nil || !global.RateLimitConfig.RequireAPIKey {
+		return ctx, nil
+	}
+	keys := metadata.ValueFromIncomingContext(ctx, "x-api-key")
+	if len(keys) == 0 || keys[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "Missing API key")
+	}
+	apiKey, err := service.AuthenticateAPIKey(keys[0])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+	}
+	return context.WithValue(ctx, apiKeyIdKey{}, apiKey.Id), nil
+}
+
+// powClient 工作量证明挑战绑定的调用方，与REST接口相同，有API key时按key区分，否则按客户端IP
+func powClient(ctx context.Context) string {
+	apiKeyId, _ := ctx.Value(apiKeyIdKey{}).(int64)
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return service.PowClient(apiKeyId, ip)
+}
+
+// invalidArgument 将binding标签校验错误转换为InvalidArgument，消息中包含字段明细
+func invalidArgument(err error) error {
+	details := validate.Details(err)
+	if details == nil {
+		return status.Error(codes.InvalidArgument, "Invalid request: "+err.Error())
+	}
+	reasons := make([]string, len(details))
+	for i, detail := range details {
+		reasons[i] = detail.Field + " " + detail.Reason
+	}
+	return status.Error(codes.InvalidArgument, "Request validation failed: "+strings.Join(reasons, "; "))
+}
+
+// toStatus 将service层错误的http状态码转换为gRPC状态码
+func toStatus(err error) error {
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		return status.Error(codes.Internal, "Internal error")
+	}
+	code := codes.Internal
+	switch serviceErr.Status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.Aborted
+	case http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, serviceErr.Message)
+}
+
+// toOrder 将订单转换为protobuf消息
+func toOrder(order *model.Order) *marketpb.Order {
+	sellOrder := order.SellOrder
+	var display *marketpb.PriceDisplay
+	if order.PriceDisplay != nil {
+		display = &marketpb.PriceDisplay{
+			Symbol:        order.PriceDisplay.Symbol,
+			Decimals:      uint32(order.PriceDisplay.Decimals),
+			Price:         order.PriceDisplay.Price,
+			StartPrice:    order.PriceDisplay.StartPrice,
+			EndPrice:      order.PriceDisplay.EndPrice,
+			DiscountPrice: order.PriceDisplay.DiscountPrice,
+			CurrentPrice:  order.PriceDisplay.CurrentPrice,
+			HighestBid:    order.PriceDisplay.HighestBid,
+			FilledPrice:   order.PriceDisplay.FilledPrice,
+		}
+	}
+	return &marketpb.Order{
+		OrderId: order.OrderId,
+		SellOrder: &marketpb.SellOrder{
+			Seller:        sellOrder.Seller,
+			Nft:           sellOrder.Nft,
+			TokenId:       sellOrder.TokenId,
+			PayToken:      sellOrder.PayToken,
+			Price:         sellOrder.Price,
+			Deadline:      sellOrder.Deadline,
+			OrderType:     sellOrder.OrderType,
+			StartPrice:    sellOrder.StartPrice,
+			EndPrice:      sellOrder.EndPrice,
+			StartTime:     sellOrder.StartTime,
+			EndTime:       sellOrder.EndTime,
+			ChainId:       sellOrder.ChainId,
+			MerkleRoot:    sellOrder.MerkleRoot,
+			DiscountPrice: sellOrder.DiscountPrice,
+			TokenStandard: sellOrder.TokenStandard,
+			Amount:        sellOrder.Amount,
+		},
+		SellerPubKey:    order.SellerPubKey,
+		Signature:       order.Signature,
+		SellerSignature: order.SellerSignature,
+		FilledTxHash:    order.FilledTxHash,
+		BlockNumber:     order.BlockNumber,
+		BlockTimestamp:  order.BlockTimestamp,
+		Buyer:           order.Buyer,
+		FilledPrice:     order.FilledPrice,
+		Status:          order.Status,
+		InvalidReason:   order.InvalidReason,
+		CurrentPrice:    order.CurrentPrice,
+		HighestBid:      order.HighestBid,
+		Remaining:       order.Remaining,
+		PriceDisplay:    display,
+	}
+}
@@ -0,0 +1,195 @@
+package auction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"nftmarket/internal/model"
+	"time"
+)
+
+// Clock 当前时间来源，测试时可替换为固定时间
+type Clock func() time.Time
+
+// 出价消息格式，出价人需对其进行personal_sign签名
+const bidMessageFormat = "nftmarket bid\norder_id: %d\namount: %s"
+
+// BidMessage 生成出价人需要签名的消息，relayerFee大于0时追加中继费用，不收取时与旧消息一致
+func BidMessage(orderId int64, amount, relayerFee *big.Int) string {
+	message := fmt.Sprintf(bidMessageFormat, orderId, amount.String())
+	if relayerFee != nil && relayerFee.Sign() > 0 {
+		message += "\nrelayer_fee: " + relayerFee.String()
+	}
+	return message
+}
+
+// ParseAmount 解析十进制金额字符串，必须为正数
+func ParseAmount(value string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid amount", value)
+	}
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount %s must be positive", value)
+	}
+	return amount, nil
+}
+
+// Validate 校验订单的拍卖参数，now为上架时间
+func Validate(order model.SellOrder, now time.Time) error {
+	if err := validateAmount(order); err != nil {
+		return err
+	}
+	switch order.OrderType {
+	case "", model.OrderTypeFixed:
+		if order.Price <= 0 {
+			return errors.New("price must be positive")
+		}
+		return validateDiscount(order)
+	case model.OrderTypeDutch, model.OrderTypeEnglish:
+		if order.MerkleRoot != "" || order.DiscountPrice != "" {
+			return errors.New("whitelist discount is only supported for fixed price orders")
+		}
+		if order.OrderType == model.OrderTypeDutch {
+			return validateDutch(order)
+		}
+		return validateEnglish(order, now)
+	default:
+		return fmt.Errorf("unsupported order type %q", order.OrderType)
+	}
+}
+
+// validateAmount ERC1155订单必须指定上架数量，只支持一口价，Price和DiscountPrice为单价
+func validateAmount(order model.SellOrder) error {
+	if !order.IsERC1155() {
+		if order.Amount != 0 {
+			return errors.New("amount is only supported for erc1155 orders")
+		}
+		return nil
+	}
+	if order.Amount <= 0 {
+		return errors.New("amount must be positive for erc1155 orders")
+	}
+	if order.OrderType != "" && order.OrderType != model.OrderTypeFixed {
+		return errors.New("erc1155 orders only support fixed price")
+	}
+	return nil
+}
+
+// validateDiscount 白名单折扣价必须低于一口价
+func validateDiscount(order model.SellOrder) error {
+	if order.MerkleRoot == "" && order.DiscountPrice == "" {
+		return nil
+	}
+	discount, err := ParseAmount(order.DiscountPrice)
+	if err != nil {
+		return fmt.Errorf("invalid discount_price: %w", err)
+	}
+	if discount.Cmp(big.NewInt(order.Price)) >= 0 {
+		return errors.New("discount_price must be lower than price")
+	}
+	return nil
+}
+
+func validateDutch(order model.SellOrder) error {
+	startPrice, err := ParseAmount(order.StartPrice)
+	if err != nil {
+		return fmt.Errorf("invalid start_price: %w", err)
+	}
+	endPrice, err := ParseAmount(order.EndPrice)
+	if err != nil {
+		return fmt.Errorf("invalid end_price: %w", err)
+	}
+	if startPrice.Cmp(endPrice) <= 0 {
+		return errors.New("start_price must be greater than end_price")
+	}
+	if order.StartTime >= order.EndTime {
+		return errors.New("start_time must be before end_time")
+	}
+	if order.Deadline < order.EndTime {
+		return errors.New("deadline must not be before end_time")
+	}
+	return nil
+}
+
+func validateEnglish(order model.SellOrder, now time.Time) error {
+	if _, err := ParseAmount(order.StartPrice); err != nil {
+		return fmt.Errorf("invalid start_price: %w", err)
+	}
+	if order.EndTime <= now.Unix() {
+		return errors.New("end_time must be in the future")
+	}
+	if order.StartTime > order.EndTime {
+		return errors.New("start_time must be before end_time")
+	}
+	if order.Deadline < order.EndTime {
+		return errors.New("deadline must not be before end_time")
+	}
+	return nil
+}
+
+// DutchPrice 计算荷兰拍在now时刻的价格
+// 价格 = StartPrice - (StartPrice - EndPrice) * (now - StartTime) / (EndTime - StartTime)，开始前为StartPrice，结束后为EndPrice
+func DutchPrice(order model.SellOrder, now time.Time) (*big.Int, error) {
+	startPrice, err := ParseAmount(order.StartPrice)
+	if err != nil {
+		return nil, err
+	}
+	endPrice, err := ParseAmount(order.EndPrice)
+	if err != nil {
+		return nil, err
+	}
+	t := now.Unix()
+	if t <= order.StartTime {
+		return startPrice, nil
+	}
+	if t >= order.EndTime {
+		return endPrice, nil
+	}
+	elapsed := big.NewInt(t - order.StartTime)
+	duration := big.NewInt(order.EndTime - order.StartTime)
+	drop := new(big.Int).Sub(startPrice, endPrice)
+	drop.Mul(drop, elapsed)
+	drop.Quo(drop, duration)
+	return startPrice.Sub(startPrice, drop), nil
+}
+
+// CurrentPrice 计算订单在now时刻的价格，英式拍返回最高出价(无出价时为保留价)
+func CurrentPrice(order model.SellOrder, highestBid *big.Int, now time.Time) (*big.Int, error) {
+	switch order.OrderType {
+	case model.OrderTypeDutch:
+		return DutchPrice(order, now)
+	case model.OrderTypeEnglish:
+		if highestBid != nil {
+			return highestBid, nil
+		}
+		return ParseAmount(order.StartPrice)
+	default:
+		return big.NewInt(order.Price), nil
+	}
+}
+
+// ValidateBid 校验英式拍出价：拍卖进行中，且不低于保留价并高于当前最高出价
+func ValidateBid(order model.SellOrder, amount, highestBid *big.Int, now time.Time) error {
+	if order.OrderType != model.OrderTypeEnglish {
+		return errors.New("order is not an english auction")
+	}
+	t := now.Unix()
+	if t < order.StartTime {
+		return errors.New("auction has not started")
+	}
+	if t >= order.EndTime {
+		return errors.New("auction has ended")
+	}
+	reserve, err := ParseAmount(order.StartPrice)
+	if err != nil {
+		return err
+	}
+	if amount.Cmp(reserve) < 0 {
+		return fmt.Errorf("bid must not be lower than reserve price %s", reserve)
+	}
+	if highestBid != nil && amount.Cmp(highestBid) <= 0 {
+		return fmt.Errorf("bid must be higher than current highest bid %s", highestBid)
+	}
+	return nil
+}
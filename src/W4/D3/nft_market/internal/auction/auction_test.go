@@ -0,0 +1,218 @@
+package auction
+
+import (
+	"math/big"
+	"nftmarket/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 固定的当前时间，所有用例的时间参数都相对于它
+var fixedNow = time.Unix(1700000000, 0)
+
+func at(offset int64) time.Time {
+	return fixedNow.Add(time.Duration(offset) * time.Second)
+}
+
+func englishOrder() model.SellOrder {
+	t := fixedNow.Unix()
+	return model.SellOrder{
+		OrderType:  model.OrderTypeEnglish,
+		StartPrice: "1000",
+		StartTime:  t - 100,
+		EndTime:    t + 100,
+		Deadline:   t + 200,
+	}
+}
+
+func dutchOrder() model.SellOrder {
+	t := fixedNow.Unix()
+	return model.SellOrder{
+		OrderType:  model.OrderTypeDutch,
+		StartPrice: "1000",
+		EndPrice:   "400",
+		StartTime:  t,
+		EndTime:    t + 100,
+		Deadline:   t + 200,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   func() model.SellOrder
+		wantErr string
+	}{
+		{"fixed", func() model.SellOrder { return model.SellOrder{Price: 100} }, ""},
+		{"fixed zero price", func() model.SellOrder { return model.SellOrder{} }, "price must be positive"},
+		{"fixed discount", func() model.SellOrder {
+			return model.SellOrder{Price: 100, MerkleRoot: "0x01", DiscountPrice: "90"}
+		}, ""},
+		{"fixed discount not lower", func() model.SellOrder {
+			return model.SellOrder{Price: 100, MerkleRoot: "0x01", DiscountPrice: "100"}
+		}, "discount_price must be lower than price"},
+		{"unsupported type", func() model.SellOrder {
+			return model.SellOrder{OrderType: "vickrey"}
+		}, "unsupported order type"},
+		{"erc1155", func() model.SellOrder {
+			return model.SellOrder{Price: 100, TokenStandard: model.TokenStandardERC1155, Amount: 5}
+		}, ""},
+		{"erc1155 without amount", func() model.SellOrder {
+			return model.SellOrder{Price: 100, TokenStandard: model.TokenStandardERC1155}
+		}, "amount must be positive"},
+		{"erc721 with amount", func() model.SellOrder {
+			return model.SellOrder{Price: 100, Amount: 5}
+		}, "amount is only supported for erc1155 orders"},
+		{"erc1155 auction", func() model.SellOrder {
+			o := dutchOrder()
+			o.TokenStandard, o.Amount = model.TokenStandardERC1155, 5
+			return o
+		}, "erc1155 orders only support fixed price"},
+		{"dutch", dutchOrder, ""},
+		{"dutch with discount", func() model.SellOrder {
+			o := dutchOrder()
+			o.MerkleRoot = "0x01"
+			return o
+		}, "whitelist discount is only supported for fixed price orders"},
+		{"dutch rising price", func() model.SellOrder {
+			o := dutchOrder()
+			o.EndPrice = "1000"
+			return o
+		}, "start_price must be greater than end_price"},
+		{"dutch invalid end price", func() model.SellOrder {
+			o := dutchOrder()
+			o.EndPrice = "abc"
+			return o
+		}, "invalid end_price"},
+		{"dutch empty window", func() model.SellOrder {
+			o := dutchOrder()
+			o.EndTime = o.StartTime
+			return o
+		}, "start_time must be before end_time"},
+		{"dutch deadline before end", func() model.SellOrder {
+			o := dutchOrder()
+			o.Deadline = o.EndTime - 1
+			return o
+		}, "deadline must not be before end_time"},
+		{"english", englishOrder, ""},
+		{"english zero reserve", func() model.SellOrder {
+			o := englishOrder()
+			o.StartPrice = "0"
+			return o
+		}, "invalid start_price"},
+		{"english already ended", func() model.SellOrder {
+			o := englishOrder()
+			o.EndTime = fixedNow.Unix()
+			return o
+		}, "end_time must be in the future"},
+		{"english starts after end", func() model.SellOrder {
+			o := englishOrder()
+			o.StartTime = o.EndTime + 1
+			return o
+		}, "start_time must be before end_time"},
+		{"english deadline before end", func() model.SellOrder {
+			o := englishOrder()
+			o.Deadline = o.EndTime - 1
+			return o
+		}, "deadline must not be before end_time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.order(), fixedNow)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDutchPrice(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"before start", at(-50), "1000"},
+		{"at start", at(0), "1000"},
+		{"quarter", at(25), "850"},
+		{"mid", at(50), "700"},
+		{"rounds toward start price", at(33), "802"}, // 1000 - 600*33/100 = 802
+		{"at end", at(100), "400"},
+		{"after end", at(150), "400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := DutchPrice(dutchOrder(), tt.now)
+			if err != nil {
+				t.Fatalf("DutchPrice: %v", err)
+			}
+			if price.String() != tt.want {
+				t.Fatalf("price = %s, want %s", price, tt.want)
+			}
+		})
+	}
+
+	order := dutchOrder()
+	order.StartPrice = ""
+	if _, err := DutchPrice(order, fixedNow); err == nil {
+		t.Fatal("DutchPrice accepted an empty start_price")
+	}
+}
+
+func TestValidateBid(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   func() model.SellOrder
+		amount  int64
+		highest int64 // 0为暂无出价
+		now     time.Time
+		wantErr string
+	}{
+		{"first bid at reserve", englishOrder, 1000, 0, fixedNow, ""},
+		{"below reserve", englishOrder, 999, 0, fixedNow, "lower than reserve price 1000"},
+		{"higher than highest", englishOrder, 1501, 1500, fixedNow, ""},
+		{"equal to highest", englishOrder, 1500, 1500, fixedNow, "higher than current highest bid 1500"},
+		{"below highest", englishOrder, 1200, 1500, fixedNow, "higher than current highest bid 1500"},
+		{"before start", englishOrder, 1000, 0, at(-101), "auction has not started"},
+		{"at start", englishOrder, 1000, 0, at(-100), ""},
+		{"last second", englishOrder, 1000, 0, at(99), ""},
+		{"at end time", englishOrder, 1000, 0, at(100), "auction has ended"},
+		{"after end time", englishOrder, 2000, 1500, at(150), "auction has ended"},
+		{"not english", dutchOrder, 1000, 0, fixedNow, "order is not an english auction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var highest *big.Int
+			if tt.highest != 0 {
+				highest = big.NewInt(tt.highest)
+			}
+			err := ValidateBid(tt.order(), big.NewInt(tt.amount), highest, tt.now)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBidMessage(t *testing.T) {
+	want := "nftmarket bid\norder_id: 5\namount: 2000000000000000"
+	if got := BidMessage(5, big.NewInt(2000000000000000), nil); got != want {
+		t.Fatalf("message without fee = %q, want %q", got, want)
+	}
+	if got := BidMessage(5, big.NewInt(2000000000000000), new(big.Int)); got != want {
+		t.Fatalf("message with zero fee = %q, want %q", got, want)
+	}
+	if got := BidMessage(5, big.NewInt(2000000000000000), big.NewInt(300)); got != want+"\nrelayer_fee: 300" {
+		t.Fatalf("message with fee = %q", got)
+	}
+}
+
+func checkErr(t *testing.T, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %v, want %q", err, want)
+	}
+}
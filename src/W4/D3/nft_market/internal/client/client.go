@@ -0,0 +1,285 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"nftmarket/internal/model"
+	"nftmarket/internal/pow"
+	"nftmarket/internal/response"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client nft_market接口的HTTP客户端，私钥只在本地签名使用，不会发送到服务端
+type Client struct {
+	BaseURL string
+	APIKey  string
+	HTTP    *http.Client
+}
+
+// APIError 服务端返回的错误
+type APIError struct {
+	Status int
+	response.ErrorBody
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.ErrorBody.Error)
+	for _, detail := range e.Details {
+		message += fmt.Sprintf("\n  %s: %s", detail.Field, detail.Reason)
+	}
+	return message
+}
+
+// IsNotFound 判断错误是否为404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// New 创建客户端，baseURL为服务地址，例如http://127.0.0.1:8080
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  apiKey,
+		HTTP:    &http.Client{Timeout: 3 * time.Minute}, // 购买接口会等待交易上链
+	}
+}
+
+// BuyRequest 购买请求
+type BuyRequest struct {
+	Buyer   string           `json:"buyer"`
+	OrderId int64            `json:"order_id"`
+	Amount  int64            `json:"amount,omitempty"`
+	Permit  *model.BuyPermit `json:"permit,omitempty"`
+	Proof   []string         `json:"proof,omitempty"`
+	Quote   *model.BuyQuote  `json:"quote,omitempty"`
+}
+
+// Quote 中继费用报价，买家对Message签名后随购买请求提交，授权额度为Total
+type Quote struct {
+	Quote   model.RelayerQuote `json:"quote"`
+	Total   string             `json:"total"`
+	Message string             `json:"message"`
+}
+
+// MerkleProof 白名单默克尔证明
+type MerkleProof struct {
+	Root    string   `json:"root"`
+	Address string   `json:"address"`
+	Proof   []string `json:"proof"`
+}
+
+// ListOrders 查询上架中的订单，chainId为0时返回所有链
+func (c *Client) ListOrders(chainId int64) ([]model.Order, error) {
+	query := url.Values{}
+	if chainId != 0 {
+		query.Set("chain_id", strconv.FormatInt(chainId, 10))
+	}
+	var orders []model.Order
+	err := c.do(http.MethodGet, "/market/list", query, nil, &orders)
+	return orders, err
+}
+
+// GetOrder 查询单个订单
+func (c *Client) GetOrder(orderId int64) (*model.Order, error) {
+	var order model.Order
+	if err := c.do(http.MethodGet, "/market/order/"+strconv.FormatInt(orderId, 10), nil, nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CreateOrder 上架订单，request中只应包含本地计算的签名和公钥；服务端开启工作量证明时先求解挑战
+func (c *Client) CreateOrder(request model.SellOrderRequest) (*model.Order, error) {
+	header, err := c.powStamp("create")
+	if err != nil {
+		return nil, err
+	}
+	var order model.Order
+	if err := c.doWithHeader(header, http.MethodPost, "/market/create", nil, request, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// PowChallenge 工作量证明挑战，Required为false时服务端未开启
+type PowChallenge struct {
+	Required bool `json:"required"`
+	pow.Challenge
+}
+
+// powStamp 获取并求解resource的挑战，返回携带印章的请求头，服务端未开启时返回nil
+func (c *Client) powStamp(resource string) (http.Header, error) {
+	var challenge PowChallenge
+	if err := c.do(http.MethodGet, "/pow/challenge", url.Values{"resource": {resource}}, nil, &challenge); err != nil {
+		return nil, err
+	}
+	if !challenge.Required {
+		return nil, nil
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(challenge.ExpiresAt, 0))
+	defer cancel()
+	stamp, err := pow.Solve(ctx, &challenge.Challenge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to solve proof-of-work challenge (difficulty %d): %w", challenge.Difficulty, err)
+	}
+	return http.Header{pow.StampHeader: {stamp}}, nil
+}
+
+// Quote 购买前获取中继费用报价
+func (c *Client) Quote(request model.QuoteRequest) (*Quote, error) {
+	var quote Quote
+	if err := c.do(http.MethodPost, "/market/quote", nil, request, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// Buy 购买NFT，交易广播后服务端返回202，订单状态为settling，outbox_id为结算交易的发件箱记录
+func (c *Client) Buy(request BuyRequest) (*model.Order, error) {
+	var order model.Order
+	if err := c.do(http.MethodPost, "/market/buy", nil, request, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CancelOrder 撤单，signature为卖家对Order.CancelMessage()的personal_sign签名
+func (c *Client) CancelOrder(orderId int64, signature string) (*model.Order, error) {
+	body := map[string]interface{}{"order_id": orderId, "signature": signature}
+	var order model.Order
+	if err := c.do(http.MethodPost, "/market/cancel", nil, body, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListTrades 查询成交记录，query为/market/trades支持的查询参数
+func (c *Client) ListTrades(query url.Values) ([]model.Trade, error) {
+	var trades []model.Trade
+	err := c.do(http.MethodGet, "/market/trades", query, nil, &trades)
+	return trades, err
+}
+
+// PayTokens 查询链上允许的支付代币及其symbol和decimals，chainId为0时为默认链
+func (c *Client) PayTokens(chainId int64) ([]model.PayToken, error) {
+	query := url.Values{}
+	if chainId != 0 {
+		query.Set("chain_id", strconv.FormatInt(chainId, 10))
+	}
+	var tokens []model.PayToken
+	err := c.do(http.MethodGet, "/market/paytokens", query, nil, &tokens)
+	return tokens, err
+}
+
+// MerkleProof 查询地址在白名单默克尔树中的证明，地址不在白名单中时返回404错误
+func (c *Client) MerkleProof(root, address string) (*MerkleProof, error) {
+	var proof MerkleProof
+	query := url.Values{"address": {address}}
+	if err := c.do(http.MethodGet, "/market/merkle/"+root+"/proof", query, nil, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// ExportTrades 流式导出成交记录并写入w，query为/market/trades/export支持的查询参数
+// 导出不受HTTP超时限制，服务端中途失败时返回错误，此时w中只有部分记录
+func (c *Client) ExportTrades(query url.Values, w io.Writer) error {
+	resp, err := c.send(&http.Client{Transport: c.HTTP.Transport}, nil, http.MethodGet, "/market/trades/export", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return err
+	}
+	// trailer在读完响应体后才可用
+	if status := resp.Trailer.Get(model.ExportStatusTrailer); status != model.ExportStatusComplete {
+		if status == "" {
+			status = "connection closed before export completed"
+		}
+		return fmt.Errorf("export incomplete: %s", status)
+	}
+	return nil
+}
+
+// do 发送请求并解析JSON响应，非2xx响应解析为*APIError
+func (c *Client) do(method, path string, query url.Values, body, out interface{}) error {
+	return c.doWithHeader(nil, method, path, query, body, out)
+}
+
+// doWithHeader 与do相同，额外设置请求头
+func (c *Client) doWithHeader(header http.Header, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := c.send(c.HTTP, header, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// send 发送请求，非2xx响应解析为*APIError，成功时由调用方关闭响应体
+func (c *Client) send(httpClient *http.Client, header http.Header, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	apiErr := &APIError{Status: resp.StatusCode}
+	if err := json.Unmarshal(data, &apiErr.ErrorBody); err != nil || apiErr.ErrorBody.Error == "" {
+		apiErr.Code = http.StatusText(resp.StatusCode)
+		apiErr.ErrorBody.Error = strings.TrimSpace(string(data))
+	}
+	return nil, apiErr
+}
@@ -0,0 +1,156 @@
+package erc1271
+
+import (
+	"bytes"
+	"context"
+	"nftmarket/contract"
+	"nftmarket/internal/revert"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MagicValue isValidSignature校验通过时的返回值，即bytes4(keccak256("isValidSignature(bytes32,bytes)"))
+var MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// 缓存条目超过此数量时清理过期条目
+const pruneThreshold = 4096
+
+var erc1271ABI = sync.OnceValues(func() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(contract.ERC1271ABI))
+})
+
+// Verifier 通过合约钱包的isValidSignature校验签名，结果按TTL缓存
+// 多签钱包的owner或阈值可能变化，TTL不宜过长；节点错误不缓存
+type Verifier struct {
+	backend bind.ContractCaller
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu         sync.Mutex
+	codes      map[common.Address]entry
+	signatures map[signatureKey]entry
+}
+
+type entry struct {
+	ok      bool
+	expires time.Time
+}
+
+type signatureKey struct {
+	account   common.Address
+	digest    common.Hash
+	signature common.Hash
+}
+
+// NewVerifier 创建校验器，backend为单条链的节点，ttl为0时不缓存
+func NewVerifier(backend bind.ContractCaller, ttl time.Duration) *Verifier {
+	return &Verifier{
+		backend:    backend,
+		ttl:        ttl,
+		now:        time.Now,
+		codes:      make(map[common.Address]entry),
+		signatures: make(map[signatureKey]entry),
+	}
+}
+
+// IsContract 地址上是否部署了合约代码
+func (v *Verifier) IsContract(ctx context.Context, account common.Address) (bool, error) {
+	if ok, hit := v.lookupCode(account); hit {
+		return ok, nil
+	}
+	code, err := v.backend.CodeAt(ctx, account, nil)
+	if err != nil {
+		return false, err
+	}
+	v.storeCode(account, len(code) > 0)
+	return len(code) > 0, nil
+}
+
+// IsValidSignature 调用account的isValidSignature(digest, signature)，返回值为MagicValue时签名有效
+// 调用revert或返回值不是MagicValue均视为无效签名
+func (v *Verifier) IsValidSignature(ctx context.Context, account common.Address, digest common.Hash, signature []byte) (bool, error) {
+	key := signatureKey{account: account, digest: digest, signature: crypto.Keccak256Hash(signature)}
+	if ok, hit := v.lookupSignature(key); hit {
+		return ok, nil
+	}
+	parsed, err := erc1271ABI()
+	if err != nil {
+		return false, err
+	}
+	input, err := parsed.Pack("isValidSignature", digest, signature)
+	if err != nil {
+		return false, err
+	}
+	output, err := v.backend.CallContract(ctx, ethereum.CallMsg{To: &account, Data: input}, nil)
+	valid := false
+	if err == nil {
+		// 返回值按bytes4左对齐编码在32字节中
+		valid = len(output) >= 4 && bytes.Equal(output[:4], MagicValue[:])
+	} else if revert.FromCallError(err) == nil {
+		// 节点连接等错误直接返回，不视为签名无效
+		return false, err
+	}
+	v.storeSignature(key, valid)
+	return valid, nil
+}
+
+func (v *Verifier) lookupCode(account common.Address) (bool, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	e, ok := v.codes[account]
+	if !ok || v.now().After(e.expires) {
+		return false, false
+	}
+	return e.ok, true
+}
+
+func (v *Verifier) lookupSignature(key signatureKey) (bool, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	e, ok := v.signatures[key]
+	if !ok || v.now().After(e.expires) {
+		return false, false
+	}
+	return e.ok, true
+}
+
+func (v *Verifier) storeCode(account common.Address, ok bool) {
+	if v.ttl <= 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	current := v.now()
+	if len(v.codes) >= pruneThreshold {
+		prune(v.codes, current)
+	}
+	v.codes[account] = entry{ok: ok, expires: current.Add(v.ttl)}
+}
+
+func (v *Verifier) storeSignature(key signatureKey, ok bool) {
+	if v.ttl <= 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	current := v.now()
+	if len(v.signatures) >= pruneThreshold {
+		prune(v.signatures, current)
+	}
+	v.signatures[key] = entry{ok: ok, expires: current.Add(v.ttl)}
+}
+
+func prune[K comparable](entries map[K]entry, now time.Time) {
+	for key, e := range entries {
+		if now.After(e.expires) {
+			delete(entries, key)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package erc1271
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// 模拟合约钱包的运行时代码：调用数据恰好32字节时写入slot 0，其余调用(包括isValidSignature)返回slot 0
+// 测试通过写入MagicValue或其他值控制isValidSignature的结果
+//
+//	CALLDATASIZE PUSH1 0x20 EQ PUSH1 0x12 JUMPI
+//	PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+//	JUMPDEST PUSH1 0 CALLDATALOAD PUSH1 0 SSTORE STOP
+const walletRuntime = "0x3660201460125760005460005260206000f35b60003560005500"
+
+// 任何调用都revert的合约：PUSH1 0 PUSH1 0 REVERT
+const revertRuntime = "0x60006000fd"
+
+// testChain 模拟链和一个有余额的部署账户
+type testChain struct {
+	backend *simulated.Backend
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainId *big.Int
+	nonce   uint64
+}
+
+func newTestChain(t *testing.T) *testChain {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{from: {Balance: big.NewInt(1e18)}})
+	t.Cleanup(func() { backend.Close() })
+	chainId, err := backend.Client().ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testChain{backend: backend, key: key, from: from, chainId: chainId}
+}
+
+// send 发送交易并出块，to为nil时部署合约
+func (c *testChain) send(t *testing.T, to *common.Address, data []byte) {
+	t.Helper()
+	tx, err := types.SignNewTx(c.key, types.LatestSignerForChainID(c.chainId), &types.DynamicFeeTx{
+		ChainID:   c.chainId,
+		Nonce:     c.nonce,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(1e11),
+		Gas:       200000,
+		To:        to,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.backend.Client().SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	c.backend.Commit()
+	c.nonce++
+	receipt, err := c.backend.Client().TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction failed: %v", err)
+	}
+}
+
+// deploy 部署运行时代码为runtime的合约，返回合约地址
+func (c *testChain) deploy(t *testing.T, runtime string) common.Address {
+	t.Helper()
+	code := hexutil.MustDecode(runtime)
+	// PUSH1 len DUP1 PUSH1 0x0b PUSH1 0 CODECOPY PUSH1 0 RETURN，将紧随其后的运行时代码作为合约代码返回
+	initCode := append([]byte{0x60, byte(len(code)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}, code...)
+	address := crypto.CreateAddress(c.from, c.nonce)
+	c.send(t, nil, initCode)
+	return address
+}
+
+// setResult 设置模拟钱包isValidSignature返回的bytes4
+func (c *testChain) setResult(t *testing.T, wallet common.Address, result [4]byte) {
+	t.Helper()
+	word := make([]byte, 32)
+	copy(word, result[:])
+	c.send(t, &wallet, word)
+}
+
+// fakeClock 可手动推进的时间
+type fakeClock struct {
+	mu      sync.Mutex
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = c.current.Add(d)
+}
+
+func TestIsContract(t *testing.T) {
+	c := newTestChain(t)
+	wallet := c.deploy(t, walletRuntime)
+	verifier := NewVerifier(c.backend.Client(), time.Minute)
+	ctx := context.Background()
+
+	if ok, err := verifier.IsContract(ctx, wallet); err != nil || !ok {
+		t.Fatalf("IsContract(wallet) = %v, %v", ok, err)
+	}
+	if ok, err := verifier.IsContract(ctx, c.from); err != nil || ok {
+		t.Fatalf("IsContract(eoa) = %v, %v", ok, err)
+	}
+}
+
+func TestIsValidSignature(t *testing.T) {
+	c := newTestChain(t)
+	wallet := c.deploy(t, walletRuntime)
+	wrongWallet := c.deploy(t, walletRuntime)
+	c.setResult(t, wallet, MagicValue)
+	c.setResult(t, wrongWallet, [4]byte{0x16, 0x26, 0xba, 0x7f})
+	reverting := c.deploy(t, revertRuntime)
+
+	digest := crypto.Keccak256Hash([]byte("order"))
+	signature := []byte("any signature the wallet accepts")
+	tests := []struct {
+		name    string
+		account common.Address
+		want    bool
+	}{
+		{"magic value", wallet, true},
+		{"wrong value", wrongWallet, false},
+		{"revert", reverting, false},
+		{"no code", c.from, false}, // 空返回值不是MagicValue
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// ttl为0时不缓存，每次都调用合约
+			verifier := NewVerifier(c.backend.Client(), 0)
+			valid, err := verifier.IsValidSignature(context.Background(), tt.account, digest, signature)
+			if err != nil {
+				t.Fatalf("IsValidSignature: %v", err)
+			}
+			if valid != tt.want {
+				t.Fatalf("valid = %v, want %v", valid, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := newTestChain(t)
+	wallet := c.deploy(t, walletRuntime)
+	c.setResult(t, wallet, MagicValue)
+
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	verifier := NewVerifier(c.backend.Client(), 30*time.Second)
+	verifier.now = clock.now
+	ctx := context.Background()
+	digest := crypto.Keccak256Hash([]byte("order"))
+	signature := []byte("signature")
+
+	check := func(want bool) {
+		t.Helper()
+		valid, err := verifier.IsValidSignature(ctx, wallet, digest, signature)
+		if err != nil {
+			t.Fatalf("IsValidSignature: %v", err)
+		}
+		if valid != want {
+			t.Fatalf("valid = %v, want %v", valid, want)
+		}
+	}
+	check(true)
+
+	// 多签owner变更后合约不再接受该签名，TTL内仍返回缓存的结果
+	c.setResult(t, wallet, [4]byte{})
+	clock.advance(30 * time.Second)
+	check(true)
+
+	clock.advance(time.Second)
+	check(false)
+
+	// 无效结果同样缓存
+	c.setResult(t, wallet, MagicValue)
+	check(false)
+	clock.advance(31 * time.Second)
+	check(true)
+
+	// 不同的签名不共享缓存
+	valid, err := verifier.IsValidSignature(ctx, wallet, digest, []byte("other signature"))
+	if err != nil || !valid {
+		t.Fatalf("other signature = %v, %v", valid, err)
+	}
+}
+
+func TestCodeCacheExpiry(t *testing.T) {
+	c := newTestChain(t)
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	verifier := NewVerifier(c.backend.Client(), 30*time.Second)
+	verifier.now = clock.now
+	ctx := context.Background()
+
+	// 合约部署前查询到的空代码在TTL内被缓存
+	address := crypto.CreateAddress(c.from, c.nonce)
+	if ok, err := verifier.IsContract(ctx, address); err != nil || ok {
+		t.Fatalf("IsContract before deploy = %v, %v", ok, err)
+	}
+	c.deploy(t, walletRuntime)
+	if ok, _ := verifier.IsContract(ctx, address); ok {
+		t.Fatal("IsContract ignored the cached result")
+	}
+	clock.advance(31 * time.Second)
+	if ok, err := verifier.IsContract(ctx, address); err != nil || !ok {
+		t.Fatalf("IsContract after expiry = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,79 @@
+package erc165
+
+import (
+	"context"
+	"math/big"
+	"nftmarket/contract"
+	"nftmarket/internal/model"
+	"nftmarket/internal/revert"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EIP-165接口id
+var (
+	InterfaceERC165  = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	InterfaceERC721  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	InterfaceERC1155 = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+	interfaceInvalid = [4]byte{0xff, 0xff, 0xff, 0xff}
+)
+
+var erc165ABI = sync.OnceValues(func() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(contract.ERC165ABI))
+})
+
+// Detect 通过ERC165检测NFT合约实现的标准，返回model.TokenStandardERC721或model.TokenStandardERC1155
+// 合约未实现ERC165或两者都不支持时返回空字符串，节点错误直接返回
+func Detect(ctx context.Context, backend bind.ContractCaller, nft common.Address) (string, error) {
+	// 按EIP-165的检测流程，先确认合约实现了ERC165本身，避免fallback函数对任意调用返回true
+	ok, err := supports(ctx, backend, nft, InterfaceERC165)
+	if err != nil || !ok {
+		return "", err
+	}
+	if ok, err = supports(ctx, backend, nft, interfaceInvalid); err != nil || ok {
+		return "", err
+	}
+	for _, standard := range []struct {
+		interfaceId [4]byte
+		name        string
+	}{{InterfaceERC1155, model.TokenStandardERC1155}, {InterfaceERC721, model.TokenStandardERC721}} {
+		ok, err := supports(ctx, backend, nft, standard.interfaceId)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return standard.name, nil
+		}
+	}
+	return "", nil
+}
+
+// supports 调用supportsInterface，调用revert或返回值无法解码时视为不支持
+func supports(ctx context.Context, backend bind.ContractCaller, account common.Address, interfaceId [4]byte) (bool, error) {
+	parsed, err := erc165ABI()
+	if err != nil {
+		return false, err
+	}
+	input, err := parsed.Pack("supportsInterface", interfaceId)
+	if err != nil {
+		return false, err
+	}
+	// EIP-165要求supportsInterface的gas消耗不超过30000
+	output, err := backend.CallContract(ctx, ethereum.CallMsg{To: &account, Gas: 30000, Data: input}, nil)
+	if err != nil {
+		if revert.FromCallError(err) != nil {
+			return false, nil
+		}
+		return false, err
+	}
+	// 没有代码的地址返回空数据
+	if len(output) != 32 {
+		return false, nil
+	}
+	return new(big.Int).SetBytes(output).Cmp(big.NewInt(1)) == 0, nil
+}
@@ -0,0 +1,344 @@
+package ethrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoEndpoint 没有配置可用的节点
+var ErrNoEndpoint = errors.New("no rpc endpoint configured")
+
+// Options 节点切换和健康检查参数，为0的字段使用默认值
+type Options struct {
+	Timeout     time.Duration // 单个节点的读请求超时，超时后换下一个节点重试
+	Cooldown    time.Duration // 节点出错后暂停使用的时间，连续出错时翻倍
+	MaxCooldown time.Duration // 暂停时间上限
+	MaxLag      uint64        // 区块高度落后最高节点超过此值时降低优先级
+}
+
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.Cooldown <= 0 {
+		o.Cooldown = 5 * time.Second
+	}
+	if o.MaxCooldown <= 0 {
+		o.MaxCooldown = 2 * time.Minute
+	}
+	if o.MaxLag == 0 {
+		o.MaxLag = 5
+	}
+	return o
+}
+
+// Client 同一条链的多个RPC节点，实现bind.ContractBackend以及市场用到的其他节点接口
+// 读请求按健康度排序依次尝试，节点连接错误、超时或限流时换下一个节点重试；
+// 交易只发送到一个节点，成功后再异步广播到其他节点
+type Client struct {
+	endpoints []*endpoint
+	options   Options
+}
+
+// Dial 连接所有节点，至少有一个节点连接成功时返回
+// HTTP节点在第一次请求时才建立连接，这里只有URL无效或websocket连接失败会出错
+func Dial(ctx context.Context, urls []string, options Options) (*Client, error) {
+	client := &Client{options: options.withDefaults()}
+	var errs []error
+	for _, url := range urls {
+		ec, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", redact(url), err))
+			continue
+		}
+		client.endpoints = append(client.endpoints, &endpoint{url: url, client: ec})
+	}
+	if len(client.endpoints) == 0 {
+		if len(errs) == 0 {
+			return nil, ErrNoEndpoint
+		}
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Printf("ethrpc: skip endpoint %v", err)
+	}
+	return client, nil
+}
+
+// VerifyChainID 向每个节点查询chain id，节点之间或与expected(不为0时)不一致时返回错误
+// 暂时无法访问的节点只记录错误，所有节点都无法访问时返回错误
+func (c *Client) VerifyChainID(ctx context.Context, expected int64) (*big.Int, error) {
+	var chainID *big.Int
+	var errs []error
+	for _, e := range c.endpoints {
+		id, err := call(c, ctx, e, c.options.Timeout, func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+			return ec.ChainID(ctx)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get chain id of %s: %w", e.name(), err))
+			continue
+		}
+		if expected != 0 && id.Int64() != expected {
+			return nil, fmt.Errorf("rpc %s returned chain id %s, expected %d", e.name(), id, expected)
+		}
+		if chainID != nil && id.Cmp(chainID) != 0 {
+			return nil, fmt.Errorf("rpc %s returned chain id %s, other endpoints returned %s", e.name(), id, chainID)
+		}
+		chainID = id
+	}
+	if chainID == nil {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Printf("ethrpc: %v", err)
+	}
+	return chainID, nil
+}
+
+// read 按健康度依次在节点上执行幂等的读请求，节点故障时换下一个节点，节点正常返回的错误(如revert、NotFound)直接返回
+func read[T any](c *Client, ctx context.Context, method string, fn func(ctx context.Context, ec *ethclient.Client) (T, error)) (T, error) {
+	var zero T
+	if len(c.endpoints) == 0 {
+		return zero, ErrNoEndpoint
+	}
+	var errs []error
+	for _, e := range c.ranked() {
+		result, err := call(c, ctx, e, c.options.Timeout, fn)
+		if err == nil || ctx.Err() != nil || !isNodeFailure(err) {
+			return result, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", e.name(), err))
+	}
+	return zero, fmt.Errorf("%s failed on all rpc endpoints: %w", method, errors.Join(errs...))
+}
+
+// call 在单个节点上执行请求并记录延迟和健康状态，timeout为单个节点的超时；调用方的ctx结束时不计入节点健康度
+func call[T any](c *Client, ctx context.Context, e *endpoint, timeout time.Duration, fn func(ctx context.Context, ec *ethclient.Client) (T, error)) (T, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	result, err := fn(attemptCtx, e.client)
+	switch {
+	case ctx.Err() != nil:
+	case err != nil && isNodeFailure(err):
+		e.failure(err, c.options)
+	default:
+		e.success(time.Since(start))
+	}
+	return result, err
+}
+
+// isNodeFailure 判断错误是否由节点本身引起(连接失败、超时、限流、节点内部错误)，此时应换节点重试
+// revert以及NotFound等节点正常返回的结果不换节点
+func isNodeFailure(err error) bool {
+	if errors.Is(err, ethereum.NotFound) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case -32601, -32603, -32005: // 方法不支持、节点内部错误、请求超出限额
+			return true
+		case -32000:
+			// 节点数据不完整(落后或已裁剪的状态)，其他节点可能有数据
+			return isMissingState(err)
+		}
+		return false
+	}
+	return true
+}
+
+// SendTransaction 按健康度选择一个节点发送交易，连接失败时换下一个节点；发送成功后异步广播到其他节点，
+// 避免单个节点没有把交易传播出去。节点拒绝交易(nonce过低、手续费不足等)时直接返回错误
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if len(c.endpoints) == 0 {
+		return ErrNoEndpoint
+	}
+	ranked := c.ranked()
+	var errs []error
+	for i, e := range ranked {
+		_, err := call(c, ctx, e, c.options.Timeout, func(ctx context.Context, ec *ethclient.Client) (struct{}, error) {
+			return struct{}{}, ec.SendTransaction(ctx, tx)
+		})
+		if err == nil || IsKnownTransaction(err) {
+			others := append(append([]*endpoint(nil), ranked[:i]...), ranked[i+1:]...)
+			go c.rebroadcast(tx, others)
+			return err
+		}
+		if ctx.Err() != nil || !isNodeFailure(err) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", e.name(), err))
+	}
+	return fmt.Errorf("eth_sendRawTransaction failed on all rpc endpoints: %w", errors.Join(errs...))
+}
+
+// rebroadcast 把已发送的交易广播到其他节点，节点已有该交易或已上链时忽略
+func (c *Client) rebroadcast(tx *types.Transaction, endpoints []*endpoint) {
+	for _, e := range endpoints {
+		if !e.available(time.Now()) {
+			continue
+		}
+		_, err := call(c, context.Background(), e, c.options.Timeout, func(ctx context.Context, ec *ethclient.Client) (struct{}, error) {
+			return struct{}{}, ec.SendTransaction(ctx, tx)
+		})
+		if err != nil && !IsKnownTransaction(err) && !isNonceTooLow(err) {
+			log.Printf("ethrpc: rebroadcast %s to %s: %v", tx.Hash().Hex(), e.name(), err)
+		}
+	}
+}
+
+// BatchCallContext 批量JSON-RPC请求，整批失败时换节点重试，单个请求的错误记录在BatchElem.Error中
+func (c *Client) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error {
+	_, err := read(c, ctx, "batch", func(ctx context.Context, ec *ethclient.Client) (struct{}, error) {
+		return struct{}{}, ec.Client().BatchCallContext(ctx, batch)
+	})
+	return err
+}
+
+// SubscribeFilterLogs 在第一个可以订阅的节点上订阅日志，需要websocket节点；订阅中断后由调用方重新订阅
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return read(c, ctx, "eth_subscribe", func(ctx context.Context, ec *ethclient.Client) (ethereum.Subscription, error) {
+		return ec.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	return read(c, ctx, "eth_chainId", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.ChainID(ctx)
+	})
+}
+
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return read(c, ctx, "eth_blockNumber", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.BlockNumber(ctx)
+	})
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return read(c, ctx, "eth_getBlockByNumber", func(ctx context.Context, ec *ethclient.Client) (*types.Header, error) {
+		return ec.HeaderByNumber(ctx, number)
+	})
+}
+
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return read(c, ctx, "eth_getBlockByHash", func(ctx context.Context, ec *ethclient.Client) (*types.Header, error) {
+		return ec.HeaderByHash(ctx, hash)
+	})
+}
+
+func (c *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return read(c, ctx, "eth_getBlockByHash", func(ctx context.Context, ec *ethclient.Client) (*types.Block, error) {
+		return ec.BlockByHash(ctx, hash)
+	})
+}
+
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+	r, err := read(c, ctx, "eth_getTransactionByHash", func(ctx context.Context, ec *ethclient.Client) (result, error) {
+		tx, isPending, err := ec.TransactionByHash(ctx, hash)
+		return result{tx, isPending}, err
+	})
+	return r.tx, r.isPending, err
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return read(c, ctx, "eth_getTransactionReceipt", func(ctx context.Context, ec *ethclient.Client) (*types.Receipt, error) {
+		return ec.TransactionReceipt(ctx, hash)
+	})
+}
+
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return read(c, ctx, "eth_getBalance", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return read(c, ctx, "eth_getTransactionCount", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return read(c, ctx, "eth_getTransactionCount", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.PendingNonceAt(ctx, account)
+	})
+}
+
+func (c *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return read(c, ctx, "eth_getCode", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) CodeAtHash(ctx context.Context, account common.Address, blockHash common.Hash) ([]byte, error) {
+	return read(c, ctx, "eth_getCode", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CodeAtHash(ctx, account, blockHash)
+	})
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return read(c, ctx, "eth_getCode", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.PendingCodeAt(ctx, account)
+	})
+}
+
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return read(c, ctx, "eth_call", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (c *Client) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	return read(c, ctx, "eth_call", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.CallContractAtHash(ctx, msg, blockHash)
+	})
+}
+
+func (c *Client) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return read(c, ctx, "eth_call", func(ctx context.Context, ec *ethclient.Client) ([]byte, error) {
+		return ec.PendingCallContract(ctx, msg)
+	})
+}
+
+func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return read(c, ctx, "eth_estimateGas", func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+		return ec.EstimateGas(ctx, msg)
+	})
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return read(c, ctx, "eth_gasPrice", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.SuggestGasPrice(ctx)
+	})
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return read(c, ctx, "eth_maxPriorityFeePerGas", func(ctx context.Context, ec *ethclient.Client) (*big.Int, error) {
+		return ec.SuggestGasTipCap(ctx)
+	})
+}
+
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return read(c, ctx, "eth_getLogs", func(ctx context.Context, ec *ethclient.Client) ([]types.Log, error) {
+		return ec.FilterLogs(ctx, query)
+	})
+}
@@ -0,0 +1,191 @@
+package ethrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 模拟节点的响应方式
+const (
+	modeOK       = iota
+	modeHTTP     // 返回HTTP 503
+	modeTimeout  // 直到客户端超时都不响应
+	modeInternal // JSON-RPC内部错误-32603
+	modeRevert   // eth_call revert，属于节点正常返回的结果
+)
+
+// node 返回固定区块高度的模拟JSON-RPC节点
+type node struct {
+	head     uint64
+	mode     atomic.Int32
+	requests atomic.Int32
+	server   *httptest.Server
+}
+
+func newNode(t *testing.T, head uint64, mode int32) *node {
+	t.Helper()
+	n := &node{head: head}
+	n.mode.Store(mode)
+	n.server = httptest.NewServer(http.HandlerFunc(n.serve))
+	t.Cleanup(n.server.Close)
+	return n
+}
+
+func (n *node) serve(w http.ResponseWriter, r *http.Request) {
+	n.requests.Add(1)
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch n.mode.Load() {
+	case modeHTTP:
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	case modeTimeout:
+		<-r.Context().Done()
+		return
+	case modeInternal:
+		resp["error"] = map[string]interface{}{"code": -32603, "message": "internal error"}
+	case modeRevert:
+		resp["error"] = map[string]interface{}{"code": 3, "message": "execution reverted", "data": "0x"}
+	default:
+		resp["result"] = fmt.Sprintf("0x%x", n.head)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func dial(t *testing.T, options Options, nodes ...*node) *Client {
+	t.Helper()
+	urls := make([]string, len(nodes))
+	for i, n := range nodes {
+		urls[i] = n.server.URL
+	}
+	client, err := Dial(context.Background(), urls, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestReadFailsOver(t *testing.T) {
+	tests := []struct {
+		name string
+		mode int32
+	}{
+		{"http error", modeHTTP},
+		{"timeout", modeTimeout},
+		{"internal error", modeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad, good := newNode(t, 1, tt.mode), newNode(t, 2, modeOK)
+			client := dial(t, Options{Timeout: 200 * time.Millisecond, Cooldown: time.Minute}, bad, good)
+
+			head, err := client.BlockNumber(context.Background())
+			if err != nil || head != 2 {
+				t.Fatalf("BlockNumber = %d, %v; want 2 from the second endpoint", head, err)
+			}
+			status := client.Status()
+			if status[0].Available || status[0].Failures != 1 || status[0].LastError == "" || !status[1].Available {
+				t.Fatalf("status = %+v", status)
+			}
+
+			// 暂停期间不再请求出错的节点
+			if _, err := client.BlockNumber(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := bad.requests.Load(); got != 1 {
+				t.Fatalf("unhealthy endpoint received %d requests, want 1", got)
+			}
+		})
+	}
+}
+
+func TestUnhealthyEndpointRetriedAfterCooldown(t *testing.T) {
+	first, second := newNode(t, 1, modeHTTP), newNode(t, 2, modeOK)
+	cooldown := 100 * time.Millisecond
+	client := dial(t, Options{Cooldown: cooldown}, first, second)
+
+	if head, err := client.BlockNumber(context.Background()); err != nil || head != 2 {
+		t.Fatalf("BlockNumber = %d, %v", head, err)
+	}
+	first.mode.Store(modeOK)
+	if head, err := client.BlockNumber(context.Background()); err != nil || head != 2 {
+		t.Fatalf("BlockNumber during cooldown = %d, %v", head, err)
+	}
+
+	// 暂停结束后重新参与排序，第一个节点没有成功请求的延迟记录，排在前面
+	time.Sleep(cooldown)
+	if head, err := client.BlockNumber(context.Background()); err != nil || head != 1 {
+		t.Fatalf("BlockNumber after cooldown = %d, %v; want 1 from the recovered endpoint", head, err)
+	}
+	if status := client.Status(); !status[0].Available || status[0].Failures != 0 {
+		t.Fatalf("status = %+v", status)
+	}
+}
+
+func TestCooldownDoublesOnConsecutiveFailures(t *testing.T) {
+	bad := newNode(t, 1, modeHTTP)
+	options := Options{Cooldown: 100 * time.Millisecond, MaxCooldown: 150 * time.Millisecond}
+	client := dial(t, options, bad)
+	e := client.endpoints[0]
+
+	for i, want := range []time.Duration{100 * time.Millisecond, 150 * time.Millisecond} {
+		start := time.Now()
+		if _, err := client.BlockNumber(context.Background()); err == nil {
+			t.Fatal("BlockNumber succeeded on a failing endpoint")
+		}
+		e.mu.Lock()
+		got := e.downUntil.Sub(start)
+		e.mu.Unlock()
+		if got < want || got > want+50*time.Millisecond {
+			t.Fatalf("failure %d: cooldown %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestReadDoesNotRetryNodeResults(t *testing.T) {
+	first, second := newNode(t, 1, modeRevert), newNode(t, 2, modeOK)
+	client := dial(t, Options{}, first, second)
+
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("CallContract error = %v, want revert", err)
+	}
+	if second.requests.Load() != 0 {
+		t.Fatal("revert was retried on another endpoint")
+	}
+	if status := client.Status(); !status[0].Available {
+		t.Fatalf("revert marked endpoint unhealthy: %+v", status[0])
+	}
+}
+
+func TestReadAllEndpointsFail(t *testing.T) {
+	client := dial(t, Options{}, newNode(t, 1, modeHTTP), newNode(t, 2, modeInternal))
+	_, err := client.BlockNumber(context.Background())
+	if err == nil {
+		t.Fatal("BlockNumber succeeded with no healthy endpoint")
+	}
+	for _, status := range client.Status() {
+		if status.Available {
+			t.Fatalf("status = %+v", status)
+		}
+	}
+}
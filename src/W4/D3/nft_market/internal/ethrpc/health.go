@@ -0,0 +1,222 @@
+package ethrpc
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 延迟按指数加权移动平均计算，新样本的权重
+const latencyWeight = 0.2
+
+// endpoint 单个RPC节点及其健康状态
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+
+	mu          sync.Mutex
+	latency     time.Duration // 成功请求延迟的移动平均
+	failures    int           // 连续失败次数，成功后清零
+	downUntil   time.Time     // 暂停使用到此时间
+	head        uint64        // 最近一次健康检查得到的区块高度
+	requests    uint64
+	errors      uint64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// EndpointStatus 节点健康状态，用于管理接口展示
+type EndpointStatus struct {
+	URL         string `json:"url"` // 只保留scheme和host，避免泄露URL中的API key
+	Available   bool   `json:"available"`
+	LatencyMs   int64  `json:"latency_ms"`
+	Failures    int    `json:"failures"`
+	DownUntil   int64  `json:"down_until,omitempty"`
+	Head        uint64 `json:"head"`
+	Lagging     bool   `json:"lagging"`
+	Requests    uint64 `json:"requests"`
+	Errors      uint64 `json:"errors"`
+	LastError   string `json:"last_error,omitempty"`
+	LastErrorAt int64  `json:"last_error_at,omitempty"`
+}
+
+func (e *endpoint) name() string {
+	return redact(e.url)
+}
+
+func (e *endpoint) success(latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests++
+	e.failures = 0
+	e.downUntil = time.Time{}
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(e.latency))
+	}
+}
+
+// failure 记录节点错误，暂停使用的时间随连续失败次数翻倍
+func (e *endpoint) failure(err error, options Options) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests++
+	e.errors++
+	e.failures++
+	e.lastError, e.lastErrorAt = err.Error(), time.Now()
+	cooldown := options.Cooldown
+	for i := 1; i < e.failures && cooldown < options.MaxCooldown; i++ {
+		cooldown *= 2
+	}
+	e.downUntil = time.Now().Add(min(cooldown, options.MaxCooldown))
+}
+
+func (e *endpoint) available(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.downUntil)
+}
+
+// ranked 按健康度排序节点：可用的在前，其中区块高度不落后的在前，再按延迟从低到高；
+// 暂停中的节点排在最后，所有节点都暂停时仍会尝试。延迟相同时保持配置顺序
+func (c *Client) ranked() []*endpoint {
+	type score struct {
+		e         *endpoint
+		available bool
+		lagging   bool
+		latency   time.Duration
+	}
+	now := time.Now()
+	maxHead := c.maxHead()
+	scores := make([]score, len(c.endpoints))
+	for i, e := range c.endpoints {
+		e.mu.Lock()
+		scores[i] = score{
+			e:         e,
+			available: !now.Before(e.downUntil),
+			lagging:   e.head+c.options.MaxLag < maxHead,
+			latency:   e.latency,
+		}
+		e.mu.Unlock()
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].available != scores[j].available {
+			return scores[i].available
+		}
+		if scores[i].lagging != scores[j].lagging {
+			return !scores[i].lagging
+		}
+		return scores[i].latency < scores[j].latency
+	})
+	ranked := make([]*endpoint, len(scores))
+	for i, s := range scores {
+		ranked[i] = s.e
+	}
+	return ranked
+}
+
+func (c *Client) maxHead() uint64 {
+	var head uint64
+	for _, e := range c.endpoints {
+		e.mu.Lock()
+		head = max(head, e.head)
+		e.mu.Unlock()
+	}
+	return head
+}
+
+// Probe 向每个节点查询区块高度，更新延迟、高度和可用状态，暂停中的节点恢复后重新参与排序
+func (c *Client) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			head, err := call(c, ctx, e, c.options.Timeout, func(ctx context.Context, ec *ethclient.Client) (uint64, error) {
+				return ec.BlockNumber(ctx)
+			})
+			if err == nil {
+				e.mu.Lock()
+				e.head = head
+				e.mu.Unlock()
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+// MonitorHealth 按间隔检查所有节点的健康状态，直到ctx结束
+func (c *Client) MonitorHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status 按配置顺序返回所有节点的健康状态
+func (c *Client) Status() []EndpointStatus {
+	now := time.Now()
+	maxHead := c.maxHead()
+	statuses := make([]EndpointStatus, 0, len(c.endpoints))
+	for _, e := range c.endpoints {
+		e.mu.Lock()
+		status := EndpointStatus{
+			URL:       e.name(),
+			Available: !now.Before(e.downUntil),
+			LatencyMs: e.latency.Milliseconds(),
+			Failures:  e.failures,
+			Head:      e.head,
+			Lagging:   e.head+c.options.MaxLag < maxHead,
+			Requests:  e.requests,
+			Errors:    e.errors,
+			LastError: e.lastError,
+		}
+		if !status.Available {
+			status.DownUntil = e.downUntil.Unix()
+		}
+		if !e.lastErrorAt.IsZero() {
+			status.LastErrorAt = e.lastErrorAt.Unix()
+		}
+		e.mu.Unlock()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// redact 去掉URL的路径、参数和用户信息，节点服务商的API key通常在这些位置
+func redact(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "invalid url"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// IsKnownTransaction 节点返回交易已存在于交易池
+func IsKnownTransaction(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "already known") || strings.Contains(message, "known transaction")
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// isMissingState 节点没有请求的区块或状态数据，通常是节点落后或已裁剪历史状态
+func isMissingState(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "header not found") || strings.Contains(message, "missing trie node") ||
+		strings.Contains(message, "unknown block")
+}
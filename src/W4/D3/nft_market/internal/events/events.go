@@ -0,0 +1,119 @@
+package events
+
+import (
+	"log"
+	"nftmarket/internal/model"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 订单事件类型
+const (
+	OrderCreated         = "order.created"          // 上架
+	OrderSettling        = "order.settling"         // 结算交易已签名落库，等待上链；ERC1155订单部分购买时订单仍为open
+	OrderPartiallyFilled = "order.partially_filled" // ERC1155订单部分成交，剩余数量仍可购买
+	OrderFilled          = "order.filled"           // 成交
+	OrderReopened        = "order.reopened"         // 结算交易失败或被丢弃，锁定的数量退回订单并重新上架
+	OrderCancelled       = "order.cancelled"        // 卖家撤单
+	OrderExpired         = "order.expired"          // 过期
+	OrderInvalidated     = "order.invalidated"      // NFT所有权转移或授权被撤销
+)
+
+// Types 所有订单事件类型
+var Types = []string{OrderCreated, OrderSettling, OrderPartiallyFilled, OrderFilled, OrderReopened, OrderCancelled, OrderExpired, OrderInvalidated}
+
+// Event 订单状态变化事件，Order为状态变化后的订单
+type Event struct {
+	Type  string
+	Order model.Order
+	Time  int64
+}
+
+// Subscription 事件订阅，消费过慢导致缓冲区写满时订阅被关闭，C随之关闭
+type Subscription struct {
+	C       <-chan Event
+	ch      chan Event
+	hub     *Hub
+	closed  bool
+	dropped bool
+}
+
+// Close 取消订阅
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.close(s)
+}
+
+// Dropped 订阅是否因消费过慢被关闭，C关闭后调用
+func (s *Subscription) Dropped() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// Hub 进程内的订单事件分发，事件在数据库事务提交后发布
+type Hub struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// Default 默认的事件分发
+var Default = &Hub{subs: make(map[*Subscription]struct{})}
+
+// Subscribe 订阅所有订单事件，buffer为缓冲的事件数
+func (h *Hub) Subscribe(buffer int) *Subscription {
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, hub: h}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish 向所有订阅方发布事件，不阻塞发布方
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			log.Printf("events: subscriber too slow, dropping subscription")
+			sub.dropped = true
+			h.close(sub)
+		}
+	}
+}
+
+func (h *Hub) close(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(h.subs, sub)
+	close(sub.ch)
+}
+
+// Subscribe 订阅默认分发的订单事件
+func Subscribe(buffer int) *Subscription {
+	return Default.Subscribe(buffer)
+}
+
+// PublishOrders 读取状态变化后的订单并发布事件，读取失败只记录日志，不影响已提交的业务
+func PublishOrders(db *gorm.DB, eventType string, orderIds ...int64) {
+	if len(orderIds) == 0 {
+		return
+	}
+	var orders []model.Order
+	if err := db.Where("order_id IN ?", orderIds).Order("order_id").Find(&orders).Error; err != nil {
+		log.Printf("events: failed to load orders for %s: %v", eventType, err)
+		return
+	}
+	current := time.Now().Unix()
+	for _, order := range orders {
+		Default.Publish(Event{Type: eventType, Order: order, Time: current})
+	}
+}
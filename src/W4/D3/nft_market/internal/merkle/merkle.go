@@ -0,0 +1,215 @@
+package merkle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// OpenZeppelin StandardMerkleTree导出格式，只支持单个address字段的叶子
+const (
+	Format       = "standard-v1"
+	LeafEncoding = "address"
+)
+
+// Tree 与OpenZeppelin StandardMerkleTree.dump()格式一致的默克尔树
+// 叶子为keccak256(keccak256(abi.encode(address)))，节点对两个子节点排序后哈希，可直接用合约MerkleProof.verify校验
+type Tree struct {
+	Format       string   `json:"format"`
+	LeafEncoding []string `json:"leafEncoding"`
+	Tree         []string `json:"tree"`
+	Values       []Value  `json:"values"`
+}
+
+// Value 叶子原始值及其在tree数组中的位置
+type Value struct {
+	Value     []string `json:"value"`
+	TreeIndex int      `json:"treeIndex"`
+}
+
+// Build 由地址列表构建默克尔树，地址不能重复
+func Build(addresses []common.Address) (*Tree, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("address list is empty")
+	}
+	type leaf struct {
+		hash  common.Hash
+		index int
+	}
+	seen := make(map[common.Address]bool, len(addresses))
+	leaves := make([]leaf, len(addresses))
+	for i, address := range addresses {
+		if seen[address] {
+			return nil, fmt.Errorf("duplicate address %s", address.Hex())
+		}
+		seen[address] = true
+		leaves[i] = leaf{hash: LeafHash(address), index: i}
+	}
+	// 与OpenZeppelin一致，叶子按哈希排序后倒序放在tree数组末尾
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i].hash[:], leaves[j].hash[:]) < 0 })
+
+	nodes := make([]common.Hash, 2*len(leaves)-1)
+	values := make([]Value, len(addresses))
+	for i, l := range leaves {
+		treeIndex := len(nodes) - 1 - i
+		nodes[treeIndex] = l.hash
+		values[l.index] = Value{Value: []string{addresses[l.index].Hex()}, TreeIndex: treeIndex}
+	}
+	for i := len(nodes) - 1 - len(leaves); i >= 0; i-- {
+		nodes[i] = hashPair(nodes[2*i+1], nodes[2*i+2])
+	}
+
+	tree := &Tree{Format: Format, LeafEncoding: []string{LeafEncoding}, Values: values}
+	for _, node := range nodes {
+		tree.Tree = append(tree.Tree, node.Hex())
+	}
+	return tree, nil
+}
+
+// Load 导入OpenZeppelin StandardMerkleTree.dump()格式的JSON，并校验树结构完整
+func Load(data []byte) (*Tree, error) {
+	var tree Tree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("invalid merkle tree dump: %w", err)
+	}
+	if err := tree.Validate(); err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// Validate 校验格式、叶子编码，以及每个叶子和内部节点的哈希
+func (t *Tree) Validate() error {
+	if t.Format != Format {
+		return fmt.Errorf("unsupported merkle tree format %q", t.Format)
+	}
+	if len(t.LeafEncoding) != 1 || t.LeafEncoding[0] != LeafEncoding {
+		return fmt.Errorf("unsupported leaf encoding %v, only [\"address\"] is supported", t.LeafEncoding)
+	}
+	if len(t.Values) == 0 || len(t.Tree) != 2*len(t.Values)-1 {
+		return errors.New("tree size does not match values")
+	}
+	nodes := make([]common.Hash, len(t.Tree))
+	for i, node := range t.Tree {
+		decoded, err := hexutil.Decode(node)
+		if err != nil || len(decoded) != common.HashLength {
+			return fmt.Errorf("invalid tree node %d", i)
+		}
+		nodes[i] = common.BytesToHash(decoded)
+	}
+	leafStart := len(nodes) - len(t.Values)
+	for i, value := range t.Values {
+		if len(value.Value) != 1 || !common.IsHexAddress(value.Value[0]) {
+			return fmt.Errorf("invalid value %d", i)
+		}
+		if value.TreeIndex < leafStart || value.TreeIndex >= len(nodes) {
+			return fmt.Errorf("value %d is not a leaf", i)
+		}
+		if nodes[value.TreeIndex] != LeafHash(common.HexToAddress(value.Value[0])) {
+			return fmt.Errorf("leaf hash of value %d does not match", i)
+		}
+	}
+	for i := leafStart - 1; i >= 0; i-- {
+		if nodes[i] != hashPair(nodes[2*i+1], nodes[2*i+2]) {
+			return fmt.Errorf("tree node %d does not match its children", i)
+		}
+	}
+	return nil
+}
+
+// Root 默克尔树根
+func (t *Tree) Root() common.Hash {
+	return common.HexToHash(t.Tree[0])
+}
+
+// Addresses 树中包含的地址，按导入或构建时的顺序
+func (t *Tree) Addresses() []common.Address {
+	addresses := make([]common.Address, len(t.Values))
+	for i, value := range t.Values {
+		addresses[i] = common.HexToAddress(value.Value[0])
+	}
+	return addresses
+}
+
+// Proof 生成地址的默克尔证明，地址不在树中时返回错误
+func (t *Tree) Proof(address common.Address) ([]common.Hash, error) {
+	for _, value := range t.Values {
+		if common.HexToAddress(value.Value[0]) != address {
+			continue
+		}
+		var proof []common.Hash
+		for i := value.TreeIndex; i > 0; i = (i - 1) / 2 {
+			// 奇数位置为左子节点，兄弟节点在右侧
+			sibling := i - 1
+			if i%2 == 1 {
+				sibling = i + 1
+			}
+			proof = append(proof, common.HexToHash(t.Tree[sibling]))
+		}
+		return proof, nil
+	}
+	return nil, fmt.Errorf("address %s is not in the merkle tree", address.Hex())
+}
+
+// Verify 校验地址的默克尔证明，与合约MerkleProof.verify一致
+func Verify(root common.Hash, address common.Address, proof []common.Hash) bool {
+	computed := LeafHash(address)
+	for _, node := range proof {
+		computed = hashPair(computed, node)
+	}
+	return computed == root
+}
+
+// LeafHash OpenZeppelin StandardMerkleTree的叶子哈希: keccak256(bytes.concat(keccak256(abi.encode(address))))
+func LeafHash(address common.Address) common.Hash {
+	encoded := common.LeftPadBytes(address.Bytes(), 32)
+	return crypto.Keccak256Hash(crypto.Keccak256(encoded))
+}
+
+// ParseAddresses 解析地址列表，支持每行一个地址或CSV(取第一列，允许表头)
+func ParseAddresses(r io.Reader) ([]common.Address, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	var addresses []common.Address
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := strings.TrimSpace(record[0])
+		if field == "" {
+			continue
+		}
+		if !common.IsHexAddress(field) {
+			// 第一行不是地址时视为表头
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid address %q", line, field)
+		}
+		addresses = append(addresses, common.HexToAddress(field))
+	}
+	return addresses, nil
+}
+
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
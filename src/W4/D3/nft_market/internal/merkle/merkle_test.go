@@ -0,0 +1,186 @@
+package merkle
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 以下数据按@openzeppelin/merkle-tree的StandardMerkleTree.of(values, ["address"])生成:
+// ozDump为dump()导出的JSON，ozProofs为getProof(value)返回的证明
+var ozAddresses = []string{
+	"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+	"0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+	"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC",
+	"0x90F79bf6EB2c4f870365E785982E1f101E93b906",
+	"0x15d34AAf54267DB7D7c367839AAf71A00a2C6A65",
+}
+
+const ozRoot = "0x0548845532e20163d626e767b171816a20d7fc27b38d1543e06f13050f4084e0"
+
+const ozDump = `{
+	"format": "standard-v1",
+	"leafEncoding": ["address"],
+	"tree": [
+		"0x0548845532e20163d626e767b171816a20d7fc27b38d1543e06f13050f4084e0",
+		"0xddaa93f15dd99b40f2955e44729a8065a94c5867725193607d5f1e46d5ec1e67",
+		"0xe5b6e3298144ffbc1521ae5d17fdce81184e774c84119e5d254141bd246c771f",
+		"0x166a633689f07198f116bd599dbcfafd186431540ba501bc90f55692742b0374",
+		"0xd791b4384f11048b2330e9ec924a5c80226526b5e9d7f65537637981af4d404f",
+		"0x9b0bc27a9e8f6a8a4b2e92b71ac31b44ef9bd5a54f150ed7b7c2668c6b9be039",
+		"0x895fcfca45b761f42d85849ce9d8b111905c7417e64dcc0738a7abbae89ad17e",
+		"0x32235e7434a20509b8e17860e4d7b9b0a551e3696a7eac2153aad4e6c348bc46",
+		"0x208697df1b2d4c083944c10909fe1ed6e99c1eaccff33ba129464b28f8245f01"
+	],
+	"values": [
+		{"value": ["0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"], "treeIndex": 4},
+		{"value": ["0x70997970C51812dc3A010C7d01b50e0d17dc79C8"], "treeIndex": 8},
+		{"value": ["0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"], "treeIndex": 5},
+		{"value": ["0x90F79bf6EB2c4f870365E785982E1f101E93b906"], "treeIndex": 7},
+		{"value": ["0x15d34AAf54267DB7D7c367839AAf71A00a2C6A65"], "treeIndex": 6}
+	]
+}`
+
+var ozProofs = map[string][]string{
+	"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266": {
+		"0x166a633689f07198f116bd599dbcfafd186431540ba501bc90f55692742b0374",
+		"0xe5b6e3298144ffbc1521ae5d17fdce81184e774c84119e5d254141bd246c771f",
+	},
+	"0x70997970C51812dc3A010C7d01b50e0d17dc79C8": {
+		"0x32235e7434a20509b8e17860e4d7b9b0a551e3696a7eac2153aad4e6c348bc46",
+		"0xd791b4384f11048b2330e9ec924a5c80226526b5e9d7f65537637981af4d404f",
+		"0xe5b6e3298144ffbc1521ae5d17fdce81184e774c84119e5d254141bd246c771f",
+	},
+	"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC": {
+		"0x895fcfca45b761f42d85849ce9d8b111905c7417e64dcc0738a7abbae89ad17e",
+		"0xddaa93f15dd99b40f2955e44729a8065a94c5867725193607d5f1e46d5ec1e67",
+	},
+	"0x90F79bf6EB2c4f870365E785982E1f101E93b906": {
+		"0x208697df1b2d4c083944c10909fe1ed6e99c1eaccff33ba129464b28f8245f01",
+		"0xd791b4384f11048b2330e9ec924a5c80226526b5e9d7f65537637981af4d404f",
+		"0xe5b6e3298144ffbc1521ae5d17fdce81184e774c84119e5d254141bd246c771f",
+	},
+	"0x15d34AAf54267DB7D7c367839AAf71A00a2C6A65": {
+		"0x9b0bc27a9e8f6a8a4b2e92b71ac31b44ef9bd5a54f150ed7b7c2668c6b9be039",
+		"0xddaa93f15dd99b40f2955e44729a8065a94c5867725193607d5f1e46d5ec1e67",
+	},
+}
+
+// outsider 不在白名单中的地址
+var outsider = common.HexToAddress("0x9965507D1a55bcC2695C58ba16FB37d819B0A4dc")
+
+func ozAddressList() []common.Address {
+	list := make([]common.Address, len(ozAddresses))
+	for i, address := range ozAddresses {
+		list[i] = common.HexToAddress(address)
+	}
+	return list
+}
+
+func hashes(list []string) []common.Hash {
+	proof := make([]common.Hash, len(list))
+	for i, node := range list {
+		proof[i] = common.HexToHash(node)
+	}
+	return proof
+}
+
+func TestBuildMatchesOpenZeppelin(t *testing.T) {
+	tree, err := Build(ozAddressList())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Root() != common.HexToHash(ozRoot) {
+		t.Fatalf("root = %s, want %s", tree.Root().Hex(), ozRoot)
+	}
+	// 叶子顺序、treeIndex和导出字段都需与OpenZeppelin一致
+	var want Tree
+	if err := json.Unmarshal([]byte(ozDump), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*tree, want) {
+		t.Fatalf("tree = %+v, want %+v", *tree, want)
+	}
+	for address, ozProof := range ozProofs {
+		proof, err := tree.Proof(common.HexToAddress(address))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(proof, hashes(ozProof)) {
+			t.Errorf("proof of %s = %v, want %v", address, proof, ozProof)
+		}
+	}
+	if _, err := tree.Proof(outsider); err == nil {
+		t.Fatal("proof generated for address not in tree")
+	}
+}
+
+func TestLoadRoundTrip(t *testing.T) {
+	tree, err := Load([]byte(ozDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tree.Addresses(), ozAddressList()) {
+		t.Fatalf("addresses = %v", tree.Addresses())
+	}
+	exported, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded, tree) {
+		t.Fatalf("reloaded = %+v, want %+v", reloaded, tree)
+	}
+}
+
+func TestLoadRejectsTamperedDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"format", `"standard-v1"`, `"standard-v2"`, "unsupported merkle tree format"},
+		{"leaf encoding", `["address"]`, `["address", "uint256"]`, "unsupported leaf encoding"},
+		{"root", ozRoot, "0x" + strings.Repeat("00", 32), "tree node 0 does not match"},
+		{"value", ozAddresses[0], outsider.Hex(), "leaf hash of value 0 does not match"},
+		{"tree index", `"treeIndex": 4`, `"treeIndex": 1`, "value 0 is not a leaf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dump := strings.Replace(ozDump, tt.old, tt.new, 1)
+			if dump == ozDump {
+				t.Fatalf("%q not found in dump", tt.old)
+			}
+			if _, err := Load([]byte(dump)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyOpenZeppelinProofs(t *testing.T) {
+	root := common.HexToHash(ozRoot)
+	for address, ozProof := range ozProofs {
+		proof := hashes(ozProof)
+		if !Verify(root, common.HexToAddress(address), proof) {
+			t.Errorf("proof of %s rejected", address)
+		}
+		tampered := append([]common.Hash(nil), proof...)
+		tampered[0][31] ^= 1
+		if Verify(root, common.HexToAddress(address), tampered) {
+			t.Errorf("tampered proof of %s accepted", address)
+		}
+		if Verify(root, common.HexToAddress(address), proof[:len(proof)-1]) {
+			t.Errorf("truncated proof of %s accepted", address)
+		}
+		if Verify(root, outsider, proof) {
+			t.Errorf("proof of %s accepted for %s", address, outsider.Hex())
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+// AdminSignature 已使用的管理员签名，Digest为签名消息的personal_sign摘要，过期后可以清理
+type AdminSignature struct {
+	Digest    string `gorm:"column:digest;primaryKey;comment:签名消息摘要"`
+	ExpiresAt int64  `gorm:"column:expires_at;index:idx_admin_signature_expires_at;comment:签名过期时间"`
+}
+
+func (a *AdminSignature) TableName() string {
+	return "admin_signature"
+}
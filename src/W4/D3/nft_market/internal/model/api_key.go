@@ -0,0 +1,22 @@
+package model
+
+// API key状态
+const (
+	ApiKeyStatusActive  = "active"
+	ApiKeyStatusRevoked = "revoked"
+)
+
+// ApiKey 调用方API key，只保存key的哈希值
+type ApiKey struct {
+	Id        int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:key id"`
+	Name      string `json:"name" gorm:"column:name;comment:调用方名称"`
+	KeyPrefix string `json:"key_prefix" gorm:"column:key_prefix;comment:key前缀，便于识别"`
+	KeyHash   string `json:"-" gorm:"column:key_hash;uniqueIndex;comment:key的sha256哈希"`
+	Status    string `json:"status" gorm:"column:status;default:active;comment:状态 active/revoked"`
+	CreatedAt int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+	RevokedAt *int64 `json:"revoked_at" gorm:"column:revoked_at;comment:吊销时间"`
+}
+
+func (k *ApiKey) TableName() string {
+	return "api_key"
+}
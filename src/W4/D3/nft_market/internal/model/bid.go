@@ -0,0 +1,24 @@
+package model
+
+// 出价状态
+const (
+	BidStatusActive  = "active"  // 有效出价
+	BidStatusInvalid = "invalid" // 结算时校验失败(余额/授权不足)或结算交易revert
+	BidStatusWon     = "won"     // 成交
+)
+
+// Bid 英式拍出价
+type Bid struct {
+	BidId      int64  `json:"bid_id" gorm:"column:bid_id;primaryKey;autoIncrement;comment:出价id"`
+	OrderId    int64  `json:"order_id" gorm:"column:order_id;index;comment:订单id"`
+	Bidder     string `json:"bidder" gorm:"column:bidder;comment:出价人地址"`
+	Amount     string `json:"amount" gorm:"column:amount;comment:出价金额"`
+	RelayerFee string `json:"relayer_fee" gorm:"column:relayer_fee;default:0;comment:出价人签名确认的中继费用，成交时与出价金额一起转出"`
+	Signature  string `json:"signature" gorm:"column:signature;comment:出价人对出价内容的personal_sign签名"`
+	Status     string `json:"status" gorm:"column:status;default:active;comment:出价状态"`
+	CreatedAt  int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:出价时间"`
+}
+
+func (b *Bid) TableName() string {
+	return "bid"
+}
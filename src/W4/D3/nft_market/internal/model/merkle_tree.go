@@ -0,0 +1,13 @@
+package model
+
+// MerkleTree 卖家白名单折扣使用的默克尔树，保存OpenZeppelin StandardMerkleTree格式的完整导出，用于生成证明
+type MerkleTree struct {
+	Root      string `json:"root" gorm:"column:root;primaryKey;comment:默克尔树根"`
+	Size      int    `json:"size" gorm:"column:size;comment:白名单地址数量"`
+	Dump      string `json:"-" gorm:"column:dump;type:text;comment:StandardMerkleTree导出的JSON"`
+	CreatedAt int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+}
+
+func (m *MerkleTree) TableName() string {
+	return "merkle_tree"
+}
@@ -1,38 +1,209 @@
 package model
 
-import "nftmarket/global"
+import (
+	"fmt"
+	"nftmarket/global"
+	"strings"
+)
+
+// 订单状态
+const (
+	OrderStatusOpen        = "open"        // 上架中
+	OrderStatusSettling    = "settling"    // 结算交易已签名落库，等待上链
+	OrderStatusFilled      = "filled"      // 已成交
+	OrderStatusExpired     = "expired"     // 已过截止时间
+	OrderStatusInvalidated = "invalidated" // NFT所有权转移或授权被撤销导致订单失效
+	OrderStatusCancelled   = "cancelled"   // 卖家签名撤单
+)
+
+// 订单类型
+const (
+	OrderTypeFixed   = "fixed"   // 一口价，空值同样视为一口价，兼容历史订单
+	OrderTypeDutch   = "dutch"   // 荷兰拍，价格从StartPrice随时间线性降至EndPrice
+	OrderTypeEnglish = "english" // 英式拍，EndTime前收集出价，结束后成交最高的有效出价
+)
+
+// NFT标准
+const (
+	TokenStandardERC721  = "erc721"  // 空值同样视为ERC721，兼容历史订单
+	TokenStandardERC1155 = "erc1155" // 按数量上架，可以分多次成交
+)
 
 // Order 订单信息
 type Order struct {
-	OrderId        int64     `json:"order_id" gorm:"column:order_id;primaryKey;autoIncrement;comment:订单id"`
-	SellOrder      SellOrder `gorm:"embedded"`
-	SellerPubKey   string    `json:"seller_pub_key" gorm:"column:seller_pub_key;comment:卖家公钥"` // 用于买家购买时验证订单信息
-	Signature      string    `json:"signature" gorm:"column:signature;comment:订单详情签名"`
-	FilledTxHash   *string   `json:"filled_tx_hash" gorm:"column:filled_tx_hash;comment:订单成交的交易哈希"`
-	BlockNumber    *int64    `json:"block_number" gorm:"column:block_number;comment:订单成交交易所在区块高度"`
-	BlockTimestamp *int64    `json:"block_timestamp" gorm:"column:block_timestamp;comment:订单成交交易的区块时间"`
+	OrderId      int64     `json:"order_id" gorm:"column:order_id;primaryKey;autoIncrement;comment:订单id"`
+	SellOrder    SellOrder `gorm:"embedded"`
+	SellerPubKey string    `json:"seller_pub_key" gorm:"column:seller_pub_key;comment:卖家公钥"` // 用于买家购买时验证订单信息
+	Signature    string    `json:"signature" gorm:"column:signature;comment:订单详情签名"`
+	// 卖家地址对SellOrder.Message()的personal_sign签名，卖家为合约钱包时通过ERC-1271校验
+	SellerSignature string  `json:"seller_signature,omitempty" gorm:"column:seller_signature;comment:卖家地址签名"`
+	FilledTxHash    *string `json:"filled_tx_hash" gorm:"column:filled_tx_hash;comment:订单成交的交易哈希"`
+	BlockNumber     *int64  `json:"block_number" gorm:"column:block_number;comment:订单成交交易所在区块高度"`
+	BlockTimestamp  *int64  `json:"block_timestamp" gorm:"column:block_timestamp;index;comment:订单成交交易的区块时间"`
+	Buyer           *string `json:"buyer" gorm:"column:buyer;comment:成交买家地址"`
+	FilledPrice     *string `json:"filled_price" gorm:"column:filled_price;comment:成交价格"`
+	Status          string  `json:"status" gorm:"column:status;index;default:open;comment:订单状态"`
+	Remaining       int64   `json:"remaining" gorm:"column:remaining;comment:剩余可购买数量"` // 未被结算锁定的数量，ERC721订单为1
+	InvalidReason   string  `json:"invalid_reason" gorm:"column:invalid_reason;comment:订单失效原因"`
+	CurrentPrice    string  `json:"current_price,omitempty" gorm:"-"` // 当前价格，列表接口中计算，ERC1155订单为单价
+	HighestBid      string  `json:"highest_bid,omitempty" gorm:"-"`   // 英式拍当前最高出价
+	// 按支付代币精度换算的价格，如"1.5 USDC"
+	PriceDisplay *PriceDisplay `json:"price_display,omitempty" gorm:"-"`
+	// 购买和结算接口返回的结算交易发件箱id，交易上链后订单变为filled，失败或被丢弃时恢复为open
+	OutboxId int64 `json:"outbox_id,omitempty" gorm:"-"`
 }
 
 // SellOrder 订单详情
 type SellOrder struct {
 	Seller   string `json:"seller" gorm:"column:seller;comment:卖家地址"`
-	Nft      string `json:"nft" gorm:"column:nft;comment:NFT合约地址"`
+	Nft      string `json:"nft" gorm:"column:nft;index;comment:NFT合约地址"`
 	TokenId  int64  `json:"token_id" gorm:"column:token_id;comment:NFT编号"`
 	PayToken string `json:"pay_token" gorm:"column:pay_token;comment:支付代币的合约地址"`
 	Price    int64  `json:"price" gorm:"column:price;comment:价格"`
 	Deadline int64  `json:"deadline" gorm:"column:deadline;comment:截止时间"`
+	// 以下为拍卖字段，一口价订单为空值，omitempty保证历史订单序列化结果(签名内容)不变
+	OrderType  string `json:"order_type,omitempty" gorm:"column:order_type;comment:订单类型"`
+	StartPrice string `json:"start_price,omitempty" gorm:"column:start_price;comment:拍卖起始价(荷兰拍)/保留价(英式拍)"`
+	EndPrice   string `json:"end_price,omitempty" gorm:"column:end_price;comment:荷兰拍最低价"`
+	StartTime  int64  `json:"start_time,omitempty" gorm:"column:start_time;comment:拍卖开始时间"`
+	EndTime    int64  `json:"end_time,omitempty" gorm:"column:end_time;comment:拍卖结束时间"`
+	// 订单所在链，纳入签名防止订单被重放到其他链；多链之前的历史订单为0，属于默认链
+	ChainId int64 `json:"chain_id,omitempty" gorm:"column:chain_id;index;comment:链id"`
+	// 白名单折扣，默克尔树中的地址携带证明购买时按DiscountPrice成交，仅一口价订单支持
+	MerkleRoot    string `json:"merkle_root,omitempty" gorm:"column:merkle_root;comment:白名单默克尔树根"`
+	DiscountPrice string `json:"discount_price,omitempty" gorm:"column:discount_price;comment:白名单折扣价"`
+	// ERC1155订单的NFT标准和上架数量，Price和DiscountPrice为单价；ERC721订单为空值，签名内容不变
+	TokenStandard string `json:"token_standard,omitempty" gorm:"column:token_standard;comment:NFT标准"`
+	Amount        int64  `json:"amount,omitempty" gorm:"column:amount;comment:ERC1155上架数量"`
+}
+
+// IsERC1155 是否为ERC1155订单
+func (o SellOrder) IsERC1155() bool {
+	return o.TokenStandard == TokenStandardERC1155
+}
+
+// Message 卖家地址需要签名的订单消息，拍卖、白名单折扣和ERC1155字段只在非空时出现
+func (o SellOrder) Message() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "nftmarket sell order\nchain_id: %d\nseller: %s\nnft: %s\ntoken_id: %d\npay_token: %s\nprice: %d\ndeadline: %d",
+		o.ChainId, o.Seller, o.Nft, o.TokenId, o.PayToken, o.Price, o.Deadline)
+	if o.OrderType != "" {
+		fmt.Fprintf(&b, "\norder_type: %s\nstart_price: %s\nend_price: %s\nstart_time: %d\nend_time: %d",
+			o.OrderType, o.StartPrice, o.EndPrice, o.StartTime, o.EndTime)
+	}
+	if o.MerkleRoot != "" {
+		fmt.Fprintf(&b, "\nmerkle_root: %s\ndiscount_price: %s", o.MerkleRoot, o.DiscountPrice)
+	}
+	if o.TokenStandard != "" {
+		fmt.Fprintf(&b, "\ntoken_standard: %s\namount: %d", o.TokenStandard, o.Amount)
+	}
+	return b.String()
+}
+
+// CancelMessage 卖家撤单需要签名的消息，chain_id为订单记录中的chain_id
+func (o *Order) CancelMessage() string {
+	return fmt.Sprintf("nftmarket cancel order\nchain_id: %d\norder_id: %d", o.SellOrder.ChainId, o.OrderId)
+}
+
+// Fill 一次成交，ERC721订单只有一次，ERC1155订单可以分多次成交
+type Fill struct {
+	Id             int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:id"`
+	OrderId        int64  `json:"order_id" gorm:"column:order_id;index;comment:订单id"`
+	OutboxId       int64  `json:"outbox_id" gorm:"column:outbox_id;comment:结算交易发件箱id，迁移前的历史成交为0"`
+	Buyer          string `json:"buyer" gorm:"column:buyer;comment:买家地址"`
+	Amount         int64  `json:"amount" gorm:"column:amount;comment:成交数量"`
+	Price          string `json:"price" gorm:"column:price;comment:成交总价"`
+	TxHash         string `json:"tx_hash" gorm:"column:tx_hash;uniqueIndex;comment:成交交易哈希"`
+	BlockNumber    int64  `json:"block_number" gorm:"column:block_number;comment:成交区块高度"`
+	BlockTimestamp int64  `json:"block_timestamp" gorm:"column:block_timestamp;index;comment:成交区块时间"`
+	// 结算交易收据中的gas消耗，迁移前的历史成交为空
+	GasUsed           *int64  `json:"gas_used" gorm:"column:gas_used;comment:结算交易消耗的gas"`
+	EffectiveGasPrice *string `json:"effective_gas_price" gorm:"column:effective_gas_price;comment:结算交易的实际gas价格(wei)"`
+	GasCost           *string `json:"gas_cost" gorm:"column:gas_cost;comment:结算钱包支付的gas费用(wei)"`
+	RelayerFee        string  `json:"relayer_fee" gorm:"column:relayer_fee;comment:买家支付给结算钱包的中继费用"`
+	CreatedAt         int64   `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+}
+
+func (f *Fill) TableName() string {
+	return "order_fill"
+}
+
+// Trade 成交记录，每次成交一条
+type Trade struct {
+	FillId         int64  `json:"fill_id"`
+	OrderId        int64  `json:"order_id"`
+	ChainId        int64  `json:"chain_id"`
+	OrderType      string `json:"order_type"`
+	TokenStandard  string `json:"token_standard"`
+	Nft            string `json:"nft"`
+	TokenId        int64  `json:"token_id"`
+	Amount         int64  `json:"amount"`
+	Seller         string `json:"seller"`
+	Buyer          string `json:"buyer"`
+	PayToken       string `json:"pay_token"`
+	Price          string `json:"price"` // 成交总价
+	TxHash         string `json:"tx_hash"`
+	BlockNumber    int64  `json:"block_number"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+}
+
+const (
+	// ExportStatusTrailer 成交导出结束后通过HTTP trailer返回的状态，complete表示已全部写出，否则为错误信息
+	ExportStatusTrailer = "X-Export-Status"
+	// ExportStatusComplete 导出完整结束
+	ExportStatusComplete = "complete"
+)
+
+// BuyPermit 买家可选的授权签名，后端离线校验后与购买在同一笔交易中提交，买家无需提前approve
+// eip2612: 对市场合约的permit签名，value为成交价格；permit2: Permit2 SignatureTransfer签名，spender为市场合约，amount为成交价格
+type BuyPermit struct {
+	Type      string `json:"type" binding:"required,oneof=eip2612 permit2"`
+	Deadline  int64  `json:"deadline" binding:"required,gt=0"`
+	Nonce     string `json:"nonce" binding:"required_if=Type permit2,omitempty,numeric"` // Permit2的无序nonce，eip2612从链上读取
+	Signature string `json:"signature" binding:"required,hexadecimal"`                   // EOA为65字节r||s||v签名，permit2的合约钱包为其isValidSignature接受的签名
+}
+
+// BuyRequest 购买请求
+type BuyRequest struct {
+	Buyer   string     `json:"buyer" binding:"required,eth_addr"`
+	OrderId int        `json:"order_id" binding:"required,gt=0"`
+	Amount  int64      `json:"amount" binding:"gte=0"`                            // ERC1155订单的购买数量，默认为1
+	Permit  *BuyPermit `json:"permit"`                                            // 可选，携带时无需提前approve，额度为成交价格加中继费用
+	Proof   []string   `json:"proof" binding:"omitempty,dive,hexadecimal,len=66"` // 可选，白名单默克尔证明，通过时按折扣价成交
+	Quote   *BuyQuote  `json:"quote"`                                             // 中继费用报价及买家签名，收取中继费用时必填
 }
 
 // SellOrderRequest SellOrder请求信息
 type SellOrderRequest struct {
-	PrivateKey string `json:"privatekey"`
-	PublicKey  string `json:"publickey"`
-	Seller     string `json:"seller"`
-	NFT        string `json:"nft"`
-	TokenID    int64  `json:"token_id"`
-	PayToken   string `json:"pay_token"`
-	Price      int64  `json:"price"`
-	Deadline   int64  `json:"deadline"`
+	PrivateKey string `json:"privatekey" binding:"required_without=Signature"`
+	PublicKey  string `json:"publickey" binding:"required"`
+	// 可选，客户端用私钥对订单在本地签名后只提交签名，私钥不发送到服务端
+	Signature  string `json:"signature" binding:"omitempty,hexadecimal"`
+	Seller     string `json:"seller" binding:"required,eth_addr"`
+	NFT        string `json:"nft" binding:"required,eth_addr"`
+	TokenID    int64  `json:"token_id" binding:"gte=0"`
+	PayToken   string `json:"pay_token" binding:"required,pay_token"`
+	Price      int64  `json:"price" binding:"gte=0"` // 一口价订单必须大于0，在auction.Validate中校验
+	Deadline   int64  `json:"deadline" binding:"required,future_deadline"`
+	OrderType  string `json:"order_type" binding:"omitempty,oneof=fixed dutch english"`
+	StartPrice string `json:"start_price" binding:"omitempty,positive_amount"`
+	EndPrice   string `json:"end_price" binding:"omitempty,positive_amount"`
+	StartTime  int64  `json:"start_time" binding:"gte=0"`
+	EndTime    int64  `json:"end_time" binding:"gte=0"`
+	ChainId    int64  `json:"chain_id" binding:"omitempty,chain_id"` // 为空时使用默认链
+	// 白名单折扣，merkle_root需先通过/market/merkle创建或导入
+	MerkleRoot    string `json:"merkle_root" binding:"required_with=DiscountPrice HumanDiscountPrice,omitempty,hexadecimal,len=66"`
+	DiscountPrice string `json:"discount_price" binding:"omitempty,positive_amount"`
+	// 可选，卖家地址对订单消息的签名，证明订单由卖家本人上架；合约钱包卖家通过ERC-1271校验
+	SellerSignature string `json:"seller_signature" binding:"omitempty,hexadecimal"`
+	// 为空时通过ERC165检测，客户端本地签名时需与签名内容一致
+	TokenStandard string `json:"token_standard" binding:"omitempty,oneof=erc721 erc1155"`
+	Amount        int64  `json:"amount" binding:"gte=0"` // ERC1155订单的上架数量
+	// 可选，按支付代币单位填写的价格，如"1.5"或"1.5 USDC"，服务端按登记的精度换算为最小单位后再签名/验签
+	HumanPrice         string `json:"human_price"`
+	HumanStartPrice    string `json:"human_start_price"`
+	HumanEndPrice      string `json:"human_end_price"`
+	HumanDiscountPrice string `json:"human_discount_price"`
 }
 
 func (o *Order) TableName() string {
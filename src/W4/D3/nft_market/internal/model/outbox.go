@@ -0,0 +1,59 @@
+package model
+
+// 结算交易发件箱状态
+const (
+	OutboxStatusPending   = "pending"   // 已签名并与订单锁定一起落库，尚未被节点接受
+	OutboxStatusSent      = "sent"      // 已广播，等待上链
+	OutboxStatusConfirmed = "confirmed" // 上链成功并达到确认数，订单已成交
+	OutboxStatusFailed    = "failed"    // 上链但执行失败，订单重新上架
+	OutboxStatusDropped   = "dropped"   // 同一nonce已被其他交易使用，订单重新上架
+)
+
+// Outbox 结算交易发件箱，签名后的原始交易与订单锁定在同一个数据库事务中写入，再进行广播
+// 进程在广播后、成交落库前退出时，启动恢复和后台任务根据链上状态完成或回滚订单
+type Outbox struct {
+	Id      int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:id"`
+	ChainId int64  `json:"chain_id" gorm:"column:chain_id;comment:链id"`
+	OrderId int64  `json:"order_id" gorm:"column:order_id;index;comment:订单id"`
+	BidId   int64  `json:"bid_id,omitempty" gorm:"column:bid_id;comment:英式拍成交的出价id"`
+	Buyer   string `json:"buyer" gorm:"column:buyer;comment:买家地址"`
+	Price   string `json:"price" gorm:"column:price;comment:成交价格"`
+	Amount  int64  `json:"amount" gorm:"column:amount;comment:成交数量，ERC721订单为1"`
+	Signer  string `json:"signer" gorm:"column:signer;comment:发送交易的结算钱包"`
+	Nonce   uint64 `json:"nonce" gorm:"column:nonce;comment:交易nonce"`
+	// 买家签名确认的中继费用及其报价，英式拍结算和不收取费用时为0
+	RelayerFee string `json:"relayer_fee" gorm:"column:relayer_fee;comment:中继费用"`
+	QuoteId    int64  `json:"quote_id,omitempty" gorm:"column:quote_id;comment:中继费用报价id"`
+	// 最近一次广播的交易，替换后为新交易，上链后为实际上链的交易
+	TxHash       string `json:"tx_hash" gorm:"column:tx_hash;uniqueIndex;comment:交易哈希"`
+	RawTx        string `json:"-" gorm:"column:raw_tx;type:text;comment:签名后的原始交易"`
+	GasFeeCap    string `json:"gas_fee_cap" gorm:"column:gas_fee_cap;comment:当前交易的GasFeeCap"`
+	GasTipCap    string `json:"gas_tip_cap" gorm:"column:gas_tip_cap;comment:当前交易的GasTipCap"`
+	Replacements int    `json:"replacements" gorm:"column:replacements;comment:提高手续费替换的次数"`
+	SentAt       int64  `json:"sent_at" gorm:"column:sent_at;comment:当前交易的签名时间，用于判断是否卡住"`
+	Status       string `json:"status" gorm:"column:status;index;default:pending;comment:状态"`
+	Attempts     int    `json:"attempts" gorm:"column:attempts;comment:广播次数"`
+	LastError    string `json:"last_error,omitempty" gorm:"column:last_error;comment:最近一次广播错误"`
+	BroadcastAt  int64  `json:"broadcast_at" gorm:"column:broadcast_at;comment:最近一次广播时间"`
+	CreatedAt    int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+	UpdatedAt    int64  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime;comment:更新时间"`
+}
+
+func (o *Outbox) TableName() string {
+	return "outbox"
+}
+
+// OutboxTx 发件箱记录签名过的每一笔交易(原始交易及同nonce的替换交易)，任意一笔上链都归属到该订单
+type OutboxTx struct {
+	Id        int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:id"`
+	OutboxId  int64  `json:"outbox_id" gorm:"column:outbox_id;index;comment:发件箱id"`
+	TxHash    string `json:"tx_hash" gorm:"column:tx_hash;uniqueIndex;comment:交易哈希"`
+	RawTx     string `json:"-" gorm:"column:raw_tx;type:text;comment:签名后的原始交易"`
+	GasFeeCap string `json:"gas_fee_cap" gorm:"column:gas_fee_cap;comment:GasFeeCap"`
+	GasTipCap string `json:"gas_tip_cap" gorm:"column:gas_tip_cap;comment:GasTipCap"`
+	CreatedAt int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:签名时间"`
+}
+
+func (o *OutboxTx) TableName() string {
+	return "outbox_tx"
+}
@@ -0,0 +1,29 @@
+package model
+
+// PayToken 允许作为支付代币的ERC20合约，symbol和decimals在登记时从合约读取；ETH_FLAG为内置代币，不在表中登记
+type PayToken struct {
+	Id        int64  `json:"id,omitempty" gorm:"column:id;primaryKey;autoIncrement;comment:记录id"`
+	ChainId   int64  `json:"chain_id" gorm:"column:chain_id;uniqueIndex:idx_pay_token_chain_address;comment:链id"`
+	Address   string `json:"address" gorm:"column:address;uniqueIndex:idx_pay_token_chain_address;comment:代币合约地址"`
+	Symbol    string `json:"symbol" gorm:"column:symbol;comment:代币符号"`
+	Decimals  uint8  `json:"decimals" gorm:"column:decimals;comment:代币精度"`
+	BuiltIn   bool   `json:"built_in,omitempty" gorm:"-"` // 内置的ETH
+	CreatedAt int64  `json:"created_at,omitempty" gorm:"column:created_at;autoCreateTime;comment:登记时间"`
+}
+
+func (p *PayToken) TableName() string {
+	return "pay_token"
+}
+
+// PriceDisplay 订单价格按支付代币精度换算后的可读形式，如"1.5 USDC"，支付代币未登记时为空
+type PriceDisplay struct {
+	Symbol        string `json:"symbol"`
+	Decimals      uint8  `json:"decimals"`
+	Price         string `json:"price,omitempty"`
+	StartPrice    string `json:"start_price,omitempty"`
+	EndPrice      string `json:"end_price,omitempty"`
+	DiscountPrice string `json:"discount_price,omitempty"`
+	CurrentPrice  string `json:"current_price,omitempty"`
+	HighestBid    string `json:"highest_bid,omitempty"`
+	FilledPrice   string `json:"filled_price,omitempty"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// PowStamp 已通过校验的工作量证明印章，Nonce为挑战的nonce，过期后可以清理
+type PowStamp struct {
+	Nonce     string `gorm:"column:nonce;primaryKey;comment:挑战nonce"`
+	Client    string `gorm:"column:client;index:idx_pow_stamp_client_used_at,priority:1;comment:调用方(API key或IP)"`
+	UsedAt    int64  `gorm:"column:used_at;index:idx_pow_stamp_client_used_at,priority:2;comment:使用时间"`
+	ExpiresAt int64  `gorm:"column:expires_at;index:idx_pow_stamp_expires_at;comment:记录保留到此时间，不早于印章过期时间并覆盖难度统计窗口"`
+}
+
+func (p *PowStamp) TableName() string {
+	return "pow_stamp"
+}
@@ -0,0 +1,51 @@
+package model
+
+import "fmt"
+
+// 中继费用模式
+const (
+	RelayerFeeNone    = "none"     // 不收取
+	RelayerFeeFlat    = "flat"     // 每笔固定费用
+	RelayerFeePercent = "percent"  // 按成交价格的万分比
+	RelayerFeeGasPlus = "gas_plus" // 按预估gas费用加收一定比例，ERC20支付的订单按价格表换算为支付代币
+)
+
+// RelayerQuote 购买前的中继费用报价，买家对Message()签名后在购买时提交，每个报价只能使用一次
+type RelayerQuote struct {
+	Id         int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:报价id"`
+	ChainId    int64  `json:"chain_id" gorm:"column:chain_id;comment:订单所在链"`
+	OrderId    int64  `json:"order_id" gorm:"column:order_id;index;comment:订单id"`
+	Buyer      string `json:"buyer" gorm:"column:buyer;comment:买家地址"`
+	Amount     int64  `json:"amount" gorm:"column:amount;comment:购买数量"`
+	Price      string `json:"price" gorm:"column:price;comment:报价时的成交总价，不含中继费用"`
+	RelayerFee string `json:"relayer_fee" gorm:"column:relayer_fee;comment:中继费用，与成交价格使用同一种代币"`
+	FeeMode    string `json:"fee_mode" gorm:"column:fee_mode;comment:计算中继费用的模式"`
+	GasCost    string `json:"gas_cost,omitempty" gorm:"column:gas_cost;comment:gas_plus模式下预估的gas费用(wei)"`
+	ExpiresAt  int64  `json:"expires_at" gorm:"column:expires_at;comment:报价过期时间"`
+	UsedAt     int64  `json:"used_at,omitempty" gorm:"column:used_at;comment:购买时使用报价的时间，0为未使用"`
+	CreatedAt  int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+}
+
+func (q *RelayerQuote) TableName() string {
+	return "relayer_quote"
+}
+
+// Message 买家需要personal_sign签名的报价消息，确认成交价格和中继费用
+func (q *RelayerQuote) Message() string {
+	return fmt.Sprintf("nftmarket buy quote\nchain_id: %d\nquote_id: %d\norder_id: %d\nbuyer: %s\namount: %d\nprice: %s\nrelayer_fee: %s\nexpires_at: %d",
+		q.ChainId, q.Id, q.OrderId, q.Buyer, q.Amount, q.Price, q.RelayerFee, q.ExpiresAt)
+}
+
+// QuoteRequest 报价请求，与购买请求的订单、买家、数量和白名单证明相同
+type QuoteRequest struct {
+	Buyer   string   `json:"buyer" binding:"required,eth_addr"`
+	OrderId int      `json:"order_id" binding:"required,gt=0"`
+	Amount  int64    `json:"amount" binding:"gte=0"`
+	Proof   []string `json:"proof" binding:"omitempty,dive,hexadecimal,len=66"`
+}
+
+// BuyQuote 购买时提交的报价及买家签名
+type BuyQuote struct {
+	Id        int64  `json:"id" binding:"required,gt=0"`
+	Signature string `json:"signature" binding:"required,hexadecimal"` // 买家对RelayerQuote.Message()的personal_sign签名
+}
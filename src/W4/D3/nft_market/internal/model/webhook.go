@@ -0,0 +1,62 @@
+package model
+
+// webhook投递状态
+const (
+	WebhookDeliveryPending   = "pending"   // 等待投递或等待重试
+	WebhookDeliveryDelivered = "delivered" // 接收方返回2xx
+)
+
+// Webhook 订单事件的webhook订阅，属于创建它的API key
+type Webhook struct {
+	Id       int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:订阅id"`
+	ApiKeyId int64  `json:"api_key_id" gorm:"column:api_key_id;index;comment:创建订阅的API key，未启用API key时为0"`
+	Url      string `json:"url" gorm:"column:url;comment:接收事件的地址"`
+	// 订阅的事件类型，为空时订阅所有事件
+	Events    []string `json:"events" gorm:"column:events;type:text;serializer:json;comment:订阅的事件类型"`
+	Nft       string   `json:"nft,omitempty" gorm:"column:nft;comment:只推送该NFT合约的订单"`
+	Seller    string   `json:"seller,omitempty" gorm:"column:seller;comment:只推送该卖家的订单"`
+	Secret    string   `json:"-" gorm:"column:secret;comment:HMAC签名密钥"`
+	CreatedAt int64    `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+}
+
+func (w *Webhook) TableName() string {
+	return "webhook"
+}
+
+// WebhookDelivery 一次事件投递，失败后按指数退避重试，重试次数用完后移入死信表
+type WebhookDelivery struct {
+	Id             int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:id"`
+	WebhookId      int64  `json:"webhook_id" gorm:"column:webhook_id;index;comment:订阅id"`
+	EventId        string `json:"event_id" gorm:"column:event_id;comment:事件id，重试和重放时不变"`
+	EventType      string `json:"event_type" gorm:"column:event_type;comment:事件类型"`
+	Payload        string `json:"payload" gorm:"column:payload;type:text;comment:投递的JSON请求体"`
+	Status         string `json:"status" gorm:"column:status;default:pending;comment:状态"`
+	Attempts       int    `json:"attempts" gorm:"column:attempts;comment:已投递次数"`
+	NextAttemptAt  int64  `json:"next_attempt_at" gorm:"column:next_attempt_at;comment:下次投递时间"`
+	LastStatusCode int    `json:"last_status_code,omitempty" gorm:"column:last_status_code;comment:最近一次投递的http状态码"`
+	LastError      string `json:"last_error,omitempty" gorm:"column:last_error;comment:最近一次投递错误"`
+	DeliveredAt    *int64 `json:"delivered_at" gorm:"column:delivered_at;comment:投递成功时间"`
+	CreatedAt      int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+}
+
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// WebhookDeadLetter 重试次数用完仍未投递成功的事件，可通过重放接口重新投递
+type WebhookDeadLetter struct {
+	Id             int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:id"`
+	WebhookId      int64  `json:"webhook_id" gorm:"column:webhook_id;index;comment:订阅id"`
+	EventId        string `json:"event_id" gorm:"column:event_id;comment:事件id"`
+	EventType      string `json:"event_type" gorm:"column:event_type;comment:事件类型"`
+	Payload        string `json:"payload" gorm:"column:payload;type:text;comment:投递的JSON请求体"`
+	Attempts       int    `json:"attempts" gorm:"column:attempts;comment:投递次数"`
+	LastStatusCode int    `json:"last_status_code,omitempty" gorm:"column:last_status_code;comment:最后一次投递的http状态码"`
+	LastError      string `json:"last_error,omitempty" gorm:"column:last_error;comment:最后一次投递错误"`
+	ReplayedAt     *int64 `json:"replayed_at" gorm:"column:replayed_at;comment:重放时间"`
+	CreatedAt      int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:进入死信表的时间"`
+}
+
+func (d *WebhookDeadLetter) TableName() string {
+	return "webhook_dead_letter"
+}
@@ -0,0 +1,26 @@
+package model
+
+// 白名单记录状态
+const (
+	WhiteListStatusActive  = "active"
+	WhiteListStatusRemoved = "removed"
+)
+
+// WhiteList 后端client白名单本地登记表
+// 合约中的whiteList mapping无法遍历，因此每次通过后端设置/取消白名单时都会在此登记，用于还原当前白名单
+type WhiteList struct {
+	Id            int64  `json:"id" gorm:"column:id;primaryKey;autoIncrement;comment:记录id"`
+	ChainId       int64  `json:"chain_id" gorm:"column:chain_id;uniqueIndex:idx_white_list_chain_client;comment:链id"`
+	Client        string `json:"client" gorm:"column:client;uniqueIndex:idx_white_list_chain_client;comment:后端client地址"`
+	Status        string `json:"status" gorm:"column:status;comment:状态 active/removed"`
+	AddTxHash     string `json:"add_tx_hash" gorm:"column:add_tx_hash;comment:设置白名单的交易哈希"`
+	RemoveTxHash  string `json:"remove_tx_hash" gorm:"column:remove_tx_hash;comment:取消白名单的交易哈希"`
+	CreatedAt     int64  `json:"created_at" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+	UpdatedAt     int64  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime;comment:更新时间"`
+	OnChainIndex  int64  `json:"on_chain_index" gorm:"-"` // 链上whiteList中的index，0表示不在白名单中
+	OnChainActive bool   `json:"on_chain_active" gorm:"-"`
+}
+
+func (w *WhiteList) TableName() string {
+	return "white_list"
+}
@@ -0,0 +1,78 @@
+package permit
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 买家授权签名类型
+const (
+	TypeEIP2612 = "eip2612"
+	TypePermit2 = "permit2"
+)
+
+// Permit2Address Uniswap Permit2在各链上的统一部署地址
+var Permit2Address = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+var (
+	eip2612TypeHash        = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+	permit2DomainTypeHash  = crypto.Keccak256Hash([]byte("EIP712Domain(string name,uint256 chainId,address verifyingContract)"))
+	permit2NameHash        = crypto.Keccak256Hash([]byte("Permit2"))
+	tokenPermissionsHash   = crypto.Keccak256Hash([]byte("TokenPermissions(address token,uint256 amount)"))
+	permitTransferFromHash = crypto.Keccak256Hash([]byte("PermitTransferFrom(TokenPermissions permitted,address spender,uint256 nonce,uint256 deadline)TokenPermissions(address token,uint256 amount)"))
+)
+
+// EIP2612Digest 计算EIP-2612 permit的EIP-712签名摘要，domainSeparator从代币合约的DOMAIN_SEPARATOR()读取
+func EIP2612Digest(domainSeparator common.Hash, owner, spender common.Address, value, nonce, deadline *big.Int) common.Hash {
+	structHash := crypto.Keccak256Hash(
+		eip2612TypeHash.Bytes(),
+		common.LeftPadBytes(owner.Bytes(), 32),
+		common.LeftPadBytes(spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(value)),
+		math.U256Bytes(new(big.Int).Set(nonce)),
+		math.U256Bytes(new(big.Int).Set(deadline)),
+	)
+	return typedDataHash(domainSeparator, structHash)
+}
+
+// Permit2Digest 计算Permit2 SignatureTransfer(PermitTransferFrom)的EIP-712签名摘要
+// spender为调用Permit2的合约，即市场合约
+func Permit2Digest(chainID *big.Int, token common.Address, amount *big.Int, spender common.Address, nonce, deadline *big.Int) common.Hash {
+	domainSeparator := crypto.Keccak256Hash(
+		permit2DomainTypeHash.Bytes(),
+		permit2NameHash.Bytes(),
+		math.U256Bytes(new(big.Int).Set(chainID)),
+		common.LeftPadBytes(Permit2Address.Bytes(), 32),
+	)
+	permitted := crypto.Keccak256Hash(
+		tokenPermissionsHash.Bytes(),
+		common.LeftPadBytes(token.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(amount)),
+	)
+	structHash := crypto.Keccak256Hash(
+		permitTransferFromHash.Bytes(),
+		permitted.Bytes(),
+		common.LeftPadBytes(spender.Bytes(), 32),
+		math.U256Bytes(new(big.Int).Set(nonce)),
+		math.U256Bytes(new(big.Int).Set(deadline)),
+	)
+	return typedDataHash(domainSeparator, structHash)
+}
+
+// Permit2NonceUsed 判断Permit2的无序nonce是否已使用，bitmap为nonceBitmap(owner, nonce >> 8)的返回值
+func Permit2NonceUsed(bitmap, nonce *big.Int) bool {
+	bit := new(big.Int).And(nonce, big.NewInt(0xff)).Uint64()
+	return bitmap.Bit(int(bit)) == 1
+}
+
+// Permit2WordPos Permit2 nonce所在的bitmap位置
+func Permit2WordPos(nonce *big.Int) *big.Int {
+	return new(big.Int).Rsh(nonce, 8)
+}
+
+func typedDataHash(domainSeparator, structHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domainSeparator.Bytes(), structHash.Bytes())
+}
@@ -0,0 +1,290 @@
+package pow
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 印章格式版本，格式为 1:{resource}:{difficulty}:{expires}:{nonce}:{mac}:{solution}
+const version = "1"
+
+// 内存存储超过此数量时清理过期条目
+const pruneThreshold = 4096
+
+// StampHeader 提交印章的请求头，gRPC使用同名小写metadata
+const StampHeader = "X-PoW-Stamp"
+
+var (
+	ErrInvalidStamp     = errors.New("invalid proof-of-work stamp")
+	ErrStampExpired     = errors.New("proof-of-work stamp expired")
+	ErrStampReplayed    = errors.New("proof-of-work stamp already used")
+	ErrInsufficientWork = errors.New("proof-of-work stamp does not meet the difficulty")
+)
+
+// Options 挑战的有效期和难度，难度为SHA-256十六进制哈希开头0的个数，为0的字段使用默认值
+type Options struct {
+	TTL            time.Duration // 挑战有效期
+	BaseDifficulty int           // 基础难度
+	MaxDifficulty  int           // 难度上限
+	Window         time.Duration // 统计调用方已使用印章数的时间窗口
+	Threshold      int           // 窗口内使用的印章超过此数量后提高难度
+	Step           int           // 超过Threshold后每多使用Step个印章难度加1
+	Store          Store         // 已使用印章的存储，为nil时保存在进程内存中
+}
+
+func (o Options) withDefaults() Options {
+	if o.TTL <= 0 {
+		o.TTL = 2 * time.Minute
+	}
+	if o.BaseDifficulty <= 0 {
+		o.BaseDifficulty = 4
+	}
+	if o.MaxDifficulty < o.BaseDifficulty {
+		o.MaxDifficulty = o.BaseDifficulty + 2
+	}
+	if o.Window <= 0 {
+		o.Window = time.Minute
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = 10
+	}
+	if o.Step <= 0 {
+		o.Step = 10
+	}
+	if o.Store == nil {
+		o.Store = NewMemoryStore()
+	}
+	return o
+}
+
+// Store 保存已通过校验的印章，用于拒绝重放和按调用方统计使用频率，多实例部署时应使用共享存储
+type Store interface {
+	// Use 记录调用方在usedAt使用了nonce，nonce已记录过时返回ErrStampReplayed，记录在keepUntil之后可以清理
+	Use(ctx context.Context, nonce, client string, usedAt, keepUntil time.Time) error
+	// Count 调用方在since之后(含)使用的印章数
+	Count(ctx context.Context, client string, since time.Time) (int, error)
+}
+
+// MemoryStore 进程内的印章存储，只适用于单实例部署
+type MemoryStore struct {
+	mu     sync.Mutex
+	used   map[string]time.Time   // nonce -> 保留到
+	stamps map[string][]usedStamp // 调用方 -> 按使用时间排序的印章
+}
+
+type usedStamp struct {
+	usedAt    time.Time
+	keepUntil time.Time
+}
+
+// NewMemoryStore 创建进程内的印章存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{used: make(map[string]time.Time), stamps: make(map[string][]usedStamp)}
+}
+
+func (s *MemoryStore) Use(_ context.Context, nonce, client string, usedAt, keepUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.used[nonce]; ok {
+		return ErrStampReplayed
+	}
+	if len(s.used) >= pruneThreshold {
+		s.prune(usedAt)
+	}
+	s.used[nonce] = keepUntil
+	s.stamps[client] = append(s.stamps[client], usedStamp{usedAt: usedAt, keepUntil: keepUntil})
+	return nil
+}
+
+func (s *MemoryStore) Count(_ context.Context, client string, since time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, stamp := range s.stamps[client] {
+		if !stamp.usedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryStore) prune(now time.Time) {
+	for nonce, keepUntil := range s.used {
+		if now.After(keepUntil) {
+			delete(s.used, nonce)
+		}
+	}
+	for client, stamps := range s.stamps {
+		kept := stamps[:0]
+		for _, stamp := range stamps {
+			if !now.After(stamp.keepUntil) {
+				kept = append(kept, stamp)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.stamps, client)
+		} else {
+			s.stamps[client] = kept
+		}
+	}
+}
+
+// Challenge 服务端签发的挑战，调用方求解solution使sha256(Stamp+solution)的十六进制以Difficulty个0开头，
+// 然后将Stamp+solution作为印章提交
+type Challenge struct {
+	Resource   string `json:"resource"`
+	Difficulty int    `json:"difficulty"`
+	ExpiresAt  int64  `json:"expires_at"`
+	Nonce      string `json:"nonce"`
+	Stamp      string `json:"stamp"` // 待求解的印章前缀，以:结尾
+}
+
+// Issuer 签发和校验挑战，MAC绑定资源和调用方，已使用的印章在过期前不能再次使用
+// 难度按调用方在窗口内已通过校验的印章数计算，只获取挑战不提交不会提高难度
+type Issuer struct {
+	secret  []byte
+	options Options
+	now     func() time.Time
+}
+
+// NewIssuer 创建签发器，secret为空时随机生成，此时重启后之前签发的挑战失效
+func NewIssuer(secret []byte, options Options) (*Issuer, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return &Issuer{
+		secret:  secret,
+		options: options.withDefaults(),
+		now:     time.Now,
+	}, nil
+}
+
+// Issue 为调用方签发resource的挑战，调用方在窗口内使用的印章越多难度越高
+func (i *Issuer) Issue(ctx context.Context, resource, client string) (*Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	now := i.now()
+	difficulty, err := i.difficulty(ctx, client, now)
+	if err != nil {
+		return nil, err
+	}
+	challenge := &Challenge{
+		Resource:   resource,
+		Difficulty: difficulty,
+		ExpiresAt:  now.Add(i.options.TTL).Unix(),
+		Nonce:      hex.EncodeToString(nonce),
+	}
+	mac := i.mac(resource, client, challenge.Difficulty, challenge.ExpiresAt, challenge.Nonce)
+	challenge.Stamp = fmt.Sprintf("%s:%s:%d:%d:%s:%s:", version, resource, challenge.Difficulty, challenge.ExpiresAt, challenge.Nonce, mac)
+	return challenge, nil
+}
+
+// Verify 校验调用方提交的resource印章：格式、MAC、有效期、工作量，通过后记入存储
+// 印章的难度低于调用方当前应有的难度时拒绝，防止在低频时预先囤积低难度挑战后集中使用
+func (i *Issuer) Verify(ctx context.Context, stamp, resource, client string) error {
+	fields := strings.Split(stamp, ":")
+	if len(fields) != 7 || fields[0] != version || fields[1] != resource {
+		return ErrInvalidStamp
+	}
+	difficulty, err1 := strconv.Atoi(fields[2])
+	expires, err2 := strconv.ParseInt(fields[3], 10, 64)
+	_, err3 := strconv.ParseUint(fields[6], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || difficulty <= 0 || difficulty > sha256.Size*2 {
+		return ErrInvalidStamp
+	}
+	nonce := fields[4]
+	expected := i.mac(resource, client, difficulty, expires, nonce)
+	if !hmac.Equal([]byte(fields[5]), []byte(expected)) {
+		return ErrInvalidStamp
+	}
+	now := i.now()
+	if now.Unix() > expires {
+		return ErrStampExpired
+	}
+	if !HasWork(stamp, difficulty) {
+		return ErrInsufficientWork
+	}
+	required, err := i.difficulty(ctx, client, now)
+	if err != nil {
+		return err
+	}
+	if difficulty < required {
+		return ErrInsufficientWork
+	}
+	// 记录至少保留到印章过期以拒绝重放，并覆盖难度统计窗口
+	keepUntil := time.Unix(expires, 0)
+	if windowEnd := now.Add(i.options.Window); windowEnd.After(keepUntil) {
+		keepUntil = windowEnd
+	}
+	return i.options.Store.Use(ctx, nonce, client, now, keepUntil)
+}
+
+// difficulty 调用方下一个印章需要的难度：窗口内已使用的印章数加上这一个超过Threshold后逐步提高
+func (i *Issuer) difficulty(ctx context.Context, client string, now time.Time) (int, error) {
+	count, err := i.options.Store.Count(ctx, client, now.Add(-i.options.Window))
+	if err != nil {
+		return 0, err
+	}
+	difficulty := i.options.BaseDifficulty
+	if over := count + 1 - i.options.Threshold; over > 0 {
+		difficulty += 1 + (over-1)/i.options.Step
+	}
+	return min(difficulty, i.options.MaxDifficulty), nil
+}
+
+func (i *Issuer) mac(resource, client string, difficulty int, expires int64, nonce string) string {
+	h := hmac.New(sha256.New, i.secret)
+	fmt.Fprintf(h, "%s:%s:%s:%d:%d:%s", version, resource, client, difficulty, expires, nonce)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HasWork 判断sha256(stamp)的十六进制是否以difficulty个0开头，比较耗时与前缀内容无关
+func HasWork(stamp string, difficulty int) bool {
+	hash := sha256.Sum256([]byte(stamp))
+	hashStr := hex.EncodeToString(hash[:])
+	if difficulty > len(hashStr) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashStr[:difficulty]), []byte(strings.Repeat("0", difficulty))) == 1
+}
+
+// Solve 求解挑战并返回完整的印章，ctx结束时放弃
+func Solve(ctx context.Context, challenge *Challenge) (string, error) {
+	_, nonce, err := FindHashWithPrefix(ctx, challenge.Stamp, strings.Repeat("0", challenge.Difficulty))
+	if err != nil {
+		return "", err
+	}
+	return challenge.Stamp + strconv.Itoa(nonce), nil
+}
+
+// FindHashWithPrefix 寻找满足指定前缀的哈希值，与W1/D1/question1/pow.go中的findHashWithPrefix相同，
+// 哈希内容为 data+nonce(十进制)，另外每尝试一批nonce检查一次ctx
+func FindHashWithPrefix(ctx context.Context, data string, targetPrefix string) (string, int, error) {
+	nonce := 0
+	for {
+		if nonce%4096 == 0 && ctx.Err() != nil {
+			return "", 0, ctx.Err()
+		}
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s%d", data, nonce)))
+		hashStr := hex.EncodeToString(hash[:])
+		if strings.HasPrefix(hashStr, targetPrefix) {
+			return hashStr, nonce, nil
+		}
+		nonce++
+	}
+}
@@ -0,0 +1,136 @@
+package pow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestIssuer(t *testing.T, store Store, current *time.Time) *Issuer {
+	t.Helper()
+	issuer, err := NewIssuer([]byte("secret"), Options{
+		TTL:            2 * time.Minute,
+		BaseDifficulty: 1,
+		MaxDifficulty:  3,
+		Window:         time.Minute,
+		Threshold:      2,
+		Step:           1,
+		Store:          store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer.now = func() time.Time { return *current }
+	return issuer
+}
+
+func issueAndSolve(t *testing.T, issuer *Issuer, client string) (*Challenge, string) {
+	t.Helper()
+	challenge, err := issuer.Issue(context.Background(), "create", client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamp, err := Solve(context.Background(), challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challenge, stamp
+}
+
+func TestDifficultyFollowsVerifiedStamps(t *testing.T) {
+	current := time.Unix(1700000000, 0)
+	issuer := newTestIssuer(t, NewMemoryStore(), &current)
+	ctx := context.Background()
+
+	// 只获取挑战不提交不提高难度
+	for i := 0; i < 20; i++ {
+		challenge, err := issuer.Issue(ctx, "create", "ip-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if challenge.Difficulty != 1 {
+			t.Fatalf("challenge %d difficulty = %d, want 1", i, challenge.Difficulty)
+		}
+	}
+
+	// 窗口内第1、2个印章为基础难度，第3个加1，之后每个再加1，最高3
+	for i, want := range []int{1, 1, 2, 3, 3} {
+		challenge, stamp := issueAndSolve(t, issuer, "ip-1")
+		if challenge.Difficulty != want {
+			t.Fatalf("stamp %d difficulty = %d, want %d", i+1, challenge.Difficulty, want)
+		}
+		if err := issuer.Verify(ctx, stamp, "create", "ip-1"); err != nil {
+			t.Fatalf("stamp %d: %v", i+1, err)
+		}
+	}
+	// 其他调用方不受影响
+	if challenge, _ := issueAndSolve(t, issuer, "ip-2"); challenge.Difficulty != 1 {
+		t.Fatalf("other client difficulty = %d, want 1", challenge.Difficulty)
+	}
+	// 窗口过后恢复基础难度
+	current = current.Add(time.Minute + time.Second)
+	if challenge, _ := issueAndSolve(t, issuer, "ip-1"); challenge.Difficulty != 1 {
+		t.Fatalf("difficulty after window = %d, want 1", challenge.Difficulty)
+	}
+}
+
+func TestVerifyRejectsStockpiledStamps(t *testing.T) {
+	current := time.Unix(1700000000, 0)
+	issuer := newTestIssuer(t, NewMemoryStore(), &current)
+	ctx := context.Background()
+
+	// 低频时预先获取并求解的低难度挑战，使用频率上升后不再被接受
+	var stamps []string
+	for i := 0; i < 3; i++ {
+		_, stamp := issueAndSolve(t, issuer, "ip-1")
+		stamps = append(stamps, stamp)
+	}
+	for _, stamp := range stamps[:2] {
+		if err := issuer.Verify(ctx, stamp, "create", "ip-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := issuer.Verify(ctx, stamps[2], "create", "ip-1"); !errors.Is(err, ErrInsufficientWork) {
+		t.Fatalf("stockpiled stamp: %v, want ErrInsufficientWork", err)
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	current := time.Unix(1700000000, 0)
+	store := NewMemoryStore()
+	issuer := newTestIssuer(t, store, &current)
+	ctx := context.Background()
+	_, stamp := issueAndSolve(t, issuer, "ip-1")
+
+	if err := issuer.Verify(ctx, stamp, "keypair", "ip-1"); !errors.Is(err, ErrInvalidStamp) {
+		t.Fatalf("other resource: %v", err)
+	}
+	if err := issuer.Verify(ctx, stamp, "create", "ip-2"); !errors.Is(err, ErrInvalidStamp) {
+		t.Fatalf("other client: %v", err)
+	}
+	// 篡改难度后MAC不匹配
+	fields := strings.Split(stamp, ":")
+	fields[2] = "3"
+	if err := issuer.Verify(ctx, strings.Join(fields, ":"), "create", "ip-1"); !errors.Is(err, ErrInvalidStamp) {
+		t.Fatalf("modified difficulty: %v", err)
+	}
+	if err := issuer.Verify(ctx, stamp, "create", "ip-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := issuer.Verify(ctx, stamp, "create", "ip-1"); !errors.Is(err, ErrStampReplayed) {
+		t.Fatalf("replay: %v", err)
+	}
+	// 共享存储的另一个实例同样拒绝重放
+	other := newTestIssuer(t, store, &current)
+	if err := other.Verify(ctx, stamp, "create", "ip-1"); !errors.Is(err, ErrStampReplayed) {
+		t.Fatalf("replay on another instance: %v", err)
+	}
+
+	_, stamp = issueAndSolve(t, issuer, "ip-1")
+	current = current.Add(2*time.Minute + time.Second)
+	if err := issuer.Verify(ctx, stamp, "create", "ip-1"); !errors.Is(err, ErrStampExpired) {
+		t.Fatalf("expired: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorBody 统一的错误返回格式
+type ErrorBody struct {
+	Code    string        `json:"code"`              // 机器可读的错误码
+	Error   string        `json:"error"`             // 错误描述
+	Details []FieldDetail `json:"details,omitempty"` // 参数校验失败的字段明细
+}
+
+// FieldDetail 单个字段的校验失败原因
+type FieldDetail struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Error 返回错误信息，错误码由http状态码决定
+func Error(c *gin.Context, status int, message string) {
+	c.JSON(status, ErrorBody{Code: codeOf(status), Error: message})
+}
+
+// Abort 中断请求并返回错误信息，用于中间件
+func Abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, ErrorBody{Code: codeOf(status), Error: message})
+}
+
+// Invalid 返回参数校验失败信息
+func Invalid(c *gin.Context, message string, details ...FieldDetail) {
+	c.JSON(http.StatusBadRequest, ErrorBody{Code: "invalid_request", Error: message, Details: details})
+}
+
+func codeOf(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusUnprocessableEntity:
+		return "execution_reverted"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	default:
+		return "internal_error"
+	}
+}
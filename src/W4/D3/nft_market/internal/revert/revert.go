@@ -0,0 +1,113 @@
+package revert
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"nftmarket/contract"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Error 交易模拟执行时合约revert，Reason为解码后的原因
+type Error struct {
+	Reason string
+	Data   []byte
+}
+
+func (e *Error) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+var (
+	customErrorsOnce sync.Once
+	customErrors     map[[4]byte]abi.Error
+)
+
+// loadCustomErrors 汇总NFTMarket、ERC20、ERC721、ERC1155、Permit2 ABI中的自定义错误，按selector索引
+func loadCustomErrors() {
+	customErrors = make(map[[4]byte]abi.Error)
+	for _, definition := range []string{contract.NFTMarketMetaData.ABI, contract.ERC20ErrorsABI, contract.ERC721ErrorsABI, contract.ERC1155ErrorsABI, contract.Permit2ABI} {
+		parsed, err := abi.JSON(strings.NewReader(definition))
+		if err != nil {
+			panic(fmt.Sprintf("invalid error abi: %v", err))
+		}
+		for _, abiErr := range parsed.Errors {
+			var selector [4]byte
+			copy(selector[:], abiErr.ID[:4])
+			customErrors[selector] = abiErr
+		}
+	}
+}
+
+// Decode 解码revert数据，支持Error(string)、Panic(uint256)以及已知合约的自定义错误
+func Decode(data []byte) string {
+	if len(data) == 0 {
+		return "reverted without reason"
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) >= 4 {
+		customErrorsOnce.Do(loadCustomErrors)
+		var selector [4]byte
+		copy(selector[:], data[:4])
+		if abiErr, ok := customErrors[selector]; ok {
+			if reason, err := formatCustomError(abiErr, data); err == nil {
+				return reason
+			}
+		}
+	}
+	return "unknown revert data 0x" + hex.EncodeToString(data)
+}
+
+// formatCustomError 将自定义错误格式化为 Name(arg=value, ...)
+func formatCustomError(abiErr abi.Error, data []byte) (string, error) {
+	values, err := abiErr.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", err
+	}
+	args := make([]string, len(values))
+	for i, value := range values {
+		if address, ok := value.(common.Address); ok {
+			value = address.Hex()
+		}
+		args[i] = fmt.Sprintf("%s=%v", abiErr.Inputs[i].Name, value)
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(args, ", ")), nil
+}
+
+// FromCallError 从eth_call返回的错误中提取revert数据，非revert错误返回nil
+func FromCallError(err error) *Error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+	var data []byte
+	switch errData := dataErr.ErrorData().(type) {
+	case string:
+		decoded, decodeErr := hex.DecodeString(strings.TrimPrefix(errData, "0x"))
+		if decodeErr != nil {
+			return nil
+		}
+		data = decoded
+	case nil:
+		// 部分节点revert时不返回data，只在错误信息中带有原因
+		message := dataErr.Error()
+		if !strings.HasPrefix(message, "execution reverted") {
+			return nil
+		}
+		reason := strings.TrimPrefix(strings.TrimPrefix(message, "execution reverted"), ": ")
+		if reason == "" {
+			reason = Decode(nil)
+		}
+		return &Error{Reason: reason}
+	default:
+		return nil
+	}
+	return &Error{Reason: Decode(data), Data: data}
+}
@@ -0,0 +1,134 @@
+package revert
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// encode 按签名sig和参数类型types进行ABI编码，返回 selector || 参数
+func encode(t *testing.T, sig string, types []string, values ...interface{}) []byte {
+	t.Helper()
+	var args abi.Arguments
+	for _, name := range types {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	packed, err := args.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(crypto.Keccak256([]byte(sig))[:4], packed...)
+}
+
+func TestDecode(t *testing.T) {
+	token := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	buyer := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	market := common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "reverted without reason"},
+		{"require message", encode(t, "Error(string)", []string{"string"}, "MKT: not whiteList client"), "MKT: not whiteList client"},
+		{"panic overflow", encode(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x11)), "arithmetic underflow or overflow"},
+		{"market SafeERC20FailedOperation", encode(t, "SafeERC20FailedOperation(address)", []string{"address"}, token),
+			"SafeERC20FailedOperation(token=" + token.Hex() + ")"},
+		{"erc20 insufficient allowance", encode(t, "ERC20InsufficientAllowance(address,uint256,uint256)", []string{"address", "uint256", "uint256"}, market, big.NewInt(5), big.NewInt(1000)),
+			"ERC20InsufficientAllowance(spender=" + market.Hex() + ", allowance=5, needed=1000)"},
+		{"erc721 incorrect owner", encode(t, "ERC721IncorrectOwner(address,uint256,address)", []string{"address", "uint256", "address"}, buyer, big.NewInt(7), token),
+			"ERC721IncorrectOwner(sender=" + buyer.Hex() + ", tokenId=7, owner=" + token.Hex() + ")"},
+		{"erc1155 missing approval", encode(t, "ERC1155MissingApprovalForAll(address,address)", []string{"address", "address"}, market, buyer),
+			"ERC1155MissingApprovalForAll(operator=" + market.Hex() + ", owner=" + buyer.Hex() + ")"},
+		{"permit2 expired", encode(t, "SignatureExpired(uint256)", []string{"uint256"}, big.NewInt(1700000000)), "SignatureExpired(signatureDeadline=1700000000)"},
+		{"permit2 without args", encode(t, "InvalidNonce()", nil), "InvalidNonce()"},
+		{"unknown selector", encode(t, "Unauthorized(address)", []string{"address"}, buyer), "unknown revert data 0x8e4a23d6"},
+		{"known selector with truncated args", encode(t, "SafeERC20FailedOperation(address)", []string{"address"}, token)[:20], "unknown revert data 0x5274afe7"},
+		{"shorter than selector", []byte{0x01, 0x02}, "unknown revert data 0x0102"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decode(tt.data); !strings.HasPrefix(got, tt.want) {
+				t.Fatalf("Decode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDecodeKnownErrors 所有已登记的自定义错误都能按名称解码
+func TestDecodeKnownErrors(t *testing.T) {
+	customErrorsOnce.Do(loadCustomErrors)
+	if len(customErrors) == 0 {
+		t.Fatal("no custom errors loaded")
+	}
+	for _, abiErr := range customErrors {
+		values := make([]interface{}, len(abiErr.Inputs))
+		for i, input := range abiErr.Inputs {
+			switch input.Type.T {
+			case abi.AddressTy:
+				values[i] = common.Address{}
+			case abi.UintTy:
+				values[i] = big.NewInt(1)
+			default:
+				t.Fatalf("%s: unexpected input type %s", abiErr.Sig, input.Type)
+			}
+		}
+		packed, err := abiErr.Inputs.Pack(values...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := append(abiErr.ID[:4:4], packed...)
+		if got := Decode(data); !strings.HasPrefix(got, abiErr.Name+"(") {
+			t.Errorf("Decode(%s) = %q", abiErr.Sig, got)
+		}
+	}
+}
+
+// dataError 模拟节点返回的rpc.DataError
+type dataError struct {
+	message string
+	data    interface{}
+}
+
+func (e *dataError) Error() string          { return e.message }
+func (e *dataError) ErrorData() interface{} { return e.data }
+
+func TestFromCallError(t *testing.T) {
+	reverted := encode(t, "Error(string)", []string{"string"}, "MKT: transfer failed")
+	tests := []struct {
+		name string
+		err  error
+		want *Error
+	}{
+		{"hex data", &dataError{"execution reverted", hexutil.Encode(reverted)}, &Error{Reason: "MKT: transfer failed", Data: reverted}},
+		{"reason in message", &dataError{"execution reverted: MKT: repeat set", nil}, &Error{Reason: "MKT: repeat set"}},
+		{"no reason", &dataError{"execution reverted", nil}, &Error{Reason: "reverted without reason"}},
+		{"malformed hex", &dataError{"execution reverted", "0xzz"}, nil},
+		{"not a revert", &dataError{"insufficient funds for gas", nil}, nil},
+		{"not a data error", errors.New("connection refused"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromCallError(tt.err)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("FromCallError = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Reason != tt.want.Reason || string(got.Data) != string(tt.want.Data) {
+				t.Fatalf("FromCallError = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
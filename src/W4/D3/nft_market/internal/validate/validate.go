@@ -0,0 +1,158 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"nftmarket/global"
+	"nftmarket/internal/response"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ETHFlag 合约中表示ETH支付的地址
+var ETHFlag = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// 未配置时的默认截止时间最大范围
+const defaultMaxDeadlineDays = 365
+
+// 自定义校验规则的失败原因
+var reasons = map[string]string{
+	"required":        "is required",
+	"eth_addr":        "must be an EIP-55 checksummed address",
+	"positive_amount": "must be a positive integer",
+	"future_deadline": "must be in the future and within the maximum horizon",
+	"pay_token":       "must be an EIP-55 checksummed address",
+	"chain_id":        "is not a configured chain",
+	"gt":              "must be greater than %s",
+	"gte":             "must be greater than or equal to %s",
+	"oneof":           "must be one of [%s]",
+	"hexadecimal":     "must be a hex string",
+	"required_if":     "is required when %s",
+	"required_with":   "is required together with %s",
+	"len":             "must have length %s",
+	"numeric":         "must be a number",
+}
+
+// Register 向gin的校验器注册自定义规则，并使用json字段名作为错误字段名
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected gin validator engine")
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+	rules := map[string]validator.Func{
+		"eth_addr":        isChecksumAddress,
+		"positive_amount": isPositiveAmount,
+		"future_deadline": isFutureDeadline,
+		"pay_token":       isChecksumAddress, // 是否已登记在CreateSellOrder中按订单所在链校验
+		"chain_id":        isConfiguredChain,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BindJSON 绑定并校验请求体，失败时返回统一格式的错误信息
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	return handleBindError(c, c.ShouldBindJSON(obj))
+}
+
+func handleBindError(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	details := Details(err)
+	if details == nil {
+		response.Invalid(c, "Invalid request: "+err.Error())
+		return false
+	}
+	response.Invalid(c, "Request validation failed", details...)
+	return false
+}
+
+// Struct 按binding标签校验结构体，供gRPC等不经过gin绑定的入口使用
+func Struct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// Details 将校验错误转换为字段明细，不是校验错误时返回nil
+func Details(err error) []response.FieldDetail {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	details := make([]response.FieldDetail, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		details = append(details, response.FieldDetail{Field: fieldErr.Field(), Reason: reasonOf(fieldErr)})
+	}
+	return details
+}
+
+// BindQuery 绑定并校验查询参数，失败时返回统一格式的错误信息
+func BindQuery(c *gin.Context, obj interface{}) bool {
+	return handleBindError(c, c.ShouldBindQuery(obj))
+}
+
+func reasonOf(fieldErr validator.FieldError) string {
+	reason, ok := reasons[fieldErr.Tag()]
+	if !ok {
+		return fmt.Sprintf("failed on the '%s' rule", fieldErr.Tag())
+	}
+	if strings.Contains(reason, "%s") {
+		return fmt.Sprintf(reason, fieldErr.Param())
+	}
+	return reason
+}
+
+// IsChecksumAddress 校验地址为EIP-55校验和格式，避免非法地址被HexToAddress静默转为零地址
+func IsChecksumAddress(address string) bool {
+	return common.IsHexAddress(address) && common.HexToAddress(address).Hex() == address
+}
+
+// MaxDeadline 当前允许的最大截止时间
+func MaxDeadline(now time.Time) time.Time {
+	days := defaultMaxDeadlineDays
+	if global.MarketConfig != nil && global.MarketConfig.MaxDeadlineDays > 0 {
+		days = global.MarketConfig.MaxDeadlineDays
+	}
+	return now.AddDate(0, 0, days)
+}
+
+func isChecksumAddress(fl validator.FieldLevel) bool {
+	return IsChecksumAddress(fl.Field().String())
+}
+
+func isConfiguredChain(fl validator.FieldLevel) bool {
+	if global.Chains == nil {
+		return false
+	}
+	_, err := global.Chains.Get(fl.Field().Int())
+	return err == nil
+}
+
+func isPositiveAmount(fl validator.FieldLevel) bool {
+	amount, ok := new(big.Int).SetString(fl.Field().String(), 10)
+	return ok && amount.Sign() > 0
+}
+
+func isFutureDeadline(fl validator.FieldLevel) bool {
+	now := time.Now()
+	deadline := fl.Field().Int()
+	return deadline > now.Unix() && deadline <= MaxDeadline(now).Unix()
+}
@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"nftmarket/chain"
+	"nftmarket/config/setting"
+	"nftmarket/global"
+	"nftmarket/internal/response"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var registerOnce sync.Once
+
+func register(t *testing.T) {
+	t.Helper()
+	registerOnce.Do(func() {
+		if err := Register(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// request 覆盖各自定义规则的请求结构体
+type request struct {
+	Bidder   string `json:"bidder" binding:"required,eth_addr"`
+	PayToken string `json:"pay_token" binding:"omitempty,pay_token"`
+	Amount   string `json:"amount" binding:"omitempty,positive_amount"`
+	Deadline int64  `json:"deadline" binding:"omitempty,future_deadline"`
+	ChainId  int64  `json:"chain_id" binding:"omitempty,chain_id"`
+}
+
+const bidder = "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"
+
+func TestRules(t *testing.T) {
+	register(t)
+	registry, err := chain.NewRegistry([]*chain.Chain{{ID: 31337}, {ID: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousChains, previousMarket := global.Chains, global.MarketConfig
+	global.Chains, global.MarketConfig = registry, nil
+	t.Cleanup(func() { global.Chains, global.MarketConfig = previousChains, previousMarket })
+
+	now := time.Now().Unix()
+	day := int64(24 * 60 * 60)
+	tests := []struct {
+		name    string
+		req     request
+		wantErr *response.FieldDetail
+	}{
+		{"valid", request{Bidder: bidder, PayToken: ETHFlag.Hex(), Amount: "1000", Deadline: now + 3600, ChainId: 10}, nil},
+		{"missing bidder", request{}, &response.FieldDetail{Field: "bidder", Reason: "is required"}},
+		{"lowercase address", request{Bidder: "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc"}, &response.FieldDetail{Field: "bidder", Reason: "must be an EIP-55 checksummed address"}},
+		{"wrong checksum", request{Bidder: "0x3c44CdDdB6a900fa2b585dd299e03d12FA4293BC"}, &response.FieldDetail{Field: "bidder", Reason: "must be an EIP-55 checksummed address"}},
+		{"short address", request{Bidder: "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293"}, &response.FieldDetail{Field: "bidder", Reason: "must be an EIP-55 checksummed address"}},
+		{"address without 0x", request{Bidder: bidder[2:]}, &response.FieldDetail{Field: "bidder", Reason: "must be an EIP-55 checksummed address"}},
+		{"eth flag pay token", request{Bidder: bidder, PayToken: "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"}, nil},
+		{"lowercase pay token", request{Bidder: bidder, PayToken: "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"}, &response.FieldDetail{Field: "pay_token", Reason: "must be an EIP-55 checksummed address"}},
+		{"large amount", request{Bidder: bidder, Amount: "115792089237316195423570985008687907853269984665640564039457584007913129639935"}, nil},
+		{"zero amount", request{Bidder: bidder, Amount: "0"}, &response.FieldDetail{Field: "amount", Reason: "must be a positive integer"}},
+		{"negative amount", request{Bidder: bidder, Amount: "-1"}, &response.FieldDetail{Field: "amount", Reason: "must be a positive integer"}},
+		{"decimal amount", request{Bidder: bidder, Amount: "1.5"}, &response.FieldDetail{Field: "amount", Reason: "must be a positive integer"}},
+		{"hex amount", request{Bidder: bidder, Amount: "0x10"}, &response.FieldDetail{Field: "amount", Reason: "must be a positive integer"}},
+		{"past deadline", request{Bidder: bidder, Deadline: now - 1}, &response.FieldDetail{Field: "deadline", Reason: "must be in the future and within the maximum horizon"}},
+		{"deadline at horizon", request{Bidder: bidder, Deadline: now + 364*day}, nil},
+		{"deadline beyond horizon", request{Bidder: bidder, Deadline: now + 366*day}, &response.FieldDetail{Field: "deadline", Reason: "must be in the future and within the maximum horizon"}},
+		{"default chain", request{Bidder: bidder, ChainId: 31337}, nil},
+		{"unknown chain", request{Bidder: bidder, ChainId: 5}, &response.FieldDetail{Field: "chain_id", Reason: "is not a configured chain"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkDetails(t, Struct(&tt.req), tt.wantErr)
+		})
+	}
+}
+
+func TestDeadlineHorizonConfig(t *testing.T) {
+	register(t)
+	previous := global.MarketConfig
+	global.MarketConfig = &setting.MarketConfig{MaxDeadlineDays: 30}
+	t.Cleanup(func() { global.MarketConfig = previous })
+
+	day := int64(24 * 60 * 60)
+	for _, tt := range []struct {
+		days  int64
+		valid bool
+	}{{29, true}, {31, false}} {
+		t.Run(strconv.FormatInt(tt.days, 10), func(t *testing.T) {
+			err := Struct(&request{Bidder: bidder, Deadline: time.Now().Unix() + tt.days*day})
+			if (err == nil) != tt.valid {
+				t.Fatalf("deadline in %d days: %v", tt.days, err)
+			}
+		})
+	}
+}
+
+func TestChainIdWithoutRegistry(t *testing.T) {
+	register(t)
+	previous := global.Chains
+	global.Chains = nil
+	t.Cleanup(func() { global.Chains = previous })
+	checkDetails(t, Struct(&request{Bidder: bidder, ChainId: 1}), &response.FieldDetail{Field: "chain_id", Reason: "is not a configured chain"})
+}
+
+// checkDetails 校验错误只包含want一个字段，want为nil时要求通过
+func checkDetails(t *testing.T, err error, want *response.FieldDetail) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	details := Details(err)
+	if len(details) != 1 || details[0] != *want {
+		t.Fatalf("details = %+v (err %v), want %+v", details, err, *want)
+	}
+}
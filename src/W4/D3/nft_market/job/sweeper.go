@@ -0,0 +1,246 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"nftmarket/chain"
+	"nftmarket/contract"
+	"nftmarket/internal/ethrpc"
+	"nftmarket/internal/events"
+	"nftmarket/internal/model"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 订单失效原因
+const (
+	ReasonTokenNotExist   = "nft token does not exist"
+	ReasonOwnerChanged    = "seller no longer owns the nft"
+	ReasonApprovalRevoked = "market approval revoked"
+	// ERC1155订单卖家持有的数量少于剩余可购买数量
+	ReasonInsufficientBalance = "seller balance is lower than the remaining amount"
+)
+
+// Sweeper 定时将过期订单标记为expired，并批量校验上架订单的NFT所有权与授权
+type Sweeper struct {
+	db        *gorm.DB
+	chain     *chain.Chain
+	rpc       *ethrpc.Client
+	market    common.Address
+	interval  time.Duration
+	batchSize int
+	erc721    abi.ABI
+	erc1155   abi.ABI
+}
+
+// NewSweeper 创建单条链的订单清理任务
+func NewSweeper(db *gorm.DB, c *chain.Chain, interval time.Duration, batchSize int) (*Sweeper, error) {
+	erc721, err := abi.JSON(strings.NewReader(contract.ERC721ABI))
+	if err != nil {
+		return nil, err
+	}
+	erc1155, err := abi.JSON(strings.NewReader(contract.ERC1155ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Sweeper{
+		db:        db,
+		chain:     c,
+		rpc:       c.Client,
+		market:    c.MarketAddress,
+		interval:  interval,
+		batchSize: batchSize,
+		erc721:    erc721,
+		erc1155:   erc1155,
+	}, nil
+}
+
+// Run 按间隔循环执行清理，直到ctx结束
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sweep(ctx); err != nil {
+			log.Printf("sweeper error on chain %d: %v", s.chain.ID, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep 执行一轮清理
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	expired, err := s.expireOrders(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to expire orders: %w", err)
+	}
+	if expired > 0 {
+		log.Printf("sweeper: %d orders expired on chain %d", expired, s.chain.ID)
+	}
+
+	var lastId int64
+	for {
+		var orders []model.Order
+		err := s.db.Scopes(s.chain.Scope).Where("status = ? AND filled_tx_hash IS NULL AND order_id > ?", model.OrderStatusOpen, lastId).
+			Order("order_id").Limit(s.batchSize).Find(&orders).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch open orders: %w", err)
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		if err := s.checkOrders(ctx, orders); err != nil {
+			return err
+		}
+		lastId = orders[len(orders)-1].OrderId
+	}
+}
+
+// expireOrders 将截止时间已过的上架订单标记为expired
+func (s *Sweeper) expireOrders(now time.Time) (int64, error) {
+	var expired []model.Order
+	result := s.db.Model(&expired).Clauses(clause.Returning{Columns: []clause.Column{{Name: "order_id"}}}).Scopes(s.chain.Scope).
+		Where("status = ? AND filled_tx_hash IS NULL AND deadline < ?", model.OrderStatusOpen, now.Unix()).
+		Update("status", model.OrderStatusExpired)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	orderIds := make([]int64, len(expired))
+	for i, order := range expired {
+		orderIds[i] = order.OrderId
+	}
+	events.PublishOrders(s.db, events.OrderExpired, orderIds...)
+	return result.RowsAffected, nil
+}
+
+// orderCalls 单个订单需要的eth_call结果，ERC721订单为三个，ERC1155订单为balance和approvedAll两个
+type orderCalls struct {
+	owner       hexutil.Bytes
+	approved    hexutil.Bytes
+	approvedAll hexutil.Bytes
+	balance     hexutil.Bytes
+}
+
+// checkOrders 通过一次JSON-RPC批量请求校验一批订单的所有权(ERC1155为持有数量)与授权
+func (s *Sweeper) checkOrders(ctx context.Context, orders []model.Order) error {
+	results := make([]orderCalls, len(orders))
+	offsets := make([]int, len(orders))
+	batch := make([]rpc.BatchElem, 0, len(orders)*3)
+	for i, order := range orders {
+		offsets[i] = len(batch)
+		nft, seller := common.HexToAddress(order.SellOrder.Nft), common.HexToAddress(order.SellOrder.Seller)
+		tokenId := big.NewInt(order.SellOrder.TokenId)
+		if order.SellOrder.IsERC1155() {
+			balanceOf, _ := s.erc1155.Pack("balanceOf", seller, tokenId)
+			isApprovedForAll, _ := s.erc1155.Pack("isApprovedForAll", seller, s.market)
+			batch = append(batch,
+				callElem(nft, balanceOf, &results[i].balance),
+				callElem(nft, isApprovedForAll, &results[i].approvedAll),
+			)
+			continue
+		}
+		ownerOf, _ := s.erc721.Pack("ownerOf", tokenId)
+		getApproved, _ := s.erc721.Pack("getApproved", tokenId)
+		isApprovedForAll, _ := s.erc721.Pack("isApprovedForAll", seller, s.market)
+		batch = append(batch,
+			callElem(nft, ownerOf, &results[i].owner),
+			callElem(nft, getApproved, &results[i].approved),
+			callElem(nft, isApprovedForAll, &results[i].approvedAll),
+		)
+	}
+	if err := s.rpc.BatchCallContext(ctx, batch); err != nil {
+		return fmt.Errorf("batch call failed: %w", err)
+	}
+
+	for i, order := range orders {
+		var reason string
+		if order.SellOrder.IsERC1155() {
+			reason = s.erc1155Reason(order, batch[offsets[i]:offsets[i]+2], results[i])
+		} else {
+			reason = s.erc721Reason(order, batch[offsets[i]:offsets[i]+3], results[i])
+		}
+		if reason == "" {
+			continue
+		}
+		if err := s.invalidate(order.OrderId, reason); err != nil {
+			return err
+		}
+		log.Printf("sweeper: order %d invalidated: %s", order.OrderId, reason)
+	}
+	return nil
+}
+
+// erc721Reason 根据ownerOf、getApproved、isApprovedForAll的结果判断订单失效原因，有效或无法判断时返回空字符串
+func (s *Sweeper) erc721Reason(order model.Order, batch []rpc.BatchElem, result orderCalls) string {
+	ownerErr, approvedErr, approvedAllErr := batch[0].Error, batch[1].Error, batch[2].Error
+	switch {
+	case ownerErr != nil:
+		// ownerOf回滚说明token不存在(已销毁)，其他错误(网络等)本轮跳过
+		if !isRevert(ownerErr) {
+			return ""
+		}
+		return ReasonTokenNotExist
+	case common.BytesToAddress(result.owner) != common.HexToAddress(order.SellOrder.Seller):
+		return ReasonOwnerChanged
+	case approvedErr != nil || approvedAllErr != nil:
+		return ""
+	case common.BytesToAddress(result.approved) != s.market && new(big.Int).SetBytes(result.approvedAll).Sign() == 0:
+		return ReasonApprovalRevoked
+	}
+	return ""
+}
+
+// erc1155Reason 根据balanceOf、isApprovedForAll的结果判断订单失效原因，持有数量需不少于剩余可购买数量
+func (s *Sweeper) erc1155Reason(order model.Order, batch []rpc.BatchElem, result orderCalls) string {
+	switch {
+	case batch[0].Error != nil || batch[1].Error != nil:
+		return ""
+	case new(big.Int).SetBytes(result.balance).Cmp(big.NewInt(order.Remaining)) < 0:
+		return ReasonInsufficientBalance
+	case new(big.Int).SetBytes(result.approvedAll).Sign() == 0:
+		return ReasonApprovalRevoked
+	}
+	return ""
+}
+
+// invalidate 标记订单失效，只更新仍处于上架状态的订单，避免覆盖并发成交的结果
+func (s *Sweeper) invalidate(orderId int64, reason string) error {
+	result := s.db.Model(&model.Order{}).
+		Where("order_id = ? AND status = ? AND filled_tx_hash IS NULL", orderId, model.OrderStatusOpen).
+		Updates(map[string]interface{}{"status": model.OrderStatusInvalidated, "invalid_reason": reason})
+	if result.Error == nil && result.RowsAffected > 0 {
+		events.PublishOrders(s.db, events.OrderInvalidated, orderId)
+	}
+	return result.Error
+}
+
+func callElem(to common.Address, data []byte, result *hexutil.Bytes) rpc.BatchElem {
+	return rpc.BatchElem{
+		Method: "eth_call",
+		Args: []interface{}{
+			map[string]interface{}{"to": to, "data": hexutil.Bytes(data)},
+			"latest",
+		},
+		Result: result,
+	}
+}
+
+// isRevert 判断eth_call错误是否为合约回滚(而非网络错误)
+func isRevert(err error) bool {
+	if _, ok := err.(rpc.DataError); ok {
+		return true
+	}
+	return strings.Contains(err.Error(), "revert")
+}
@@ -1,23 +1,65 @@
 package main
 
 import (
+	"context"
 	"log"
+	"nftmarket/cmd"
 	"nftmarket/config"
 	"nftmarket/db"
+	"nftmarket/global"
+	"nftmarket/grpcserver"
 	routers "nftmarket/routes"
+	"nftmarket/service"
+	"os"
 )
 
 func init() {
 	config.SetupConfig()
+	config.SetupValidator()
 	config.SetupDBEngine()
-	err := db.MigrateDb()
-	if err != nil {
-		log.Panic("config.MigrateDb error : ", err)
-	}
-	config.SetupEthClient()
-	config.SetupNFTMarketContract()
 }
 
 func main() {
+	// migrate子命令只需要数据库，在校验schema版本和连接节点之前执行
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := cmd.Execute(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if global.DbConfig.AutoMigrate {
+		if err := db.MigrateUp(global.DBEngine); err != nil {
+			log.Panic("db.MigrateUp error : ", err)
+		}
+	}
+	// 数据库schema比代码新或有未执行的迁移时拒绝启动
+	if err := db.CheckSchemaVersion(global.DBEngine); err != nil {
+		log.Panic("db.CheckSchemaVersion error : ", err)
+	}
+	config.SetupChains()
+	config.SetupSignerPool()
+	config.SetupRpcHealthCheck()
+
+	// 带参数时作为命令行工具运行，例如: go run . whitelist list
+	if len(os.Args) > 1 {
+		if err := cmd.Execute(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// 处理上次退出前未完成的结算交易，避免订单停留在settling或重复结算
+	if err := service.RecoverOutbox(context.Background()); err != nil {
+		log.Panic("service.RecoverOutbox error : ", err)
+	}
+	config.SetupPayTokens()
+	config.SetupSweeper()
+	service.SetRelayerFeePolicy(config.RelayerFeePolicy())
+	service.SetFiatPrices(config.FiatPrices())
+	service.SetProofOfWork(config.ProofOfWork())
+	go service.RunAuctionSettler(context.Background(), config.SweeperInterval())
+	go service.RunOutboxDispatcher(context.Background(), config.SweeperInterval())
+	go service.RunWebhookDispatcher(context.Background(), config.WebhookOptions())
+	// gRPC与REST共用service层，分别监听不同端口
+	go grpcserver.Run(config.GrpcAddr())
 	routers.InitRouter()
 }
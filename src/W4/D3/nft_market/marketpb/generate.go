@@ -0,0 +1,4 @@
+// Package marketpb nft_market gRPC接口的protobuf定义及生成代码，修改market.proto后执行go generate ./marketpb重新生成
+package marketpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative market.proto
@@ -0,0 +1,1845 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: market.proto
+
+// nft_market的gRPC接口，与REST接口共用同一套service层，字段统一使用snake_case
+
+package marketpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SellOrder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seller        string                 `protobuf:"bytes,1,opt,name=seller,proto3" json:"seller,omitempty"`
+	Nft           string                 `protobuf:"bytes,2,opt,name=nft,proto3" json:"nft,omitempty"`
+	TokenId       int64                  `protobuf:"varint,3,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	PayToken      string                 `protobuf:"bytes,4,opt,name=pay_token,json=payToken,proto3" json:"pay_token,omitempty"`
+	Price         int64                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`
+	Deadline      int64                  `protobuf:"varint,6,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	OrderType     string                 `protobuf:"bytes,7,opt,name=order_type,json=orderType,proto3" json:"order_type,omitempty"`
+	StartPrice    string                 `protobuf:"bytes,8,opt,name=start_price,json=startPrice,proto3" json:"start_price,omitempty"`
+	EndPrice      string                 `protobuf:"bytes,9,opt,name=end_price,json=endPrice,proto3" json:"end_price,omitempty"`
+	StartTime     int64                  `protobuf:"varint,10,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       int64                  `protobuf:"varint,11,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	ChainId       int64                  `protobuf:"varint,12,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	MerkleRoot    string                 `protobuf:"bytes,13,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	DiscountPrice string                 `protobuf:"bytes,14,opt,name=discount_price,json=discountPrice,proto3" json:"discount_price,omitempty"`
+	// ERC1155订单的NFT标准和上架数量，ERC721订单为空值
+	TokenStandard string `protobuf:"bytes,15,opt,name=token_standard,json=tokenStandard,proto3" json:"token_standard,omitempty"`
+	Amount        int64  `protobuf:"varint,16,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SellOrder) Reset() {
+	*x = SellOrder{}
+	mi := &file_market_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SellOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SellOrder) ProtoMessage() {}
+
+func (x *SellOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SellOrder.ProtoReflect.Descriptor instead.
+func (*SellOrder) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SellOrder) GetSeller() string {
+	if x != nil {
+		return x.Seller
+	}
+	return ""
+}
+
+func (x *SellOrder) GetNft() string {
+	if x != nil {
+		return x.Nft
+	}
+	return ""
+}
+
+func (x *SellOrder) GetTokenId() int64 {
+	if x != nil {
+		return x.TokenId
+	}
+	return 0
+}
+
+func (x *SellOrder) GetPayToken() string {
+	if x != nil {
+		return x.PayToken
+	}
+	return ""
+}
+
+func (x *SellOrder) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SellOrder) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *SellOrder) GetOrderType() string {
+	if x != nil {
+		return x.OrderType
+	}
+	return ""
+}
+
+func (x *SellOrder) GetStartPrice() string {
+	if x != nil {
+		return x.StartPrice
+	}
+	return ""
+}
+
+func (x *SellOrder) GetEndPrice() string {
+	if x != nil {
+		return x.EndPrice
+	}
+	return ""
+}
+
+func (x *SellOrder) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *SellOrder) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *SellOrder) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *SellOrder) GetMerkleRoot() string {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return ""
+}
+
+func (x *SellOrder) GetDiscountPrice() string {
+	if x != nil {
+		return x.DiscountPrice
+	}
+	return ""
+}
+
+func (x *SellOrder) GetTokenStandard() string {
+	if x != nil {
+		return x.TokenStandard
+	}
+	return ""
+}
+
+func (x *SellOrder) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type Order struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	OrderId         int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	SellOrder       *SellOrder             `protobuf:"bytes,2,opt,name=sell_order,json=sellOrder,proto3" json:"sell_order,omitempty"`
+	SellerPubKey    string                 `protobuf:"bytes,3,opt,name=seller_pub_key,json=sellerPubKey,proto3" json:"seller_pub_key,omitempty"`
+	Signature       string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	SellerSignature string                 `protobuf:"bytes,5,opt,name=seller_signature,json=sellerSignature,proto3" json:"seller_signature,omitempty"`
+	FilledTxHash    *string                `protobuf:"bytes,6,opt,name=filled_tx_hash,json=filledTxHash,proto3,oneof" json:"filled_tx_hash,omitempty"`
+	BlockNumber     *int64                 `protobuf:"varint,7,opt,name=block_number,json=blockNumber,proto3,oneof" json:"block_number,omitempty"`
+	BlockTimestamp  *int64                 `protobuf:"varint,8,opt,name=block_timestamp,json=blockTimestamp,proto3,oneof" json:"block_timestamp,omitempty"`
+	Buyer           *string                `protobuf:"bytes,9,opt,name=buyer,proto3,oneof" json:"buyer,omitempty"`
+	FilledPrice     *string                `protobuf:"bytes,10,opt,name=filled_price,json=filledPrice,proto3,oneof" json:"filled_price,omitempty"`
+	Status          string                 `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"`
+	InvalidReason   string                 `protobuf:"bytes,12,opt,name=invalid_reason,json=invalidReason,proto3" json:"invalid_reason,omitempty"`
+	CurrentPrice    string                 `protobuf:"bytes,13,opt,name=current_price,json=currentPrice,proto3" json:"current_price,omitempty"`
+	HighestBid      string                 `protobuf:"bytes,14,opt,name=highest_bid,json=highestBid,proto3" json:"highest_bid,omitempty"`
+	// 未被结算锁定的剩余数量，ERC721订单为1
+	Remaining int64 `protobuf:"varint,15,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	// 按支付代币精度换算的价格，支付代币未登记时为空
+	PriceDisplay  *PriceDisplay `protobuf:"bytes,16,opt,name=price_display,json=priceDisplay,proto3" json:"price_display,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_market_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Order) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Order) GetSellOrder() *SellOrder {
+	if x != nil {
+		return x.SellOrder
+	}
+	return nil
+}
+
+func (x *Order) GetSellerPubKey() string {
+	if x != nil {
+		return x.SellerPubKey
+	}
+	return ""
+}
+
+func (x *Order) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Order) GetSellerSignature() string {
+	if x != nil {
+		return x.SellerSignature
+	}
+	return ""
+}
+
+func (x *Order) GetFilledTxHash() string {
+	if x != nil && x.FilledTxHash != nil {
+		return *x.FilledTxHash
+	}
+	return ""
+}
+
+func (x *Order) GetBlockNumber() int64 {
+	if x != nil && x.BlockNumber != nil {
+		return *x.BlockNumber
+	}
+	return 0
+}
+
+func (x *Order) GetBlockTimestamp() int64 {
+	if x != nil && x.BlockTimestamp != nil {
+		return *x.BlockTimestamp
+	}
+	return 0
+}
+
+func (x *Order) GetBuyer() string {
+	if x != nil && x.Buyer != nil {
+		return *x.Buyer
+	}
+	return ""
+}
+
+func (x *Order) GetFilledPrice() string {
+	if x != nil && x.FilledPrice != nil {
+		return *x.FilledPrice
+	}
+	return ""
+}
+
+func (x *Order) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Order) GetInvalidReason() string {
+	if x != nil {
+		return x.InvalidReason
+	}
+	return ""
+}
+
+func (x *Order) GetCurrentPrice() string {
+	if x != nil {
+		return x.CurrentPrice
+	}
+	return ""
+}
+
+func (x *Order) GetHighestBid() string {
+	if x != nil {
+		return x.HighestBid
+	}
+	return ""
+}
+
+func (x *Order) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *Order) GetPriceDisplay() *PriceDisplay {
+	if x != nil {
+		return x.PriceDisplay
+	}
+	return nil
+}
+
+// 价格的可读形式，如"1.5 USDC"
+type PriceDisplay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Decimals      uint32                 `protobuf:"varint,2,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	Price         string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	StartPrice    string                 `protobuf:"bytes,4,opt,name=start_price,json=startPrice,proto3" json:"start_price,omitempty"`
+	EndPrice      string                 `protobuf:"bytes,5,opt,name=end_price,json=endPrice,proto3" json:"end_price,omitempty"`
+	DiscountPrice string                 `protobuf:"bytes,6,opt,name=discount_price,json=discountPrice,proto3" json:"discount_price,omitempty"`
+	CurrentPrice  string                 `protobuf:"bytes,7,opt,name=current_price,json=currentPrice,proto3" json:"current_price,omitempty"`
+	HighestBid    string                 `protobuf:"bytes,8,opt,name=highest_bid,json=highestBid,proto3" json:"highest_bid,omitempty"`
+	FilledPrice   string                 `protobuf:"bytes,9,opt,name=filled_price,json=filledPrice,proto3" json:"filled_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceDisplay) Reset() {
+	*x = PriceDisplay{}
+	mi := &file_market_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceDisplay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceDisplay) ProtoMessage() {}
+
+func (x *PriceDisplay) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceDisplay.ProtoReflect.Descriptor instead.
+func (*PriceDisplay) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PriceDisplay) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetDecimals() uint32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *PriceDisplay) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetStartPrice() string {
+	if x != nil {
+		return x.StartPrice
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetEndPrice() string {
+	if x != nil {
+		return x.EndPrice
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetDiscountPrice() string {
+	if x != nil {
+		return x.DiscountPrice
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetCurrentPrice() string {
+	if x != nil {
+		return x.CurrentPrice
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetHighestBid() string {
+	if x != nil {
+		return x.HighestBid
+	}
+	return ""
+}
+
+func (x *PriceDisplay) GetFilledPrice() string {
+	if x != nil {
+		return x.FilledPrice
+	}
+	return ""
+}
+
+// 字段含义与POST /market/create的请求体相同
+type CreateOrderRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	PrivateKey         string                 `protobuf:"bytes,1,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
+	PublicKey          string                 `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Signature          string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	Seller             string                 `protobuf:"bytes,4,opt,name=seller,proto3" json:"seller,omitempty"`
+	Nft                string                 `protobuf:"bytes,5,opt,name=nft,proto3" json:"nft,omitempty"`
+	TokenId            int64                  `protobuf:"varint,6,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	PayToken           string                 `protobuf:"bytes,7,opt,name=pay_token,json=payToken,proto3" json:"pay_token,omitempty"`
+	Price              int64                  `protobuf:"varint,8,opt,name=price,proto3" json:"price,omitempty"`
+	Deadline           int64                  `protobuf:"varint,9,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	OrderType          string                 `protobuf:"bytes,10,opt,name=order_type,json=orderType,proto3" json:"order_type,omitempty"`
+	StartPrice         string                 `protobuf:"bytes,11,opt,name=start_price,json=startPrice,proto3" json:"start_price,omitempty"`
+	EndPrice           string                 `protobuf:"bytes,12,opt,name=end_price,json=endPrice,proto3" json:"end_price,omitempty"`
+	StartTime          int64                  `protobuf:"varint,13,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime            int64                  `protobuf:"varint,14,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	ChainId            int64                  `protobuf:"varint,15,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	MerkleRoot         string                 `protobuf:"bytes,16,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	DiscountPrice      string                 `protobuf:"bytes,17,opt,name=discount_price,json=discountPrice,proto3" json:"discount_price,omitempty"`
+	SellerSignature    string                 `protobuf:"bytes,18,opt,name=seller_signature,json=sellerSignature,proto3" json:"seller_signature,omitempty"`
+	TokenStandard      string                 `protobuf:"bytes,19,opt,name=token_standard,json=tokenStandard,proto3" json:"token_standard,omitempty"`
+	Amount             int64                  `protobuf:"varint,20,opt,name=amount,proto3" json:"amount,omitempty"`
+	HumanPrice         string                 `protobuf:"bytes,21,opt,name=human_price,json=humanPrice,proto3" json:"human_price,omitempty"`
+	HumanStartPrice    string                 `protobuf:"bytes,22,opt,name=human_start_price,json=humanStartPrice,proto3" json:"human_start_price,omitempty"`
+	HumanEndPrice      string                 `protobuf:"bytes,23,opt,name=human_end_price,json=humanEndPrice,proto3" json:"human_end_price,omitempty"`
+	HumanDiscountPrice string                 `protobuf:"bytes,24,opt,name=human_discount_price,json=humanDiscountPrice,proto3" json:"human_discount_price,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *CreateOrderRequest) Reset() {
+	*x = CreateOrderRequest{}
+	mi := &file_market_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderRequest) ProtoMessage() {}
+
+func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateOrderRequest) GetPrivateKey() string {
+	if x != nil {
+		return x.PrivateKey
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetSeller() string {
+	if x != nil {
+		return x.Seller
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetNft() string {
+	if x != nil {
+		return x.Nft
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetTokenId() int64 {
+	if x != nil {
+		return x.TokenId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetPayToken() string {
+	if x != nil {
+		return x.PayToken
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetOrderType() string {
+	if x != nil {
+		return x.OrderType
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetStartPrice() string {
+	if x != nil {
+		return x.StartPrice
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetEndPrice() string {
+	if x != nil {
+		return x.EndPrice
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetMerkleRoot() string {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetDiscountPrice() string {
+	if x != nil {
+		return x.DiscountPrice
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetSellerSignature() string {
+	if x != nil {
+		return x.SellerSignature
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetTokenStandard() string {
+	if x != nil {
+		return x.TokenStandard
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetHumanPrice() string {
+	if x != nil {
+		return x.HumanPrice
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetHumanStartPrice() string {
+	if x != nil {
+		return x.HumanStartPrice
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetHumanEndPrice() string {
+	if x != nil {
+		return x.HumanEndPrice
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetHumanDiscountPrice() string {
+	if x != nil {
+		return x.HumanDiscountPrice
+	}
+	return ""
+}
+
+type ListOrdersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 为0时返回所有链的订单
+	ChainId       int64 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersRequest) Reset() {
+	*x = ListOrdersRequest{}
+	mi := &file_market_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersRequest) ProtoMessage() {}
+
+func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListOrdersRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+type ListOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResponse) Reset() {
+	*x = ListOrdersResponse{}
+	mi := &file_market_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResponse) ProtoMessage() {}
+
+func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_market_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOrderRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type BuyPermit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Deadline      int64                  `protobuf:"varint,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	Nonce         string                 `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Signature     string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuyPermit) Reset() {
+	*x = BuyPermit{}
+	mi := &file_market_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuyPermit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuyPermit) ProtoMessage() {}
+
+func (x *BuyPermit) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuyPermit.ProtoReflect.Descriptor instead.
+func (*BuyPermit) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BuyPermit) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *BuyPermit) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *BuyPermit) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *BuyPermit) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type PowChallengeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 目前gRPC只有create需要印章
+	Resource      string `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PowChallengeRequest) Reset() {
+	*x = PowChallengeRequest{}
+	mi := &file_market_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PowChallengeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PowChallengeRequest) ProtoMessage() {}
+
+func (x *PowChallengeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PowChallengeRequest.ProtoReflect.Descriptor instead.
+func (*PowChallengeRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PowChallengeRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+type PowChallenge struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 为false时不需要提交印章
+	Required bool   `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"`
+	Resource string `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	// SHA-256十六进制哈希开头0的个数
+	Difficulty int32  `protobuf:"varint,3,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	ExpiresAt  int64  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Nonce      string `protobuf:"bytes,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// 待求解的印章前缀，求解solution使sha256(stamp+solution)满足难度后提交stamp+solution
+	Stamp         string `protobuf:"bytes,6,opt,name=stamp,proto3" json:"stamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PowChallenge) Reset() {
+	*x = PowChallenge{}
+	mi := &file_market_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PowChallenge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PowChallenge) ProtoMessage() {}
+
+func (x *PowChallenge) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PowChallenge.ProtoReflect.Descriptor instead.
+func (*PowChallenge) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PowChallenge) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *PowChallenge) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *PowChallenge) GetDifficulty() int32 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *PowChallenge) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *PowChallenge) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *PowChallenge) GetStamp() string {
+	if x != nil {
+		return x.Stamp
+	}
+	return ""
+}
+
+type QuoteBuyRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Buyer   string                 `protobuf:"bytes,1,opt,name=buyer,proto3" json:"buyer,omitempty"`
+	OrderId int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// ERC1155订单的购买数量，为0时购买1个
+	Amount        int64    `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Proof         []string `protobuf:"bytes,4,rep,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuoteBuyRequest) Reset() {
+	*x = QuoteBuyRequest{}
+	mi := &file_market_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteBuyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteBuyRequest) ProtoMessage() {}
+
+func (x *QuoteBuyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteBuyRequest.ProtoReflect.Descriptor instead.
+func (*QuoteBuyRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QuoteBuyRequest) GetBuyer() string {
+	if x != nil {
+		return x.Buyer
+	}
+	return ""
+}
+
+func (x *QuoteBuyRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *QuoteBuyRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *QuoteBuyRequest) GetProof() []string {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+type RelayerQuote struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ChainId int64                  `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	OrderId int64                  `protobuf:"varint,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Buyer   string                 `protobuf:"bytes,4,opt,name=buyer,proto3" json:"buyer,omitempty"`
+	Amount  int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	// 成交总价，不含中继费用
+	Price      string `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	RelayerFee string `protobuf:"bytes,7,opt,name=relayer_fee,json=relayerFee,proto3" json:"relayer_fee,omitempty"`
+	FeeMode    string `protobuf:"bytes,8,opt,name=fee_mode,json=feeMode,proto3" json:"fee_mode,omitempty"`
+	// gas_plus模式下预估的gas费用(wei)
+	GasCost       string `protobuf:"bytes,9,opt,name=gas_cost,json=gasCost,proto3" json:"gas_cost,omitempty"`
+	ExpiresAt     int64  `protobuf:"varint,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayerQuote) Reset() {
+	*x = RelayerQuote{}
+	mi := &file_market_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayerQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayerQuote) ProtoMessage() {}
+
+func (x *RelayerQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayerQuote.ProtoReflect.Descriptor instead.
+func (*RelayerQuote) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RelayerQuote) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RelayerQuote) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *RelayerQuote) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *RelayerQuote) GetBuyer() string {
+	if x != nil {
+		return x.Buyer
+	}
+	return ""
+}
+
+func (x *RelayerQuote) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RelayerQuote) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *RelayerQuote) GetRelayerFee() string {
+	if x != nil {
+		return x.RelayerFee
+	}
+	return ""
+}
+
+func (x *RelayerQuote) GetFeeMode() string {
+	if x != nil {
+		return x.FeeMode
+	}
+	return ""
+}
+
+func (x *RelayerQuote) GetGasCost() string {
+	if x != nil {
+		return x.GasCost
+	}
+	return ""
+}
+
+func (x *RelayerQuote) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type QuoteBuyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Quote *RelayerQuote          `protobuf:"bytes,1,opt,name=quote,proto3" json:"quote,omitempty"`
+	// 成交价格 + 中继费用，买家的授权额度
+	Total string `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	// 买家需要personal_sign签名的报价消息
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuoteBuyResponse) Reset() {
+	*x = QuoteBuyResponse{}
+	mi := &file_market_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteBuyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteBuyResponse) ProtoMessage() {}
+
+func (x *QuoteBuyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteBuyResponse.ProtoReflect.Descriptor instead.
+func (*QuoteBuyResponse) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *QuoteBuyResponse) GetQuote() *RelayerQuote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+func (x *QuoteBuyResponse) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+func (x *QuoteBuyResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BuyQuote struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// 买家对报价消息的personal_sign签名
+	Signature     string `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuyQuote) Reset() {
+	*x = BuyQuote{}
+	mi := &file_market_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuyQuote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuyQuote) ProtoMessage() {}
+
+func (x *BuyQuote) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuyQuote.ProtoReflect.Descriptor instead.
+func (*BuyQuote) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BuyQuote) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *BuyQuote) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type BuyNFTRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Buyer   string                 `protobuf:"bytes,1,opt,name=buyer,proto3" json:"buyer,omitempty"`
+	OrderId int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Permit  *BuyPermit             `protobuf:"bytes,3,opt,name=permit,proto3" json:"permit,omitempty"`
+	Proof   []string               `protobuf:"bytes,4,rep,name=proof,proto3" json:"proof,omitempty"`
+	// ERC1155订单的购买数量，为0时购买1个
+	Amount int64 `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	// 中继费用报价，服务端配置了中继费用时必填
+	Quote         *BuyQuote `protobuf:"bytes,6,opt,name=quote,proto3" json:"quote,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuyNFTRequest) Reset() {
+	*x = BuyNFTRequest{}
+	mi := &file_market_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuyNFTRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuyNFTRequest) ProtoMessage() {}
+
+func (x *BuyNFTRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuyNFTRequest.ProtoReflect.Descriptor instead.
+func (*BuyNFTRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BuyNFTRequest) GetBuyer() string {
+	if x != nil {
+		return x.Buyer
+	}
+	return ""
+}
+
+func (x *BuyNFTRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *BuyNFTRequest) GetPermit() *BuyPermit {
+	if x != nil {
+		return x.Permit
+	}
+	return nil
+}
+
+func (x *BuyNFTRequest) GetProof() []string {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *BuyNFTRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *BuyNFTRequest) GetQuote() *BuyQuote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Signature     string                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_market_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CancelOrderRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *CancelOrderRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type WatchOrderEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 只推送该链的订单事件，为0时推送所有链
+	ChainId int64 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	// 只推送这些订单的事件，为空时推送所有订单
+	OrderIds      []int64 `protobuf:"varint,2,rep,packed,name=order_ids,json=orderIds,proto3" json:"order_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOrderEventsRequest) Reset() {
+	*x = WatchOrderEventsRequest{}
+	mi := &file_market_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrderEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrderEventsRequest) ProtoMessage() {}
+
+func (x *WatchOrderEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrderEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrderEventsRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WatchOrderEventsRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *WatchOrderEventsRequest) GetOrderIds() []int64 {
+	if x != nil {
+		return x.OrderIds
+	}
+	return nil
+}
+
+type OrderEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// order.created、order.settling、order.partially_filled、order.filled、order.reopened、order.cancelled、order.expired、order.invalidated
+	Type          string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Order         *Order `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	Time          int64  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_market_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *OrderEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+var File_market_proto protoreflect.FileDescriptor
+
+const file_market_proto_rawDesc = "" +
+	"\n" +
+	"\fmarket.proto\x12\fnftmarket.v1\"\xd8\x03\n" +
+	"\tSellOrder\x12\x16\n" +
+	"\x06seller\x18\x01 \x01(\tR\x06seller\x12\x10\n" +
+	"\x03nft\x18\x02 \x01(\tR\x03nft\x12\x19\n" +
+	"\btoken_id\x18\x03 \x01(\x03R\atokenId\x12\x1b\n" +
+	"\tpay_token\x18\x04 \x01(\tR\bpayToken\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x03R\x05price\x12\x1a\n" +
+	"\bdeadline\x18\x06 \x01(\x03R\bdeadline\x12\x1d\n" +
+	"\n" +
+	"order_type\x18\a \x01(\tR\torderType\x12\x1f\n" +
+	"\vstart_price\x18\b \x01(\tR\n" +
+	"startPrice\x12\x1b\n" +
+	"\tend_price\x18\t \x01(\tR\bendPrice\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\n" +
+	" \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\v \x01(\x03R\aendTime\x12\x19\n" +
+	"\bchain_id\x18\f \x01(\x03R\achainId\x12\x1f\n" +
+	"\vmerkle_root\x18\r \x01(\tR\n" +
+	"merkleRoot\x12%\n" +
+	"\x0ediscount_price\x18\x0e \x01(\tR\rdiscountPrice\x12%\n" +
+	"\x0etoken_standard\x18\x0f \x01(\tR\rtokenStandard\x12\x16\n" +
+	"\x06amount\x18\x10 \x01(\x03R\x06amount\"\xc4\x05\n" +
+	"\x05Order\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x126\n" +
+	"\n" +
+	"sell_order\x18\x02 \x01(\v2\x17.nftmarket.v1.SellOrderR\tsellOrder\x12$\n" +
+	"\x0eseller_pub_key\x18\x03 \x01(\tR\fsellerPubKey\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\tR\tsignature\x12)\n" +
+	"\x10seller_signature\x18\x05 \x01(\tR\x0fsellerSignature\x12)\n" +
+	"\x0efilled_tx_hash\x18\x06 \x01(\tH\x00R\ffilledTxHash\x88\x01\x01\x12&\n" +
+	"\fblock_number\x18\a \x01(\x03H\x01R\vblockNumber\x88\x01\x01\x12,\n" +
+	"\x0fblock_timestamp\x18\b \x01(\x03H\x02R\x0eblockTimestamp\x88\x01\x01\x12\x19\n" +
+	"\x05buyer\x18\t \x01(\tH\x03R\x05buyer\x88\x01\x01\x12&\n" +
+	"\ffilled_price\x18\n" +
+	" \x01(\tH\x04R\vfilledPrice\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06status\x12%\n" +
+	"\x0einvalid_reason\x18\f \x01(\tR\rinvalidReason\x12#\n" +
+	"\rcurrent_price\x18\r \x01(\tR\fcurrentPrice\x12\x1f\n" +
+	"\vhighest_bid\x18\x0e \x01(\tR\n" +
+	"highestBid\x12\x1c\n" +
+	"\tremaining\x18\x0f \x01(\x03R\tremaining\x12?\n" +
+	"\rprice_display\x18\x10 \x01(\v2\x1a.nftmarket.v1.PriceDisplayR\fpriceDisplayB\x11\n" +
+	"\x0f_filled_tx_hashB\x0f\n" +
+	"\r_block_numberB\x12\n" +
+	"\x10_block_timestampB\b\n" +
+	"\x06_buyerB\x0f\n" +
+	"\r_filled_price\"\xa6\x02\n" +
+	"\fPriceDisplay\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bdecimals\x18\x02 \x01(\rR\bdecimals\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\x12\x1f\n" +
+	"\vstart_price\x18\x04 \x01(\tR\n" +
+	"startPrice\x12\x1b\n" +
+	"\tend_price\x18\x05 \x01(\tR\bendPrice\x12%\n" +
+	"\x0ediscount_price\x18\x06 \x01(\tR\rdiscountPrice\x12#\n" +
+	"\rcurrent_price\x18\a \x01(\tR\fcurrentPrice\x12\x1f\n" +
+	"\vhighest_bid\x18\b \x01(\tR\n" +
+	"highestBid\x12!\n" +
+	"\ffilled_price\x18\t \x01(\tR\vfilledPrice\"\x91\x06\n" +
+	"\x12CreateOrderRequest\x12\x1f\n" +
+	"\vprivate_key\x18\x01 \x01(\tR\n" +
+	"privateKey\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x02 \x01(\tR\tpublicKey\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\x12\x16\n" +
+	"\x06seller\x18\x04 \x01(\tR\x06seller\x12\x10\n" +
+	"\x03nft\x18\x05 \x01(\tR\x03nft\x12\x19\n" +
+	"\btoken_id\x18\x06 \x01(\x03R\atokenId\x12\x1b\n" +
+	"\tpay_token\x18\a \x01(\tR\bpayToken\x12\x14\n" +
+	"\x05price\x18\b \x01(\x03R\x05price\x12\x1a\n" +
+	"\bdeadline\x18\t \x01(\x03R\bdeadline\x12\x1d\n" +
+	"\n" +
+	"order_type\x18\n" +
+	" \x01(\tR\torderType\x12\x1f\n" +
+	"\vstart_price\x18\v \x01(\tR\n" +
+	"startPrice\x12\x1b\n" +
+	"\tend_price\x18\f \x01(\tR\bendPrice\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\r \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x0e \x01(\x03R\aendTime\x12\x19\n" +
+	"\bchain_id\x18\x0f \x01(\x03R\achainId\x12\x1f\n" +
+	"\vmerkle_root\x18\x10 \x01(\tR\n" +
+	"merkleRoot\x12%\n" +
+	"\x0ediscount_price\x18\x11 \x01(\tR\rdiscountPrice\x12)\n" +
+	"\x10seller_signature\x18\x12 \x01(\tR\x0fsellerSignature\x12%\n" +
+	"\x0etoken_standard\x18\x13 \x01(\tR\rtokenStandard\x12\x16\n" +
+	"\x06amount\x18\x14 \x01(\x03R\x06amount\x12\x1f\n" +
+	"\vhuman_price\x18\x15 \x01(\tR\n" +
+	"humanPrice\x12*\n" +
+	"\x11human_start_price\x18\x16 \x01(\tR\x0fhumanStartPrice\x12&\n" +
+	"\x0fhuman_end_price\x18\x17 \x01(\tR\rhumanEndPrice\x120\n" +
+	"\x14human_discount_price\x18\x18 \x01(\tR\x12humanDiscountPrice\".\n" +
+	"\x11ListOrdersRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\"A\n" +
+	"\x12ListOrdersResponse\x12+\n" +
+	"\x06orders\x18\x01 \x03(\v2\x13.nftmarket.v1.OrderR\x06orders\",\n" +
+	"\x0fGetOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"o\n" +
+	"\tBuyPermit\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1a\n" +
+	"\bdeadline\x18\x02 \x01(\x03R\bdeadline\x12\x14\n" +
+	"\x05nonce\x18\x03 \x01(\tR\x05nonce\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\tR\tsignature\"1\n" +
+	"\x13PowChallengeRequest\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\"\xb1\x01\n" +
+	"\fPowChallenge\x12\x1a\n" +
+	"\brequired\x18\x01 \x01(\bR\brequired\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\x03 \x01(\x05R\n" +
+	"difficulty\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12\x14\n" +
+	"\x05nonce\x18\x05 \x01(\tR\x05nonce\x12\x14\n" +
+	"\x05stamp\x18\x06 \x01(\tR\x05stamp\"p\n" +
+	"\x0fQuoteBuyRequest\x12\x14\n" +
+	"\x05buyer\x18\x01 \x01(\tR\x05buyer\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\x12\x14\n" +
+	"\x05proof\x18\x04 \x03(\tR\x05proof\"\x8e\x02\n" +
+	"\fRelayerQuote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x03R\achainId\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\x03R\aorderId\x12\x14\n" +
+	"\x05buyer\x18\x04 \x01(\tR\x05buyer\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\tR\x05price\x12\x1f\n" +
+	"\vrelayer_fee\x18\a \x01(\tR\n" +
+	"relayerFee\x12\x19\n" +
+	"\bfee_mode\x18\b \x01(\tR\afeeMode\x12\x19\n" +
+	"\bgas_cost\x18\t \x01(\tR\agasCost\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\x03R\texpiresAt\"t\n" +
+	"\x10QuoteBuyResponse\x120\n" +
+	"\x05quote\x18\x01 \x01(\v2\x1a.nftmarket.v1.RelayerQuoteR\x05quote\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\tR\x05total\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"8\n" +
+	"\bBuyQuote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\tR\tsignature\"\xcd\x01\n" +
+	"\rBuyNFTRequest\x12\x14\n" +
+	"\x05buyer\x18\x01 \x01(\tR\x05buyer\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12/\n" +
+	"\x06permit\x18\x03 \x01(\v2\x17.nftmarket.v1.BuyPermitR\x06permit\x12\x14\n" +
+	"\x05proof\x18\x04 \x03(\tR\x05proof\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12,\n" +
+	"\x05quote\x18\x06 \x01(\v2\x16.nftmarket.v1.BuyQuoteR\x05quote\"M\n" +
+	"\x12CancelOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\tR\tsignature\"Q\n" +
+	"\x17WatchOrderEventsRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x1b\n" +
+	"\torder_ids\x18\x02 \x03(\x03R\borderIds\"_\n" +
+	"\n" +
+	"OrderEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12)\n" +
+	"\x05order\x18\x02 \x01(\v2\x13.nftmarket.v1.OrderR\x05order\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\x03R\x04time2\xd5\x04\n" +
+	"\x06Market\x12P\n" +
+	"\x0fGetPowChallenge\x12!.nftmarket.v1.PowChallengeRequest\x1a\x1a.nftmarket.v1.PowChallenge\x12D\n" +
+	"\vCreateOrder\x12 .nftmarket.v1.CreateOrderRequest\x1a\x13.nftmarket.v1.Order\x12O\n" +
+	"\n" +
+	"ListOrders\x12\x1f.nftmarket.v1.ListOrdersRequest\x1a .nftmarket.v1.ListOrdersResponse\x12>\n" +
+	"\bGetOrder\x12\x1d.nftmarket.v1.GetOrderRequest\x1a\x13.nftmarket.v1.Order\x12I\n" +
+	"\bQuoteBuy\x12\x1d.nftmarket.v1.QuoteBuyRequest\x1a\x1e.nftmarket.v1.QuoteBuyResponse\x12:\n" +
+	"\x06BuyNFT\x12\x1b.nftmarket.v1.BuyNFTRequest\x1a\x13.nftmarket.v1.Order\x12D\n" +
+	"\vCancelOrder\x12 .nftmarket.v1.CancelOrderRequest\x1a\x13.nftmarket.v1.Order\x12U\n" +
+	"\x10WatchOrderEvents\x12%.nftmarket.v1.WatchOrderEventsRequest\x1a\x18.nftmarket.v1.OrderEvent0\x01B\x14Z\x12nftmarket/marketpbb\x06proto3"
+
+var (
+	file_market_proto_rawDescOnce sync.Once
+	file_market_proto_rawDescData []byte
+)
+
+func file_market_proto_rawDescGZIP() []byte {
+	file_market_proto_rawDescOnce.Do(func() {
+		file_market_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_market_proto_rawDesc), len(file_market_proto_rawDesc)))
+	})
+	return file_market_proto_rawDescData
+}
+
+var file_market_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_market_proto_goTypes = []any{
+	(*SellOrder)(nil),               // 0: nftmarket.v1.SellOrder
+	(*Order)(nil),                   // 1: nftmarket.v1.Order
+	(*PriceDisplay)(nil),            // 2: nftmarket.v1.PriceDisplay
+	(*CreateOrderRequest)(nil),      // 3: nftmarket.v1.CreateOrderRequest
+	(*ListOrdersRequest)(nil),       // 4: nftmarket.v1.ListOrdersRequest
+	(*ListOrdersResponse)(nil),      // 5: nftmarket.v1.ListOrdersResponse
+	(*GetOrderRequest)(nil),         // 6: nftmarket.v1.GetOrderRequest
+	(*BuyPermit)(nil),               // 7: nftmarket.v1.BuyPermit
+	(*PowChallengeRequest)(nil),     // 8: nftmarket.v1.PowChallengeRequest
+	(*PowChallenge)(nil),            // 9: nftmarket.v1.PowChallenge
+	(*QuoteBuyRequest)(nil),         // 10: nftmarket.v1.QuoteBuyRequest
+	(*RelayerQuote)(nil),            // 11: nftmarket.v1.RelayerQuote
+	(*QuoteBuyResponse)(nil),        // 12: nftmarket.v1.QuoteBuyResponse
+	(*BuyQuote)(nil),                // 13: nftmarket.v1.BuyQuote
+	(*BuyNFTRequest)(nil),           // 14: nftmarket.v1.BuyNFTRequest
+	(*CancelOrderRequest)(nil),      // 15: nftmarket.v1.CancelOrderRequest
+	(*WatchOrderEventsRequest)(nil), // 16: nftmarket.v1.WatchOrderEventsRequest
+	(*OrderEvent)(nil),              // 17: nftmarket.v1.OrderEvent
+}
+var file_market_proto_depIdxs = []int32{
+	0,  // 0: nftmarket.v1.Order.sell_order:type_name -> nftmarket.v1.SellOrder
+	2,  // 1: nftmarket.v1.Order.price_display:type_name -> nftmarket.v1.PriceDisplay
+	1,  // 2: nftmarket.v1.ListOrdersResponse.orders:type_name -> nftmarket.v1.Order
+	11, // 3: nftmarket.v1.QuoteBuyResponse.quote:type_name -> nftmarket.v1.RelayerQuote
+	7,  // 4: nftmarket.v1.BuyNFTRequest.permit:type_name -> nftmarket.v1.BuyPermit
+	13, // 5: nftmarket.v1.BuyNFTRequest.quote:type_name -> nftmarket.v1.BuyQuote
+	1,  // 6: nftmarket.v1.OrderEvent.order:type_name -> nftmarket.v1.Order
+	8,  // 7: nftmarket.v1.Market.GetPowChallenge:input_type -> nftmarket.v1.PowChallengeRequest
+	3,  // 8: nftmarket.v1.Market.CreateOrder:input_type -> nftmarket.v1.CreateOrderRequest
+	4,  // 9: nftmarket.v1.Market.ListOrders:input_type -> nftmarket.v1.ListOrdersRequest
+	6,  // 10: nftmarket.v1.Market.GetOrder:input_type -> nftmarket.v1.GetOrderRequest
+	10, // 11: nftmarket.v1.Market.QuoteBuy:input_type -> nftmarket.v1.QuoteBuyRequest
+	14, // 12: nftmarket.v1.Market.BuyNFT:input_type -> nftmarket.v1.BuyNFTRequest
+	15, // 13: nftmarket.v1.Market.CancelOrder:input_type -> nftmarket.v1.CancelOrderRequest
+	16, // 14: nftmarket.v1.Market.WatchOrderEvents:input_type -> nftmarket.v1.WatchOrderEventsRequest
+	9,  // 15: nftmarket.v1.Market.GetPowChallenge:output_type -> nftmarket.v1.PowChallenge
+	1,  // 16: nftmarket.v1.Market.CreateOrder:output_type -> nftmarket.v1.Order
+	5,  // 17: nftmarket.v1.Market.ListOrders:output_type -> nftmarket.v1.ListOrdersResponse
+	1,  // 18: nftmarket.v1.Market.GetOrder:output_type -> nftmarket.v1.Order
+	12, // 19: nftmarket.v1.Market.QuoteBuy:output_type -> nftmarket.v1.QuoteBuyResponse
+	1,  // 20: nftmarket.v1.Market.BuyNFT:output_type -> nftmarket.v1.Order
+	1,  // 21: nftmarket.v1.Market.CancelOrder:output_type -> nftmarket.v1.Order
+	17, // 22: nftmarket.v1.Market.WatchOrderEvents:output_type -> nftmarket.v1.OrderEvent
+	15, // [15:23] is the sub-list for method output_type
+	7,  // [7:15] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_market_proto_init() }
+func file_market_proto_init() {
+	if File_market_proto != nil {
+		return
+	}
+	file_market_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_market_proto_rawDesc), len(file_market_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_market_proto_goTypes,
+		DependencyIndexes: file_market_proto_depIdxs,
+		MessageInfos:      file_market_proto_msgTypes,
+	}.Build()
+	File_market_proto = out.File
+	file_market_proto_goTypes = nil
+	file_market_proto_depIdxs = nil
+}
@@ -0,0 +1,409 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: market.proto
+
+// nft_market的gRPC接口，与REST接口共用同一套service层，字段统一使用snake_case
+
+package marketpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Market_GetPowChallenge_FullMethodName  = "/nftmarket.v1.Market/GetPowChallenge"
+	Market_CreateOrder_FullMethodName      = "/nftmarket.v1.Market/CreateOrder"
+	Market_ListOrders_FullMethodName       = "/nftmarket.v1.Market/ListOrders"
+	Market_GetOrder_FullMethodName         = "/nftmarket.v1.Market/GetOrder"
+	Market_QuoteBuy_FullMethodName         = "/nftmarket.v1.Market/QuoteBuy"
+	Market_BuyNFT_FullMethodName           = "/nftmarket.v1.Market/BuyNFT"
+	Market_CancelOrder_FullMethodName      = "/nftmarket.v1.Market/CancelOrder"
+	Market_WatchOrderEvents_FullMethodName = "/nftmarket.v1.Market/WatchOrderEvents"
+)
+
+// MarketClient is the client API for Market service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MarketClient interface {
+	// 获取工作量证明挑战，等同GET /pow/challenge
+	GetPowChallenge(ctx context.Context, in *PowChallengeRequest, opts ...grpc.CallOption) (*PowChallenge, error)
+	// 上架订单，等同POST /market/create，开启工作量证明时需在metadata x-pow-stamp中携带印章
+	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// 上架中的订单，等同GET /market/list
+	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
+	// 单个订单，等同GET /market/order/{id}
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// 购买前获取中继费用报价，等同POST /market/quote
+	QuoteBuy(ctx context.Context, in *QuoteBuyRequest, opts ...grpc.CallOption) (*QuoteBuyResponse, error)
+	// 购买，等同POST /market/buy，交易广播后不等待上链，返回status为settling的订单，ERC1155订单部分购买时仍为open
+	BuyNFT(ctx context.Context, in *BuyNFTRequest, opts ...grpc.CallOption) (*Order, error)
+	// 卖家撤单，等同POST /market/cancel
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// 订阅订单状态变化事件，连接建立之后发生的事件才会推送
+	WatchOrderEvents(ctx context.Context, in *WatchOrderEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error)
+}
+
+type marketClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketClient(cc grpc.ClientConnInterface) MarketClient {
+	return &marketClient{cc}
+}
+
+func (c *marketClient) GetPowChallenge(ctx context.Context, in *PowChallengeRequest, opts ...grpc.CallOption) (*PowChallenge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PowChallenge)
+	err := c.cc.Invoke(ctx, Market_GetPowChallenge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, Market_CreateOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, Market_ListOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, Market_GetOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) QuoteBuy(ctx context.Context, in *QuoteBuyRequest, opts ...grpc.CallOption) (*QuoteBuyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuoteBuyResponse)
+	err := c.cc.Invoke(ctx, Market_QuoteBuy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) BuyNFT(ctx context.Context, in *BuyNFTRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, Market_BuyNFT_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, Market_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) WatchOrderEvents(ctx context.Context, in *WatchOrderEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Market_ServiceDesc.Streams[0], Market_WatchOrderEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOrderEventsRequest, OrderEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Market_WatchOrderEventsClient = grpc.ServerStreamingClient[OrderEvent]
+
+// MarketServer is the server API for Market service.
+// All implementations must embed UnimplementedMarketServer
+// for forward compatibility.
+type MarketServer interface {
+	// 获取工作量证明挑战，等同GET /pow/challenge
+	GetPowChallenge(context.Context, *PowChallengeRequest) (*PowChallenge, error)
+	// 上架订单，等同POST /market/create，开启工作量证明时需在metadata x-pow-stamp中携带印章
+	CreateOrder(context.Context, *CreateOrderRequest) (*Order, error)
+	// 上架中的订单，等同GET /market/list
+	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
+	// 单个订单，等同GET /market/order/{id}
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	// 购买前获取中继费用报价，等同POST /market/quote
+	QuoteBuy(context.Context, *QuoteBuyRequest) (*QuoteBuyResponse, error)
+	// 购买，等同POST /market/buy，交易广播后不等待上链，返回status为settling的订单，ERC1155订单部分购买时仍为open
+	BuyNFT(context.Context, *BuyNFTRequest) (*Order, error)
+	// 卖家撤单，等同POST /market/cancel
+	CancelOrder(context.Context, *CancelOrderRequest) (*Order, error)
+	// 订阅订单状态变化事件，连接建立之后发生的事件才会推送
+	WatchOrderEvents(*WatchOrderEventsRequest, grpc.ServerStreamingServer[OrderEvent]) error
+	mustEmbedUnimplementedMarketServer()
+}
+
+// UnimplementedMarketServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMarketServer struct{}
+
+func (UnimplementedMarketServer) GetPowChallenge(context.Context, *PowChallengeRequest) (*PowChallenge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPowChallenge not implemented")
+}
+func (UnimplementedMarketServer) CreateOrder(context.Context, *CreateOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
+}
+func (UnimplementedMarketServer) ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrders not implemented")
+}
+func (UnimplementedMarketServer) GetOrder(context.Context, *GetOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedMarketServer) QuoteBuy(context.Context, *QuoteBuyRequest) (*QuoteBuyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QuoteBuy not implemented")
+}
+func (UnimplementedMarketServer) BuyNFT(context.Context, *BuyNFTRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuyNFT not implemented")
+}
+func (UnimplementedMarketServer) CancelOrder(context.Context, *CancelOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedMarketServer) WatchOrderEvents(*WatchOrderEventsRequest, grpc.ServerStreamingServer[OrderEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrderEvents not implemented")
+}
+func (UnimplementedMarketServer) mustEmbedUnimplementedMarketServer() {}
+func (UnimplementedMarketServer) testEmbeddedByValue()                {}
+
+// UnsafeMarketServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketServer will
+// result in compilation errors.
+type UnsafeMarketServer interface {
+	mustEmbedUnimplementedMarketServer()
+}
+
+func RegisterMarketServer(s grpc.ServiceRegistrar, srv MarketServer) {
+	// If the following call pancis, it indicates UnimplementedMarketServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Market_ServiceDesc, srv)
+}
+
+func _Market_GetPowChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PowChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).GetPowChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_GetPowChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).GetPowChallenge(ctx, req.(*PowChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_CreateOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).CreateOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_CreateOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).CreateOrder(ctx, req.(*CreateOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_ListOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).ListOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_QuoteBuy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuoteBuyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).QuoteBuy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_QuoteBuy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).QuoteBuy(ctx, req.(*QuoteBuyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_BuyNFT_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuyNFTRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).BuyNFT(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_BuyNFT_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).BuyNFT(ctx, req.(*BuyNFTRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_WatchOrderEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrderEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketServer).WatchOrderEvents(m, &grpc.GenericServerStream[WatchOrderEventsRequest, OrderEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Market_WatchOrderEventsServer = grpc.ServerStreamingServer[OrderEvent]
+
+// Market_ServiceDesc is the grpc.ServiceDesc for Market service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Market_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nftmarket.v1.Market",
+	HandlerType: (*MarketServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPowChallenge",
+			Handler:    _Market_GetPowChallenge_Handler,
+		},
+		{
+			MethodName: "CreateOrder",
+			Handler:    _Market_CreateOrder_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _Market_ListOrders_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _Market_GetOrder_Handler,
+		},
+		{
+			MethodName: "QuoteBuy",
+			Handler:    _Market_QuoteBuy_Handler,
+		},
+		{
+			MethodName: "BuyNFT",
+			Handler:    _Market_BuyNFT_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _Market_CancelOrder_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrderEvents",
+			Handler:       _Market_WatchOrderEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "market.proto",
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"nftmarket/global"
+	"nftmarket/internal/response"
+	"nftmarket/service"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// 管理员签名的有效时间窗口
+const adminSignatureTTL = 5 * time.Minute
+
+// 管理员请求体的大小上限
+const adminMaxBodySize = 1 << 20
+
+// AdminMessage 管理员需要签名的消息内容，绑定请求方法、路径(含查询参数chain_id)、请求体的sha256(hex)和时间戳，
+// 防止重放到其他接口或其他链，或替换请求体
+func AdminMessage(method, path string, body []byte, timestamp int64) string {
+	bodyHash := sha256.Sum256(body)
+	return fmt.Sprintf("nftmarket admin\n%s %s\n%s\n%d", method, path, hex.EncodeToString(bodyHash[:]), timestamp)
+}
+
+// AdminAuth 校验请求是否由查询参数chain_id指定链(默认链)上的NFTMarket合约owner签名
+// 请求头 X-Admin-Timestamp 为unix秒，X-Admin-Signature 为owner对AdminMessage的personal_sign签名，owner为合约钱包时为其ERC-1271签名
+// 同一签名在有效期内只能使用一次
+func AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timestamp, err := strconv.ParseInt(c.GetHeader("X-Admin-Timestamp"), 10, 64)
+		if err != nil {
+			response.Abort(c, http.StatusUnauthorized, "Invalid admin timestamp")
+			return
+		}
+		diff := time.Since(time.Unix(timestamp, 0))
+		if diff > adminSignatureTTL || diff < -adminSignatureTTL {
+			response.Abort(c, http.StatusUnauthorized, "Admin signature expired")
+			return
+		}
+		var chainId int64
+		if value := c.Query("chain_id"); value != "" {
+			if chainId, err = strconv.ParseInt(value, 10, 64); err != nil {
+				response.Abort(c, http.StatusBadRequest, "Invalid chain_id")
+				return
+			}
+		}
+		adminChain, err := global.Chains.Get(chainId)
+		if err != nil {
+			response.Abort(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		owner, err := adminChain.Market.Owner(nil)
+		if err != nil {
+			response.Abort(c, http.StatusInternalServerError, "Failed to query contract owner")
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, adminMaxBodySize))
+		if err != nil {
+			response.Abort(c, http.StatusRequestEntityTooLarge, "Admin request body too large")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		// owner为多签等合约钱包时通过ERC-1271校验
+		message := AdminMessage(c.Request.Method, c.Request.URL.RequestURI(), body, timestamp)
+		digest := common.BytesToHash(accounts.TextHash([]byte(message)))
+		valid, err := adminChain.VerifySignature(c.Request.Context(), owner, digest, c.GetHeader("X-Admin-Signature"))
+		if err != nil {
+			response.Abort(c, http.StatusUnauthorized, "Invalid admin signature")
+			return
+		}
+		if !valid {
+			response.Abort(c, http.StatusForbidden, "Only contract owner is allowed")
+			return
+		}
+		// 按消息摘要去重，签名的不同编码(如高s值)也视为同一签名
+		err = service.UseAdminSignature(digest.Hex(), time.Unix(timestamp, 0).Add(adminSignatureTTL))
+		if errors.Is(err, service.ErrAdminSignatureUsed) {
+			response.Abort(c, http.StatusUnauthorized, "Admin signature already used")
+			return
+		}
+		if err != nil {
+			response.Abort(c, http.StatusInternalServerError, "Failed to record admin signature")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"nftmarket/global"
+	"nftmarket/internal/response"
+	"nftmarket/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApiKeyIdContextKey gin上下文中保存当前API key id的键
+const ApiKeyIdContextKey = service.ApiKeyIdContextKey
+
+// APIKeyAuth 校验请求头X-API-Key，RateLimit.RequireAPIKey为false时不校验
+func APIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if global.RateLimitConfig == nil || !global.RateLimitConfig.RequireAPIKey {
+			c.Next()
+			return
+		}
+		authenticateAPIKey(c)
+	}
+}
+
+// RequireAPIKey 无论RateLimit.RequireAPIKey是否开启都要求有效的API key，用于全量导出等开销大的接口
+func RequireAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ApiKeyIdContextKey); ok {
+			c.Next()
+			return
+		}
+		authenticateAPIKey(c)
+	}
+}
+
+func authenticateAPIKey(c *gin.Context) {
+	key := c.GetHeader("X-API-Key")
+	if key == "" {
+		response.Abort(c, http.StatusUnauthorized, "Missing API key")
+		return
+	}
+	apiKey, err := service.AuthenticateAPIKey(key)
+	if err != nil {
+		response.Abort(c, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+	c.Set(ApiKeyIdContextKey, apiKey.Id)
+	c.Next()
+}
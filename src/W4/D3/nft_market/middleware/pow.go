@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"nftmarket/internal/pow"
+	"nftmarket/internal/response"
+	"nftmarket/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProofOfWork 要求请求头X-PoW-Stamp携带resource的已求解印章，需在APIKeyAuth之后执行以按API key区分调用方
+func ProofOfWork(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := service.PowClient(c.GetInt64(ApiKeyIdContextKey), c.ClientIP())
+		if err := service.VerifyPowStamp(c.Request.Context(), c.GetHeader(pow.StampHeader), resource, client); err != nil {
+			var serviceErr *service.Error
+			if errors.As(err, &serviceErr) {
+				response.Abort(c, serviceErr.Status, serviceErr.Message)
+			} else {
+				response.Abort(c, http.StatusInternalServerError, "Internal error")
+			}
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"nftmarket/config/setting"
+	"nftmarket/global"
+	"nftmarket/internal/response"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// 超过此时间未使用的令牌桶会被清理
+const limiterIdleTTL = 10 * time.Minute
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// limiterStore 按 接口+调用方 保存令牌桶
+type limiterStore struct {
+	mu        sync.Mutex
+	limiters  map[string]*limiterEntry
+	lastSweep time.Time
+}
+
+var limiters = &limiterStore{limiters: make(map[string]*limiterEntry)}
+
+func (s *limiterStore) get(key string, r float64, burst int) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) > limiterIdleTTL {
+		for k, entry := range s.limiters {
+			if now.Sub(entry.lastSeen) > limiterIdleTTL {
+				delete(s.limiters, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	entry, ok := s.limiters[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(rate.Limit(r), burst)}
+		s.limiters[key] = entry
+	}
+	entry.lastSeen = now
+	return entry.limiter
+}
+
+// IPRateLimit 按接口和客户端IP限流，应在API key校验之前执行，防止未授权请求刷接口
+func IPRateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := routeLimit(c.FullPath())
+		if limit.IPRate <= 0 {
+			c.Next()
+			return
+		}
+		key := fmt.Sprintf("ip|%s|%s", c.FullPath(), c.ClientIP())
+		if !allow(c, key, limit.IPRate, limit.IPBurst) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// KeyRateLimit 按接口和API key限流，需在APIKeyAuth之后执行
+func KeyRateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyId, ok := c.Get(ApiKeyIdContextKey)
+		limit := routeLimit(c.FullPath())
+		if !ok || limit.KeyRate <= 0 {
+			c.Next()
+			return
+		}
+		key := fmt.Sprintf("key|%s|%d", c.FullPath(), keyId)
+		if !allow(c, key, limit.KeyRate, limit.KeyBurst) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow 消耗一个令牌并设置配额响应头，令牌不足时返回429
+func allow(c *gin.Context, key string, r float64, burst int) bool {
+	if burst <= 0 {
+		burst = 1
+	}
+	limiter := limiters.get(key, r, burst)
+	allowed := limiter.Allow()
+	remaining := int(math.Max(0, math.Floor(limiter.Tokens())))
+	c.Header("X-RateLimit-Limit", strconv.Itoa(burst))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	// 令牌桶补满所需的秒数
+	reset := math.Ceil(float64(burst-remaining) / r)
+	c.Header("X-RateLimit-Reset", strconv.Itoa(int(reset)))
+	if !allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(1/r))))
+		response.Abort(c, http.StatusTooManyRequests, "Rate limit exceeded")
+		return false
+	}
+	return true
+}
+
+// routeLimit 查找接口对应的限流规则，未配置时使用Default
+func routeLimit(path string) setting.RouteLimit {
+	conf := global.RateLimitConfig
+	if conf == nil {
+		return setting.RouteLimit{}
+	}
+	for _, limit := range conf.Routes {
+		if limit.Path == path {
+			return limit
+		}
+	}
+	return conf.Default
+}
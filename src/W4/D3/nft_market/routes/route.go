@@ -1,16 +1,82 @@
 package routers
 
 import (
+	"log"
+	"net/http"
+	"nftmarket/doc"
+	"nftmarket/global"
+	"nftmarket/middleware"
 	"nftmarket/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 func InitRouter() {
-	r := gin.Default()
-	r.POST("/market/create", service.CreateOrder)
-	r.GET("/market/list", service.ListSellOrders)
-	r.POST("/market/buy", service.BuyNFT)
-	r.GET("/keypair", service.GenKeyPair)
+	r := NewRouter()
+	// 接口文档与路由不一致时拒绝启动，避免文档过期
+	if err := doc.CheckRoutes(r.Routes()); err != nil {
+		log.Panic("doc.CheckRoutes error : ", err)
+	}
 	r.Run(":8080")
 }
+
+// NewRouter 注册所有接口路由
+func NewRouter() *gin.Engine {
+	r := gin.Default()
+	// 只信任配置的反向代理，否则任何人都能通过X-Forwarded-For伪造IP绕过按IP的限流
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Panic("SetTrustedProxies error : ", err)
+	}
+	r.Use(middleware.IPRateLimit())
+
+	// 业务接口，需要API key
+	api := r.Group("", middleware.APIKeyAuth(), middleware.KeyRateLimit())
+	api.GET("/pow/challenge", service.PowChallenge)
+	api.POST("/market/create", middleware.ProofOfWork(service.PowResourceCreate), service.CreateOrder)
+	api.GET("/market/list", service.ListSellOrders)
+	api.GET("/market/order/:id", service.GetOrder)
+	api.POST("/market/cancel", service.CancelOrder)
+	api.POST("/market/quote", service.QuoteBuy)
+	api.POST("/market/buy", service.BuyNFT)
+	api.POST("/market/bid", service.PlaceBid)
+	api.GET("/market/bid/fee", service.BidFee)
+	api.GET("/market/bids", service.ListBids)
+	api.POST("/market/settle", service.SettleAuction)
+	api.GET("/market/trades", service.ListTrades)
+	api.GET("/market/trades/export", middleware.RequireAPIKey(), service.ExportTrades)
+	api.GET("/market/stats", service.CollectionStatistics)
+	api.POST("/market/merkle", service.CreateMerkleTree)
+	api.POST("/market/merkle/import", service.ImportMerkleTree)
+	api.GET("/market/merkle/:root", service.ExportMerkleTree)
+	api.GET("/market/merkle/:root/proof", service.GetMerkleProof)
+	api.GET("/market/paytokens", service.ListPayTokens)
+	api.GET("/keypair", middleware.ProofOfWork(service.PowResourceKeyPair), service.GenKeyPair)
+	api.POST("/webhooks", service.CreateWebhook)
+	api.GET("/webhooks", service.ListWebhooks)
+	api.DELETE("/webhooks/:id", service.DeleteWebhook)
+	api.GET("/webhooks/:id/dead-letters", service.ListWebhookDeadLetters)
+	api.POST("/webhooks/:id/replay", service.ReplayWebhook)
+	r.GET("/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", doc.OpenAPI)
+	})
+
+	// 管理员接口，需要合约owner签名
+	admin := r.Group("/admin", middleware.AdminAuth())
+	admin.POST("/whitelist/add", service.AddWhiteList)
+	admin.POST("/whitelist/remove", service.RemoveWhiteList)
+	admin.GET("/whitelist/list", service.ListWhiteList)
+	admin.GET("/signers", service.ListSigners)
+	admin.GET("/rpc", service.ListRpcEndpoints)
+	admin.POST("/paytoken/add", service.AddPayToken)
+	admin.POST("/paytoken/remove", service.RemovePayToken)
+	admin.GET("/relayer/pnl", service.ListRelayerPnL)
+	return r
+}
+
+// trustedProxies 可信反向代理，未配置时不信任任何代理，ClientIP为连接的对端IP
+func trustedProxies() []string {
+	if global.RateLimitConfig == nil {
+		return nil
+	}
+	return global.RateLimitConfig.TrustedProxies
+}
@@ -0,0 +1,144 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"nftmarket/config/setting"
+	"nftmarket/doc"
+	"nftmarket/global"
+	"nftmarket/internal/pow"
+	"nftmarket/service"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 限流器按IP全局保存，每个用例使用新的对端IP，-count大于1时互不影响
+var peerCounter atomic.Int32
+
+func nextPeer() string {
+	n := peerCounter.Add(1)
+	return fmt.Sprintf("10.1.%d.%d", n/250, n%250+1)
+}
+
+// setRateLimitConfig 替换限流配置，测试结束后恢复
+func setRateLimitConfig(t *testing.T, conf *setting.RateLimitConfig) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	previous := global.RateLimitConfig
+	global.RateLimitConfig = conf
+	t.Cleanup(func() { global.RateLimitConfig = previous })
+}
+
+// TestRoutesMatchOpenAPI 路由与doc/openapi.yaml不一致时失败，与启动时的检查相同
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	setRateLimitConfig(t, nil)
+	if err := doc.CheckRoutes(NewRouter().Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIPRateLimitForwardedFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted bool
+		want    []int
+	}{
+		// 对端不是可信代理时忽略X-Forwarded-For，按对端IP限流
+		{"spoofed header", false, []int{200, 429, 429, 429}},
+		// 来自可信代理的请求按X-Forwarded-For中的客户端IP限流
+		{"trusted proxy", true, []int{200, 200, 200, 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peer := nextPeer()
+			conf := &setting.RateLimitConfig{
+				Routes: []setting.RouteLimit{{Path: "/openapi.yaml", IPRate: 0.001, IPBurst: 1}},
+			}
+			if tt.trusted {
+				conf.TrustedProxies = []string{peer}
+			}
+			setRateLimitConfig(t, conf)
+			r := NewRouter()
+
+			var got []int
+			for range tt.want {
+				req := httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil)
+				req.RemoteAddr = peer + ":40000"
+				req.Header.Set("X-Forwarded-For", nextPeer())
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				got = append(got, w.Code)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("status codes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowDifficultyIgnoresForwardedFor(t *testing.T) {
+	setRateLimitConfig(t, &setting.RateLimitConfig{})
+	issuer, err := pow.NewIssuer([]byte("secret"), pow.Options{
+		BaseDifficulty: 1,
+		MaxDifficulty:  3,
+		Window:         time.Minute,
+		Threshold:      2,
+		Step:           1,
+		Store:          pow.NewMemoryStore(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.SetProofOfWork(issuer)
+	t.Cleanup(func() { service.SetProofOfWork(nil) })
+	r := NewRouter()
+
+	// 未要求API key时按IP区分调用方，每次请求更换X-Forwarded-For
+	send := func(peer, target, stamp string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = peer + ":40000"
+		req.Header.Set("X-Forwarded-For", nextPeer())
+		if stamp != "" {
+			req.Header.Set(pow.StampHeader, stamp)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	challenge := func(peer string) *pow.Challenge {
+		t.Helper()
+		w := send(peer, "/pow/challenge?resource=keypair", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("challenge status %d: %s", w.Code, w.Body)
+		}
+		var resp service.PowChallengeResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || !resp.Required {
+			t.Fatalf("challenge response %s: %v", w.Body, err)
+		}
+		return resp.Challenge
+	}
+
+	peer := nextPeer()
+	for i, want := range []int{1, 1, 2, 3} {
+		c := challenge(peer)
+		if c.Difficulty != want {
+			t.Fatalf("stamp %d difficulty = %d, want %d", i+1, c.Difficulty, want)
+		}
+		stamp, err := pow.Solve(context.Background(), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w := send(peer, "/keypair", stamp); w.Code != http.StatusOK {
+			t.Fatalf("keypair status %d: %s", w.Code, w.Body)
+		}
+	}
+	if c := challenge(nextPeer()); c.Difficulty != 1 {
+		t.Fatalf("other peer difficulty = %d, want 1", c.Difficulty)
+	}
+}
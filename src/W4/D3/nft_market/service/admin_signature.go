@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// 每记录多少次签名清理一次过期记录
+const adminSignaturePruneInterval = 256
+
+var adminSignatureUses atomic.Int64
+
+// ErrAdminSignatureUsed 管理员签名在有效期内已被使用过
+var ErrAdminSignatureUsed = errors.New("admin signature already used")
+
+// UseAdminSignature 记录已通过校验的管理员签名，digest已记录过时返回ErrAdminSignatureUsed
+// 记录保存在数据库中，多实例部署时共享
+func UseAdminSignature(digest string, expiresAt time.Time) error {
+	if adminSignatureUses.Add(1)%adminSignaturePruneInterval == 0 {
+		global.DBEngine.Where("expires_at < ?", time.Now().Unix()).Delete(&model.AdminSignature{})
+	}
+	record := &model.AdminSignature{Digest: digest, ExpiresAt: expiresAt.Unix()}
+	result := global.DBEngine.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAdminSignatureUsed
+	}
+	return nil
+}
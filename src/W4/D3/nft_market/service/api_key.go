@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/utils"
+	"time"
+)
+
+// CreateAPIKey 创建API key，明文key只在创建时返回一次
+func CreateAPIKey(name string) (string, *model.ApiKey, error) {
+	if name == "" {
+		return "", nil, errors.New("api key name is required")
+	}
+	key, err := utils.GenAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+	apiKey := model.ApiKey{
+		Name:      name,
+		KeyPrefix: key[:12],
+		KeyHash:   utils.HashAPIKey(key),
+		Status:    model.ApiKeyStatusActive,
+	}
+	if err := global.DBEngine.Create(&apiKey).Error; err != nil {
+		return "", nil, err
+	}
+	return key, &apiKey, nil
+}
+
+// RevokeAPIKey 吊销API key
+func RevokeAPIKey(id int64) error {
+	revokedAt := time.Now().Unix()
+	result := global.DBEngine.Model(&model.ApiKey{}).
+		Where("id = ? AND status = ?", id, model.ApiKeyStatusActive).
+		Updates(map[string]interface{}{"status": model.ApiKeyStatusRevoked, "revoked_at": revokedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("active api key not found")
+	}
+	return nil
+}
+
+// ListAPIKeys 展示所有API key
+func ListAPIKeys() ([]model.ApiKey, error) {
+	var keys []model.ApiKey
+	err := global.DBEngine.Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// AuthenticateAPIKey 按明文key查找有效的API key
+func AuthenticateAPIKey(key string) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	err := global.DBEngine.Where("key_hash = ? AND status = ?", utils.HashAPIKey(key), model.ApiKeyStatusActive).
+		First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"nftmarket/chain"
+	"nftmarket/contract"
+	"nftmarket/global"
+	"nftmarket/internal/auction"
+	"nftmarket/internal/model"
+	"nftmarket/internal/response"
+	"nftmarket/internal/revert"
+	"nftmarket/internal/validate"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// 避免手动结算接口与后台结算任务同时结算同一拍卖
+var settleMu sync.Mutex
+
+// PlaceBid 英式拍出价
+func PlaceBid(c *gin.Context) {
+	var input struct {
+		OrderId    int64  `json:"order_id" binding:"required,gt=0"`
+		Bidder     string `json:"bidder" binding:"required,eth_addr"`
+		Amount     string `json:"amount" binding:"required,positive_amount"`
+		RelayerFee string `json:"relayer_fee" binding:"omitempty,numeric"` // 中继费用策略非none时必填，不低于/market/bid/fee返回的费用
+		Signature  string `json:"signature" binding:"required,hexadecimal"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	amount, err := auction.ParseAmount(input.Amount)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	relayerFeeValue := new(big.Int)
+	if input.RelayerFee != "" {
+		if _, ok := relayerFeeValue.SetString(input.RelayerFee, 10); !ok || relayerFeeValue.Sign() < 0 {
+			response.Error(c, http.StatusBadRequest, "Invalid relayer_fee")
+			return
+		}
+	}
+
+	var order model.Order
+	if err := global.DBEngine.First(&order, input.OrderId).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Order not found")
+		return
+	}
+	if order.Status != model.OrderStatusOpen {
+		response.Error(c, http.StatusBadRequest, "Order is "+order.Status)
+		return
+	}
+
+	orderChain, err := global.Chains.Get(order.SellOrder.ChainId)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// 结算交易由平台钱包发送，出价时按当前策略确认中继费用，成交时从出价人转出出价金额加中继费用
+	fee, _, err := relayerFee(c.Request.Context(), orderChain, order.SellOrder.PayToken, amount)
+	if errors.Is(err, errNoTokenPrice) {
+		response.Error(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		response.Error(c, http.StatusBadGateway, "Failed to estimate gas cost")
+		return
+	}
+	if relayerFeeValue.Cmp(fee) < 0 {
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("relayer_fee must be at least %s, request one from /market/bid/fee", fee))
+		return
+	}
+
+	// 出价需由出价人签名，防止冒用他人地址出价；出价人为合约钱包时通过ERC-1271校验
+	bidder := common.HexToAddress(input.Bidder)
+	digest := accounts.TextHash([]byte(auction.BidMessage(order.OrderId, amount, relayerFeeValue)))
+	valid, err := orderChain.VerifySignature(c.Request.Context(), bidder, common.BytesToHash(digest), input.Signature)
+	if err != nil || !valid {
+		response.Error(c, http.StatusBadRequest, "Invalid bid signature")
+		return
+	}
+
+	highestBid, err := highestActiveBid(order.OrderId)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch bids")
+		return
+	}
+	var highestAmount *big.Int
+	if highestBid != nil {
+		highestAmount, _ = new(big.Int).SetString(highestBid.Amount, 10)
+	}
+	if err := auction.ValidateBid(order.SellOrder, amount, highestAmount, now()); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bid := model.Bid{
+		OrderId:    order.OrderId,
+		Bidder:     bidder.Hex(),
+		Amount:     amount.String(),
+		RelayerFee: relayerFeeValue.String(),
+		Signature:  input.Signature,
+		Status:     model.BidStatusActive,
+	}
+	if err := global.DBEngine.Create(&bid).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to save bid")
+		return
+	}
+	c.JSON(http.StatusOK, bid)
+}
+
+// BidFee 英式拍出价前获取中继费用和需要签名的出价消息
+func BidFee(c *gin.Context) {
+	var query struct {
+		OrderId int64  `form:"order_id" json:"order_id" binding:"required,gt=0"`
+		Amount  string `form:"amount" json:"amount" binding:"required,positive_amount"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	amount, err := auction.ParseAmount(query.Amount)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	var order model.Order
+	if err := global.DBEngine.First(&order, query.OrderId).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Order not found")
+		return
+	}
+	if order.SellOrder.OrderType != model.OrderTypeEnglish {
+		response.Error(c, http.StatusBadRequest, "order is not an english auction")
+		return
+	}
+	orderChain, err := global.Chains.Get(order.SellOrder.ChainId)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	fee, _, err := relayerFee(c.Request.Context(), orderChain, order.SellOrder.PayToken, amount)
+	if errors.Is(err, errNoTokenPrice) {
+		response.Error(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		response.Error(c, http.StatusBadGateway, "Failed to estimate gas cost")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"relayer_fee": fee.String(),
+		"fee_mode":    relayerFeePolicy.Mode,
+		"total":       new(big.Int).Add(amount, fee).String(),
+		"message":     auction.BidMessage(order.OrderId, amount, fee),
+	})
+}
+
+// ListBids 展示订单的出价记录
+func ListBids(c *gin.Context) {
+	var query struct {
+		OrderId int64 `form:"order_id" json:"order_id" binding:"required,gt=0"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	var bids []model.Bid
+	err := global.DBEngine.Where("order_id = ?", query.OrderId).Order("bid_id DESC").Find(&bids).Error
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch bids")
+		return
+	}
+	c.JSON(http.StatusOK, bids)
+}
+
+// SettleAuction 英式拍结束后结算最高的有效出价
+func SettleAuction(c *gin.Context) {
+	var input struct {
+		OrderId int64 `json:"order_id" binding:"required,gt=0"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	var order model.Order
+	if err := global.DBEngine.First(&order, input.OrderId).Error; err != nil {
+		response.Error(c, http.StatusNotFound, "Order not found")
+		return
+	}
+	if err := settleEnglishAuction(&order); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// 结算交易已广播，由发件箱任务完成成交
+	c.JSON(http.StatusAccepted, order)
+}
+
+// RunAuctionSettler 定时结算已结束的英式拍
+func RunAuctionSettler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var orders []model.Order
+		err := global.DBEngine.Where("order_type = ? AND status = ? AND end_time <= ?",
+			model.OrderTypeEnglish, model.OrderStatusOpen, now().Unix()).Find(&orders).Error
+		if err != nil {
+			log.Printf("auction settler: failed to fetch auctions: %v", err)
+			continue
+		}
+		for i := range orders {
+			if err := settleEnglishAuction(&orders[i]); err != nil {
+				log.Printf("auction settler: order %d: %v", orders[i].OrderId, err)
+			}
+		}
+	}
+}
+
+// settleEnglishAuction 按出价从高到低依次校验，成交第一个有效出价
+func settleEnglishAuction(order *model.Order) error {
+	settleMu.Lock()
+	defer settleMu.Unlock()
+	// 加锁后重新读取，避免重复结算
+	if err := global.DBEngine.First(order, order.OrderId).Error; err != nil {
+		return err
+	}
+	if order.SellOrder.OrderType != model.OrderTypeEnglish {
+		return errors.New("order is not an english auction")
+	}
+	if order.Status != model.OrderStatusOpen {
+		return fmt.Errorf("order is %s", order.Status)
+	}
+	if now().Unix() < order.SellOrder.EndTime {
+		return errors.New("auction has not ended")
+	}
+	if now().Unix() > order.SellOrder.Deadline {
+		return errors.New("order deadline exceeded")
+	}
+	valid, err := verifySellOrderSignature(order.SellOrder, order.Signature, order.SellerPubKey)
+	if err != nil || !valid {
+		return errors.New("invalid signature")
+	}
+	orderChain, err := global.Chains.Get(order.SellOrder.ChainId)
+	if err != nil {
+		return err
+	}
+	if order.SellerSignature != "" {
+		if err := verifySellerSignature(orderChain, order.SellOrder, order.SellerSignature); err != nil {
+			return err
+		}
+	}
+
+	var bids []model.Bid
+	if err := global.DBEngine.Where("order_id = ? AND status = ?", order.OrderId, model.BidStatusActive).Find(&bids).Error; err != nil {
+		return err
+	}
+	sortBidsDesc(bids)
+	for i := range bids {
+		amount, _ := new(big.Int).SetString(bids[i].Amount, 10)
+		fee, ok := new(big.Int).SetString(bids[i].RelayerFee, 10)
+		if !ok {
+			fee = new(big.Int)
+		}
+		if err := checkBuyerFunds(orderChain, order.SellOrder.PayToken, bids[i].Bidder, new(big.Int).Add(amount, fee)); err != nil {
+			log.Printf("bid %d of order %d is invalid: %v", bids[i].BidId, order.OrderId, err)
+			bids[i].Status = model.BidStatusInvalid
+			global.DBEngine.Save(&bids[i])
+			continue
+		}
+		quote, err := bidQuote(orderChain, order, &bids[i], amount, fee)
+		if err != nil {
+			return err
+		}
+
+		// 成交后出价在发件箱完成时标记为won
+		entry, err := callBuyNFTForOffline(orderChain, bids[i].Bidder, order, amount, 1, nil, quote, bids[i].BidId)
+		var reverted *revert.Error
+		if errors.As(err, &reverted) {
+			// 模拟执行revert(如出价人在校验后撤销了授权)，跳过该出价继续结算次高出价；上链后执行失败的出价由发件箱任务标记
+			log.Printf("bid %d of order %d is invalid: %v", bids[i].BidId, order.OrderId, err)
+			bids[i].Status = model.BidStatusInvalid
+			global.DBEngine.Save(&bids[i])
+			continue
+		}
+		// 节点、数据库错误不归咎于出价，下次结算时重试
+		if err != nil {
+			return fmt.Errorf("failed to buy NFT: %w", err)
+		}
+		if err := global.DBEngine.First(order, order.OrderId).Error; err != nil {
+			return err
+		}
+		order.OutboxId = entry.Id
+		return nil
+	}
+	return errors.New("no valid bid")
+}
+
+// bidQuote 按出价人签名确认的中继费用生成报价记录，与购买报价一样由发件箱记录费用并标记已使用，费用为0时返回nil
+func bidQuote(orderChain *chain.Chain, order *model.Order, bid *model.Bid, amount, fee *big.Int) (*model.RelayerQuote, error) {
+	if fee.Sign() == 0 {
+		return nil, nil
+	}
+	quote := &model.RelayerQuote{
+		ChainId:    orderChain.ID,
+		OrderId:    order.OrderId,
+		Buyer:      bid.Bidder,
+		Amount:     1,
+		Price:      amount.String(),
+		RelayerFee: fee.String(),
+		FeeMode:    relayerFeePolicy.Mode,
+		ExpiresAt:  now().Add(relayerFeePolicy.QuoteTTL).Unix(),
+	}
+	if err := global.DBEngine.Create(quote).Error; err != nil {
+		return nil, fmt.Errorf("failed to create relayer quote for bid %d: %w", bid.BidId, err)
+	}
+	return quote, nil
+}
+
+// highestActiveBid 查询订单当前最高的有效出价，无出价返回nil
+func highestActiveBid(orderId int64) (*model.Bid, error) {
+	var bids []model.Bid
+	if err := global.DBEngine.Where("order_id = ? AND status = ?", orderId, model.BidStatusActive).Find(&bids).Error; err != nil {
+		return nil, err
+	}
+	if len(bids) == 0 {
+		return nil, nil
+	}
+	// 金额以字符串存储，不能直接在SQL中排序
+	sortBidsDesc(bids)
+	return &bids[0], nil
+}
+
+// sortBidsDesc 按出价金额从高到低排序，金额相同时先出价者优先
+func sortBidsDesc(bids []model.Bid) {
+	amounts := make(map[int64]*big.Int, len(bids))
+	for _, bid := range bids {
+		amounts[bid.BidId], _ = new(big.Int).SetString(bid.Amount, 10)
+	}
+	sort.Slice(bids, func(i, j int) bool {
+		if cmp := amounts[bids[i].BidId].Cmp(amounts[bids[j].BidId]); cmp != 0 {
+			return cmp > 0
+		}
+		return bids[i].BidId < bids[j].BidId
+	})
+}
+
+// checkBuyerFunds 校验买家ERC20余额和对市场合约的授权是否足够
+func checkBuyerFunds(orderChain *chain.Chain, payToken string, buyer string, amount *big.Int) error {
+	token := common.HexToAddress(payToken)
+	ethFlag, err := orderChain.Market.ETHFLAG(nil)
+	if err != nil {
+		return err
+	}
+	// ETH支付时合约从买家转入WETH后解包，校验买家的WETH余额和授权
+	if token == ethFlag {
+		if token, err = orderChain.Market.WETH(nil); err != nil {
+			return err
+		}
+	}
+	erc20, err := abi.JSON(strings.NewReader(contract.ERC20ABI))
+	if err != nil {
+		return err
+	}
+	bound := bind.NewBoundContract(token, erc20, orderChain.Client, orderChain.Client, orderChain.Client)
+	buyerAddress := common.HexToAddress(buyer)
+
+	var balance []interface{}
+	if err := bound.Call(nil, &balance, "balanceOf", buyerAddress); err != nil {
+		return err
+	}
+	if balance[0].(*big.Int).Cmp(amount) < 0 {
+		return errors.New("insufficient balance")
+	}
+	var allowance []interface{}
+	if err := bound.Call(nil, &allowance, "allowance", buyerAddress, orderChain.MarketAddress); err != nil {
+		return err
+	}
+	if allowance[0].(*big.Int).Cmp(amount) < 0 {
+		return errors.New("insufficient allowance")
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/auction"
+	"nftmarket/internal/model"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+)
+
+const testChainId = 31337
+
+// setClock 替换service的当前时间，返回可修改的时间，测试结束后恢复
+func setClock(t *testing.T, start time.Time) *time.Time {
+	t.Helper()
+	current := start
+	previous := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = previous })
+	return &current
+}
+
+// setTestChains 使用不连接节点的单链注册表，EOA签名通过ecrecover校验
+func setTestChains(t *testing.T) {
+	t.Helper()
+	registry, err := chain.NewRegistry([]*chain.Chain{{ID: testChainId}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := global.Chains
+	global.Chains = registry
+	t.Cleanup(func() { global.Chains = previous })
+}
+
+// createEnglishAuction 保存一个卖家签名有效的英式拍订单，时间相对于start
+func createEnglishAuction(t *testing.T, start time.Time) *model.Order {
+	t.Helper()
+	privateKey, publicKey, err := utils.GenKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sellOrder := model.SellOrder{
+		Seller:     "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		Nft:        "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		TokenId:    1,
+		PayToken:   validate.ETHFlag.Hex(),
+		OrderType:  model.OrderTypeEnglish,
+		StartPrice: "1000",
+		StartTime:  start.Unix(),
+		EndTime:    start.Add(100 * time.Second).Unix(),
+		Deadline:   start.Add(200 * time.Second).Unix(),
+		ChainId:    testChainId,
+	}
+	orderJson, err := json.Marshal(sellOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := utils.Sign(string(orderJson), privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := &model.Order{SellOrder: sellOrder, SellerPubKey: publicKey, Signature: signature, Status: model.OrderStatusOpen, Remaining: 1}
+	if err := global.DBEngine.Create(order).Error; err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func newAuctionRouter() *gin.Engine {
+	r := gin.New()
+	r.POST("/market/bid", PlaceBid)
+	r.GET("/market/bids", ListBids)
+	r.POST("/market/settle", SettleAuction)
+	return r
+}
+
+func serve(r *gin.Engine, method, target string, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuctionTimingUsesServiceClock(t *testing.T) {
+	setupTestDB(t, &model.Order{}, &model.Bid{})
+	setTestChains(t)
+	start := time.Unix(1700000000, 0)
+	clock := setClock(t, start.Add(-time.Second))
+	order := createEnglishAuction(t, start)
+	unbidOrder := createEnglishAuction(t, start)
+	r := newAuctionRouter()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bidder := crypto.PubkeyToAddress(key.PublicKey)
+	bid := func(amount int64) *httptest.ResponseRecorder {
+		value := big.NewInt(amount)
+		digest := accounts.TextHash([]byte(auction.BidMessage(order.OrderId, value, new(big.Int))))
+		signature, err := crypto.Sign(digest, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature[crypto.RecoveryIDOffset] += 27
+		return serve(r, http.MethodPost, "/market/bid", gin.H{
+			"order_id":  order.OrderId,
+			"bidder":    bidder.Hex(),
+			"amount":    value.String(),
+			"signature": hexutil.Encode(signature),
+		})
+	}
+	settle := func(orderId int64) *httptest.ResponseRecorder {
+		return serve(r, http.MethodPost, "/market/settle", gin.H{"order_id": orderId})
+	}
+	expect := func(w *httptest.ResponseRecorder, status int, message string) {
+		t.Helper()
+		if w.Code != status || !strings.Contains(w.Body.String(), message) {
+			t.Fatalf("status %d body %s, want %d containing %q", w.Code, w.Body, status, message)
+		}
+	}
+
+	expect(bid(1000), http.StatusBadRequest, "auction has not started")
+	*clock = start
+	expect(bid(1000), http.StatusOK, `"amount":"1000"`)
+	*clock = start.Add(50 * time.Second)
+	expect(bid(1500), http.StatusOK, `"amount":"1500"`)
+	expect(settle(order.OrderId), http.StatusBadRequest, "auction has not ended")
+
+	// 到达end_time后不再接受出价，可以结算
+	*clock = start.Add(100 * time.Second)
+	expect(bid(2000), http.StatusBadRequest, "auction has ended")
+	expect(settle(unbidOrder.OrderId), http.StatusBadRequest, "no valid bid")
+
+	// 超过deadline后不再结算
+	*clock = start.Add(201 * time.Second)
+	expect(settle(order.OrderId), http.StatusBadRequest, "order deadline exceeded")
+
+	w := serve(r, http.MethodGet, "/market/bids?order_id="+strconv.FormatInt(order.OrderId, 10), nil)
+	var bids []model.Bid
+	if err := json.Unmarshal(w.Body.Bytes(), &bids); err != nil {
+		t.Fatalf("bids %s: %v", w.Body, err)
+	}
+	if len(bids) != 2 || bids[0].Amount != "1500" || bids[1].Amount != "1000" {
+		t.Fatalf("bids = %+v", bids)
+	}
+}
+
+func TestListBidsValidatesOrderId(t *testing.T) {
+	setupTestDB(t, &model.Bid{})
+	r := newAuctionRouter()
+	for _, target := range []string{"/market/bids", "/market/bids?order_id=", "/market/bids?order_id=abc", "/market/bids?order_id=0", "/market/bids?order_id=-1"} {
+		if w := serve(r, http.MethodGet, target, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status %d, want 400", target, w.Code)
+		}
+	}
+	if w := serve(r, http.MethodGet, "/market/bids?order_id=7", nil); w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Fatalf("GET order without bids: status %d body %s", w.Code, w.Body)
+	}
+}
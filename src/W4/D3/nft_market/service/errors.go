@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"nftmarket/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error 业务错误，Status为对应的http状态码，REST和gRPC接口分别转换为各自的错误返回
+type Error struct {
+	Status  int
+	Message string
+	Invalid bool // 请求参数不合法，REST接口返回invalid_request
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func statusError(status int, message string) *Error {
+	return &Error{Status: status, Message: message}
+}
+
+func invalidError(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Message: message, Invalid: true}
+}
+
+// writeError 按业务错误的状态码返回错误信息，其他错误返回500
+func writeError(c *gin.Context, err error) {
+	var serviceErr *Error
+	if !errors.As(err, &serviceErr) {
+		response.Error(c, http.StatusInternalServerError, "Internal error")
+		return
+	}
+	if serviceErr.Invalid {
+		response.Invalid(c, serviceErr.Message)
+		return
+	}
+	response.Error(c, serviceErr.Status, serviceErr.Message)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// 导出时每批从数据库读取的成交记录数，按(block_timestamp, fill_id)游标分页，内存占用与总行数无关
+const exportBatchSize = 1000
+
+// FiatPrice 支付代币的法币价格，Price为每个完整代币(按decimals换算后)的价格，自From(unix秒)起生效
+type FiatPrice struct {
+	Currency string
+	ChainId  int64 // 为0时适用于所有链
+	PayToken common.Address
+	Price    *big.Rat
+	From     int64
+}
+
+// FiatPriceTable 法币价格表，同一币种和代币按生效时间取成交时最新的价格
+type FiatPriceTable []FiatPrice
+
+var fiatPrices FiatPriceTable
+
+// SetFiatPrices 设置导出使用的法币价格表，在启动服务前调用
+// 成交记录中ETH支付的代币为ETH_FLAG，价格表中以零地址表示的ETH按ETH_FLAG匹配
+func SetFiatPrices(table FiatPriceTable) {
+	sorted := append(FiatPriceTable(nil), table...)
+	for i := range sorted {
+		if sorted[i].PayToken == (common.Address{}) {
+			sorted[i].PayToken = validate.ETHFlag
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].From > sorted[j].From })
+	fiatPrices = sorted
+}
+
+// hasCurrency 价格表中是否有该币种
+func (t FiatPriceTable) hasCurrency(currency string) bool {
+	for _, price := range t {
+		if strings.EqualFold(price.Currency, currency) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup 成交时生效的价格，指定链的价格优先于适用所有链的价格，没有时返回nil
+func (t FiatPriceTable) lookup(currency string, chainId int64, payToken string, at int64) *big.Rat {
+	token := common.HexToAddress(payToken)
+	var fallback *big.Rat
+	for _, price := range t {
+		if !strings.EqualFold(price.Currency, currency) || price.PayToken != token || price.From > at {
+			continue
+		}
+		if price.ChainId == chainId {
+			return price.Price
+		}
+		if price.ChainId == 0 && fallback == nil {
+			fallback = price.Price
+		}
+	}
+	return fallback
+}
+
+// ExportRow 导出的一行成交记录，字段顺序即CSV列顺序
+type ExportRow struct {
+	FillId         int64  `json:"fill_id"`
+	ChainId        int64  `json:"chain_id"`
+	OrderId        int64  `json:"order_id"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	BlockTime      string `json:"block_time"` // RFC3339(UTC)
+	TxHash         string `json:"tx_hash"`
+	Collection     string `json:"collection"`
+	TokenId        int64  `json:"token_id"`
+	TokenStandard  string `json:"token_standard"`
+	Amount         int64  `json:"amount"`
+	Seller         string `json:"seller"`
+	Buyer          string `json:"buyer"`
+	PayToken       string `json:"pay_token"`
+	Symbol         string `json:"symbol"`
+	Price          string `json:"price"`         // 成交总价(最小单位)
+	PriceDisplay   string `json:"price_display"` // 按精度换算的成交总价，支付代币未登记时为空
+	FiatCurrency   string `json:"fiat_currency,omitempty"`
+	FiatValue      string `json:"fiat_value,omitempty"` // 价格表中没有该代币时为空
+}
+
+var exportColumns = []string{"fill_id", "chain_id", "order_id", "block_timestamp", "block_time", "tx_hash", "collection", "token_id",
+	"token_standard", "amount", "seller", "buyer", "pay_token", "symbol", "price", "price_display", "fiat_currency", "fiat_value"}
+
+func (r ExportRow) record() []string {
+	return []string{strconv.FormatInt(r.FillId, 10), strconv.FormatInt(r.ChainId, 10), strconv.FormatInt(r.OrderId, 10),
+		strconv.FormatInt(r.BlockTimestamp, 10), r.BlockTime, r.TxHash, r.Collection, strconv.FormatInt(r.TokenId, 10),
+		r.TokenStandard, strconv.FormatInt(r.Amount, 10), r.Seller, r.Buyer, r.PayToken, r.Symbol, r.Price, r.PriceDisplay,
+		r.FiatCurrency, r.FiatValue}
+}
+
+// ExportTrades 按区块时间范围流式导出成交记录(CSV或JSON Lines)，按时间和fill_id升序
+// 分批按游标读取，边读边写；导出中途失败时已写出的内容无法撤回，结果通过X-Export-Status trailer返回
+func ExportTrades(c *gin.Context) {
+	var query struct {
+		From     int64  `form:"from" json:"from" binding:"required,gt=0"`     // 区块时间下限(unix秒，含)
+		To       int64  `form:"to" json:"to" binding:"required,gtfield=From"` // 区块时间上限(unix秒，不含)
+		Format   string `form:"format" json:"format" binding:"omitempty,oneof=csv jsonl"`
+		Fiat     string `form:"fiat" json:"fiat"` // 法币币种，需在FiatPrices中配置
+		Nft      string `form:"nft" json:"nft" binding:"omitempty,eth_addr"`
+		PayToken string `form:"pay_token" json:"pay_token" binding:"omitempty,eth_addr"`
+		After    string `form:"after" json:"after"` // 从该游标之后继续导出，格式为{block_timestamp}:{fill_id}，即上次导出的最后一行
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	exportChain, ok := queryChain(c)
+	if !ok {
+		return
+	}
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+	if query.Fiat != "" && !fiatPrices.hasCurrency(query.Fiat) {
+		writeError(c, invalidError(fmt.Sprintf("fiat currency %q is not configured", query.Fiat)))
+		return
+	}
+	var cursor struct{ timestamp, fillId int64 }
+	hasCursor := query.After != ""
+	if hasCursor {
+		timestamp, fillId, found := strings.Cut(query.After, ":")
+		var err1, err2 error
+		cursor.timestamp, err1 = strconv.ParseInt(timestamp, 10, 64)
+		cursor.fillId, err2 = strconv.ParseInt(fillId, 10, 64)
+		if !found || err1 != nil || err2 != nil {
+			writeError(c, invalidError("after must be {block_timestamp}:{fill_id}"))
+			return
+		}
+	}
+	lookup, err := payTokenLookup()
+	if err != nil {
+		writeError(c, statusError(http.StatusInternalServerError, "Failed to fetch pay tokens"))
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if query.Format == "jsonl" {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="trades-%d-%d.%s"`, query.From, query.To, query.Format))
+	c.Header("Trailer", model.ExportStatusTrailer)
+	c.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+	if query.Format == "csv" {
+		csvWriter.Write(exportColumns)
+	}
+	status := model.ExportStatusComplete
+	for {
+		tx := trades(global.DBEngine).Where("block_timestamp >= ? AND block_timestamp < ?", query.From, query.To)
+		if exportChain != nil {
+			tx = tx.Scopes(exportChain.Scope)
+		}
+		if query.Nft != "" {
+			tx = tx.Where("nft = ?", query.Nft)
+		}
+		if query.PayToken != "" {
+			tx = tx.Where("pay_token = ?", query.PayToken)
+		}
+		if hasCursor {
+			tx = tx.Where("(block_timestamp, fill_id) > (?, ?)", cursor.timestamp, cursor.fillId)
+		}
+		var batch []model.Trade
+		if err := tx.Order("block_timestamp, fill_id").Limit(exportBatchSize).Scan(&batch).Error; err != nil {
+			log.Printf("export trades: %v", err)
+			status = "error: failed to fetch trades"
+			break
+		}
+		for i := range batch {
+			normalizeTrade(&batch[i])
+			row := exportRow(batch[i], lookup(batch[i].ChainId, batch[i].PayToken), query.Fiat)
+			if query.Format == "csv" {
+				err = csvWriter.Write(row.record())
+			} else {
+				err = encoder.Encode(row)
+			}
+			if err != nil {
+				break
+			}
+		}
+		if query.Format == "csv" {
+			csvWriter.Flush()
+			err = csvWriter.Error()
+		}
+		if err != nil {
+			// 客户端断开
+			log.Printf("export trades: %v", err)
+			return
+		}
+		c.Writer.Flush()
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor.timestamp, cursor.fillId = last.BlockTimestamp, last.FillId
+		hasCursor = true
+	}
+	c.Writer.Header().Set(model.ExportStatusTrailer, status)
+}
+
+// exportRow 组装导出行，token为nil时不换算价格，fiat为空时不计算法币价值
+func exportRow(trade model.Trade, token *model.PayToken, fiat string) ExportRow {
+	row := ExportRow{
+		FillId:         trade.FillId,
+		ChainId:        trade.ChainId,
+		OrderId:        trade.OrderId,
+		BlockTimestamp: trade.BlockTimestamp,
+		BlockTime:      time.Unix(trade.BlockTimestamp, 0).UTC().Format(time.RFC3339),
+		TxHash:         trade.TxHash,
+		Collection:     trade.Nft,
+		TokenId:        trade.TokenId,
+		TokenStandard:  trade.TokenStandard,
+		Amount:         trade.Amount,
+		Seller:         trade.Seller,
+		Buyer:          trade.Buyer,
+		PayToken:       trade.PayToken,
+		Price:          trade.Price,
+	}
+	if token == nil {
+		return row
+	}
+	row.Symbol = token.Symbol
+	price, ok := new(big.Int).SetString(trade.Price, 10)
+	if !ok {
+		return row
+	}
+	row.PriceDisplay = utils.FormatUnits(price, token.Decimals)
+	if fiat == "" {
+		return row
+	}
+	row.FiatCurrency = strings.ToUpper(fiat)
+	if unitPrice := fiatPrices.lookup(fiat, trade.ChainId, trade.PayToken, trade.BlockTimestamp); unitPrice != nil {
+		// 成交总价 / 10^decimals * 单价，保留2位小数
+		value := new(big.Rat).SetFrac(price, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.Decimals)), nil))
+		row.FiatValue = value.Mul(value, unitPrice).FloatString(2)
+	}
+	return row
+}
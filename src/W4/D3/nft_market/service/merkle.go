@@ -0,0 +1,169 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"nftmarket/global"
+	"nftmarket/internal/merkle"
+	"nftmarket/internal/model"
+	"nftmarket/internal/response"
+	"nftmarket/internal/validate"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 白名单CSV/JSON请求体的最大长度
+const maxMerkleBodySize = 8 << 20
+
+// CreateMerkleTree 由白名单地址构建默克尔树，请求体为JSON地址列表，或Content-Type为text/csv、text/plain的地址列表/CSV
+func CreateMerkleTree(c *gin.Context) {
+	var addresses []common.Address
+	contentType := c.ContentType()
+	if contentType == "text/csv" || contentType == "text/plain" {
+		var err error
+		addresses, err = merkle.ParseAddresses(http.MaxBytesReader(c.Writer, c.Request.Body, maxMerkleBodySize))
+		if err != nil {
+			response.Invalid(c, err.Error())
+			return
+		}
+	} else {
+		var input struct {
+			Addresses []string `json:"addresses" binding:"required,min=1,dive,eth_addr"`
+		}
+		if !validate.BindJSON(c, &input) {
+			return
+		}
+		for _, address := range input.Addresses {
+			addresses = append(addresses, common.HexToAddress(address))
+		}
+	}
+	tree, err := merkle.Build(addresses)
+	if err != nil {
+		response.Invalid(c, err.Error())
+		return
+	}
+	record, err := SaveMerkleTree(tree)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to save merkle tree")
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// ImportMerkleTree 导入OpenZeppelin StandardMerkleTree.dump()格式的默克尔树
+func ImportMerkleTree(c *gin.Context) {
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxMerkleBodySize))
+	if err != nil {
+		response.Invalid(c, "Failed to read request body")
+		return
+	}
+	tree, err := merkle.Load(data)
+	if err != nil {
+		response.Invalid(c, err.Error())
+		return
+	}
+	record, err := SaveMerkleTree(tree)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to save merkle tree")
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// ExportMerkleTree 导出StandardMerkleTree格式的默克尔树，可直接用@openzeppelin/merkle-tree的StandardMerkleTree.load加载
+func ExportMerkleTree(c *gin.Context) {
+	tree, err := LoadMerkleTree(c.Param("root"))
+	if err != nil {
+		merkleTreeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetMerkleProof 查询地址在白名单默克尔树中的证明
+func GetMerkleProof(c *gin.Context) {
+	var query struct {
+		Address string `form:"address" json:"address" binding:"required,eth_addr"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	tree, err := LoadMerkleTree(c.Param("root"))
+	if err != nil {
+		merkleTreeError(c, err)
+		return
+	}
+	proof, err := tree.Proof(common.HexToAddress(query.Address))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	hexProof := make([]string, len(proof))
+	for i, node := range proof {
+		hexProof[i] = node.Hex()
+	}
+	c.JSON(http.StatusOK, gin.H{"root": tree.Root().Hex(), "address": query.Address, "proof": hexProof})
+}
+
+// SaveMerkleTree 保存默克尔树，相同的树根只保存一次
+func SaveMerkleTree(tree *merkle.Tree) (*model.MerkleTree, error) {
+	dump, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	record := &model.MerkleTree{Root: tree.Root().Hex(), Size: len(tree.Values), Dump: string(dump)}
+	if err := global.DBEngine.Where(model.MerkleTree{Root: record.Root}).FirstOrCreate(record).Error; err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// LoadMerkleTree 按树根读取默克尔树
+func LoadMerkleTree(root string) (*merkle.Tree, error) {
+	var record model.MerkleTree
+	if err := global.DBEngine.First(&record, "root = ?", normalizeRoot(root)).Error; err != nil {
+		return nil, err
+	}
+	return merkle.Load([]byte(record.Dump))
+}
+
+// verifyDiscountProof 校验买家的白名单证明，通过时返回订单的折扣价
+func verifyDiscountProof(order model.SellOrder, buyer string, proof []string) (string, error) {
+	if order.MerkleRoot == "" {
+		return "", errors.New("order has no whitelist discount")
+	}
+	nodes := make([]common.Hash, len(proof))
+	for i, node := range proof {
+		nodes[i] = common.HexToHash(node)
+	}
+	if !merkle.Verify(common.HexToHash(order.MerkleRoot), common.HexToAddress(buyer), nodes) {
+		return "", fmt.Errorf("buyer %s is not in the whitelist of this order", buyer)
+	}
+	return order.DiscountPrice, nil
+}
+
+func merkleTreeError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Error(c, http.StatusNotFound, "Merkle tree not found")
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, "Failed to load merkle tree")
+}
+
+// normalizeRoot 统一树根格式为小写0x前缀
+func normalizeRoot(root string) string {
+	return common.HexToHash(strings.TrimSpace(root)).Hex()
+}
+
+// merkleTreeExists 上架时校验树根已创建或导入，保证买家可以查询证明
+func merkleTreeExists(root string) (bool, error) {
+	var count int64
+	err := global.DBEngine.Model(&model.MerkleTree{}).Where("root = ?", normalizeRoot(root)).Count(&count).Error
+	return count > 0, err
+}
@@ -7,26 +7,65 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
-	"nftmarket/config"
+	"nftmarket/chain"
+	"nftmarket/contract"
 	"nftmarket/global"
+	"nftmarket/internal/auction"
+	"nftmarket/internal/erc165"
+	"nftmarket/internal/events"
 	"nftmarket/internal/model"
+	"nftmarket/internal/permit"
+	"nftmarket/internal/response"
+	"nftmarket/internal/revert"
+	"nftmarket/internal/validate"
 	"nftmarket/utils"
-	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// now 当前时间，拍卖定价与校验均通过它获取时间，测试时可替换
+var now auction.Clock = time.Now
+
 // CreateOrder 离线上架NFT
 func CreateOrder(c *gin.Context) {
 	var request model.SellOrderRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validate.BindJSON(c, &request) {
+		return
+	}
+	order, err := CreateSellOrder(request)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, order)
+}
+
+// CreateSellOrder 校验并保存卖单，REST和gRPC接口共用，request需已通过binding标签校验
+func CreateSellOrder(request model.SellOrderRequest) (*model.Order, error) {
+	// 订单绑定所在链，chain_id纳入签名
+	orderChain, err := global.Chains.Get(request.ChainId)
+	if err != nil {
+		return nil, invalidError(err.Error())
+	}
+	// 支付代币需在订单所在链上登记，按代币单位填写的价格按其精度换算
+	payToken, err := findPayToken(orderChain.ID, request.PayToken)
+	if err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to fetch pay token")
+	}
+	if payToken == nil {
+		return nil, invalidError(fmt.Sprintf("pay_token %s is not registered on chain %d", request.PayToken, orderChain.ID))
+	}
+	if err := resolveHumanPrices(&request, payToken); err != nil {
+		return nil, invalidError(err.Error())
+	}
+
 	sellOrder := model.SellOrder{
 		Seller:   request.Seller,
 		Nft:      request.NFT,
@@ -34,106 +73,396 @@ func CreateOrder(c *gin.Context) {
 		PayToken: request.PayToken,
 		Price:    request.Price,
 		Deadline: request.Deadline,
+		ChainId:  orderChain.ID,
 	}
-	fmt.Printf("sellorder: %+v\n", sellOrder)
-	// 对SellOrder进行哈希并签名
-	signature, err := signSellOrder(sellOrder, request.PrivateKey)
+	if request.OrderType != "" && request.OrderType != model.OrderTypeFixed {
+		sellOrder.OrderType = request.OrderType
+		sellOrder.StartPrice = request.StartPrice
+		sellOrder.EndPrice = request.EndPrice
+		sellOrder.StartTime = request.StartTime
+		sellOrder.EndTime = request.EndTime
+	}
+	if request.MerkleRoot != "" {
+		exists, err := merkleTreeExists(request.MerkleRoot)
+		if err != nil {
+			return nil, statusError(http.StatusInternalServerError, "Failed to fetch merkle tree")
+		}
+		if !exists {
+			return nil, invalidError("merkle_root not found, create or import the merkle tree first")
+		}
+		sellOrder.MerkleRoot = normalizeRoot(request.MerkleRoot)
+		sellOrder.DiscountPrice = request.DiscountPrice
+	}
+	// ERC1155订单的标准和数量纳入签名，ERC721订单保持为空值
+	standard, err := resolveTokenStandard(orderChain, request.NFT, request.TokenStandard)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign sell order"})
-		return
+		return nil, err
+	}
+	if standard == model.TokenStandardERC1155 {
+		sellOrder.TokenStandard = standard
+	}
+	sellOrder.Amount = request.Amount
+	if err := auction.Validate(sellOrder, now()); err != nil {
+		return nil, invalidError(err.Error())
+	}
+	if request.SellerSignature != "" {
+		if err := verifySellerSignature(orderChain, sellOrder, request.SellerSignature); err != nil {
+			return nil, invalidError(err.Error())
+		}
+	}
+	// 公钥统一按PEM保存，旧格式、JWK和SEC1点在此转换
+	sellerPubKey, err := canonicalPublicKey(request.PublicKey)
+	if err != nil {
+		return nil, invalidError("invalid public key: " + err.Error())
+	}
+	// 客户端已在本地签名时只验签，否则用提交的私钥对SellOrder进行哈希并签名
+	signature := request.Signature
+	if signature != "" {
+		if valid, err := verifySellOrderSignature(sellOrder, signature, request.PublicKey); err != nil || !valid {
+			return nil, invalidError("invalid order signature, the signed sell order must include the resolved chain_id")
+		}
+	} else {
+		signature, err = signSellOrder(sellOrder, request.PrivateKey)
+		if err != nil {
+			return nil, statusError(http.StatusInternalServerError, "Failed to sign sell order")
+		}
 	}
 
 	// 创建新的Order记录
 	order := model.Order{
-		SellOrder:      sellOrder,
-		SellerPubKey:   request.PublicKey,
-		Signature:      signature,
-		FilledTxHash:   nil,
-		BlockNumber:    nil,
-		BlockTimestamp: nil,
+		SellOrder:       sellOrder,
+		SellerPubKey:    sellerPubKey,
+		Signature:       signature,
+		SellerSignature: request.SellerSignature,
+		FilledTxHash:    nil,
+		BlockNumber:     nil,
+		BlockTimestamp:  nil,
+		Status:          model.OrderStatusOpen,
+		Remaining:       1,
+	}
+	if sellOrder.IsERC1155() {
+		order.Remaining = sellOrder.Amount
 	}
 
-	fmt.Printf("order: %+v\n", order)
 	// 将订单存入数据库
 	if err := global.DBEngine.Create(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save order"})
-		return
+		return nil, statusError(http.StatusInternalServerError, "Failed to save order")
 	}
-
-	c.JSON(http.StatusOK, order)
+	events.PublishOrders(global.DBEngine, events.OrderCreated, order.OrderId)
+	fillPriceDisplay(&order, payToken)
+	return &order, nil
 }
 
 // ListSellOrders 展示上架订单信息
 func ListSellOrders(c *gin.Context) {
+	// 指定chain_id时只展示该链的订单
+	orderChain, ok := queryChain(c)
+	if !ok {
+		return
+	}
+	orders, err := OpenSellOrders(orderChain)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// OpenSellOrders 查询上架中的订单并计算当前价格，orderChain为nil时返回所有链的订单
+func OpenSellOrders(orderChain *chain.Chain) ([]model.Order, error) {
+	tx := global.DBEngine.Where("filled_tx_hash IS NULL AND status = ?", model.OrderStatusOpen)
+	if orderChain != nil {
+		tx = tx.Scopes(orderChain.Scope)
+	}
 	var orders []model.Order
-	result := global.DBEngine.Where("filled_tx_hash IS NULL").Find(&orders)
 	// 查询未成交的订单
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
+	if err := tx.Find(&orders).Error; err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to fetch orders")
+	}
+
+	// 计算当前价格，荷兰拍按当前时间计算，英式拍为最高出价
+	current := now()
+	for i := range orders {
+		if err := fillCurrentPrice(&orders[i], current); err != nil {
+			return nil, statusError(http.StatusInternalServerError, "Failed to calculate price")
+		}
+	}
+	if err := describeOrders(orders); err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to fetch pay tokens")
+	}
+	return orders, nil
+}
+
+// GetOrder 查询单个订单，上架中的订单同时返回当前价格
+func GetOrder(c *gin.Context) {
+	var uri struct {
+		OrderId int64 `uri:"id" binding:"required,gt=0"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.Invalid(c, "invalid order id")
+		return
+	}
+	order, err := FindOrder(uri.OrderId)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, order)
+}
 
-	c.JSON(http.StatusOK, orders)
+// FindOrder 按id查询订单，上架中的订单同时计算当前价格
+func FindOrder(orderId int64) (*model.Order, error) {
+	var order model.Order
+	if err := global.DBEngine.First(&order, orderId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, statusError(http.StatusNotFound, "Order not found")
+		}
+		return nil, statusError(http.StatusInternalServerError, "Failed to fetch order")
+	}
+	if order.Status == model.OrderStatusOpen {
+		if err := fillCurrentPrice(&order, now()); err != nil {
+			return nil, statusError(http.StatusInternalServerError, "Failed to calculate price")
+		}
+	}
+	describeOrder(&order)
+	return &order, nil
 }
 
-// BuyNFT 购买NFT
-func BuyNFT(c *gin.Context) {
+// CancelOrder 卖家撤单，需卖家地址对订单撤单消息签名，结算中的订单不能撤单
+func CancelOrder(c *gin.Context) {
 	var input struct {
-		Buyer   string `json:"buyer"`
-		OrderId int    `json:"order_id"`
+		OrderId   int64  `json:"order_id" binding:"required,gt=0"`
+		Signature string `json:"signature" binding:"required,hexadecimal"`
 	}
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validate.BindJSON(c, &input) {
 		return
 	}
+	order, err := CancelSellOrder(c.Request.Context(), input.OrderId, input.Signature)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
 
+// CancelSellOrder 校验卖家对撤单消息的签名后撤销仍在上架中的订单
+func CancelSellOrder(ctx context.Context, orderId int64, signature string) (*model.Order, error) {
 	var order model.Order
-	if err := global.DBEngine.First(&order, input.OrderId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
-		return
+	if err := global.DBEngine.First(&order, orderId).Error; err != nil {
+		return nil, statusError(http.StatusNotFound, "Order not found")
+	}
+	orderChain, err := global.Chains.Get(order.SellOrder.ChainId)
+	if err != nil {
+		return nil, statusError(http.StatusBadRequest, err.Error())
 	}
+	// 卖家为合约钱包时通过ERC-1271校验
+	digest := accounts.TextHash([]byte(order.CancelMessage()))
+	valid, err := orderChain.VerifySignature(ctx, common.HexToAddress(order.SellOrder.Seller), common.BytesToHash(digest), signature)
+	if err != nil || !valid {
+		return nil, statusError(http.StatusForbidden, "Cancel must be signed by the seller")
+	}
+	// 只撤销仍在上架中的订单，与结算锁定订单互斥
+	result := global.DBEngine.Model(&model.Order{}).
+		Where("order_id = ? AND status = ? AND filled_tx_hash IS NULL", order.OrderId, model.OrderStatusOpen).
+		Update("status", model.OrderStatusCancelled)
+	if result.Error != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to cancel order")
+	}
+	if result.RowsAffected == 0 {
+		return nil, statusError(http.StatusConflict, "Order is "+order.Status)
+	}
+	order.Status = model.OrderStatusCancelled
+	events.PublishOrders(global.DBEngine, events.OrderCancelled, order.OrderId)
+	describeOrder(&order)
+	return &order, nil
+}
 
-	if order.FilledTxHash != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order already filled"})
-		return
+// fillCurrentPrice 计算上架中订单的当前价格，荷兰拍按当前时间计算，英式拍为最高出价
+func fillCurrentPrice(order *model.Order, current time.Time) error {
+	var highestAmount *big.Int
+	if order.SellOrder.OrderType == model.OrderTypeEnglish {
+		highestBid, err := highestActiveBid(order.OrderId)
+		if err != nil {
+			return err
+		}
+		if highestBid != nil {
+			order.HighestBid = highestBid.Amount
+			highestAmount, _ = new(big.Int).SetString(highestBid.Amount, 10)
+		}
 	}
+	price, err := auction.CurrentPrice(order.SellOrder, highestAmount, current)
+	if err != nil {
+		return err
+	}
+	order.CurrentPrice = price.String()
+	return nil
+}
 
-	if time.Now().Unix() > int64(order.SellOrder.Deadline) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order deadline exceeded"})
+// BuyNFT 购买NFT
+func BuyNFT(c *gin.Context) {
+	var input model.BuyRequest
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	order, _, err := Buy(input)
+	if err != nil {
+		writeError(c, err)
 		return
 	}
+	// 结算交易已广播，由发件箱任务完成成交，订单为settling(ERC1155订单部分购买时仍为open)
+	c.JSON(http.StatusAccepted, order)
+}
+
+// Buy 校验订单和买家授权后锁定订单并广播结算交易，返回锁定后的订单和状态为pending/sent的发件箱记录
+func Buy(input model.BuyRequest) (*model.Order, *model.Outbox, error) {
+	var order model.Order
+	if err := global.DBEngine.First(&order, input.OrderId).Error; err != nil {
+		return nil, nil, statusError(http.StatusNotFound, "Order not found")
+	}
+	amount := buyAmount(input.Amount)
+	price, err := purchasePrice(&order, input.Buyer, amount, input.Proof, now())
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// 验证签名
 	valid, err := verifySellOrderSignature(order.SellOrder, order.Signature, order.SellerPubKey)
 	if err != nil || !valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
-		return
+		return nil, nil, statusError(http.StatusBadRequest, "Invalid signature")
 	}
 
-	// 调用智能合约buyNFTForOffline方法
-	txHash, blockNumber, blockTimestamp, err := callBuyNFTForOffline(input.Buyer, order.SellOrder)
+	orderChain, err := global.Chains.Get(order.SellOrder.ChainId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to buy NFT"})
-		return
+		return nil, nil, statusError(http.StatusBadRequest, err.Error())
+	}
+	// 合约钱包卖家的签名在成交前重新校验，多签owner变更后旧签名可能失效
+	if order.SellerSignature != "" {
+		if err := verifySellerSignature(orderChain, order.SellOrder, order.SellerSignature); err != nil {
+			return nil, nil, statusError(http.StatusBadRequest, err.Error())
+		}
 	}
 
-	// 更新订单状态
-	order.FilledTxHash = &txHash
-	order.BlockNumber = &blockNumber
-	order.BlockTimestamp = &blockTimestamp
-	if err := global.DBEngine.Save(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
-		return
+	// 买家签名确认的中继费用与成交价格一起从买家转出
+	quote, err := verifyQuote(orderChain, &order, input, amount, price)
+	if err != nil {
+		return nil, nil, err
+	}
+	if input.Permit != nil {
+		total := new(big.Int).Add(price, quoteFee(quote))
+		if err := verifyBuyPermit(orderChain, input.Buyer, order.SellOrder.PayToken, total, input.Permit); err != nil {
+			return nil, nil, statusError(http.StatusBadRequest, err.Error())
+		}
 	}
 
-	c.JSON(http.StatusOK, order)
+	// 调用订单所在链的智能合约buyNFTForOffline方法，携带permit时调用buyNFTWithPermit/buyNFTWithPermit2，ERC1155订单调用对应的buyERC1155方法
+	entry, err := callBuyNFTForOffline(orderChain, input.Buyer, &order, price, amount, input.Permit, quote, 0)
+	var reverted *revert.Error
+	if errors.As(err, &reverted) {
+		return nil, nil, statusError(http.StatusUnprocessableEntity, "Settlement would revert: "+reverted.Reason)
+	}
+	if errors.Is(err, ErrOrderNotOpen) {
+		return nil, nil, statusError(http.StatusConflict, "Order is being settled by another request")
+	}
+	if errors.Is(err, ErrQuoteUsed) {
+		return nil, nil, statusError(http.StatusConflict, "Quote already used, request a new quote")
+	}
+	if err != nil {
+		return nil, nil, statusError(http.StatusInternalServerError, "Failed to buy NFT")
+	}
+
+	// 重新读取锁定后的订单
+	if err := global.DBEngine.First(&order, order.OrderId).Error; err != nil {
+		return nil, nil, statusError(http.StatusInternalServerError, "Failed to fetch order")
+	}
+	describeOrder(&order)
+	order.OutboxId = entry.Id
+	return &order, entry, nil
+}
+
+// buyAmount 购买数量，未指定时为1
+func buyAmount(amount int64) int64 {
+	if amount == 0 {
+		return 1
+	}
+	return amount
+}
+
+// purchasePrice 校验订单可以按amount购买并计算成交总价，购买和中继费用报价共用
+func purchasePrice(order *model.Order, buyer string, amount int64, proof []string, current time.Time) (*big.Int, error) {
+	if order.FilledTxHash != nil {
+		return nil, statusError(http.StatusBadRequest, "Order already filled")
+	}
+
+	if order.Status != model.OrderStatusOpen {
+		message := "Order is " + order.Status
+		if order.InvalidReason != "" {
+			message += ": " + order.InvalidReason
+		}
+		return nil, statusError(http.StatusBadRequest, message)
+	}
+
+	// ERC721订单数量固定为1，ERC1155订单不能超过未被锁定的剩余数量
+	if !order.SellOrder.IsERC1155() && amount != 1 {
+		return nil, statusError(http.StatusBadRequest, "amount is only supported for erc1155 orders")
+	}
+	if amount > order.Remaining {
+		return nil, statusError(http.StatusBadRequest, fmt.Sprintf("Only %d remaining", order.Remaining))
+	}
+
+	if current.Unix() > int64(order.SellOrder.Deadline) {
+		return nil, statusError(http.StatusBadRequest, "Order deadline exceeded")
+	}
+
+	// 英式拍只能通过出价成交
+	if order.SellOrder.OrderType == model.OrderTypeEnglish {
+		return nil, statusError(http.StatusBadRequest, "English auction can only be filled by bids")
+	}
+	if order.SellOrder.OrderType == model.OrderTypeDutch && current.Unix() < order.SellOrder.StartTime {
+		return nil, statusError(http.StatusBadRequest, "Auction has not started")
+	}
+	// 荷兰拍在结算时按当前时间计算价格
+	price, err := auction.CurrentPrice(order.SellOrder, nil, current)
+	if err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to calculate price")
+	}
+
+	// 携带白名单证明时按折扣价成交
+	if proof != nil {
+		discountPrice, err := verifyDiscountProof(order.SellOrder, buyer, proof)
+		if err != nil {
+			return nil, statusError(http.StatusBadRequest, err.Error())
+		}
+		price, _ = auction.ParseAmount(discountPrice)
+	}
+	// ERC1155订单的价格为单价，按购买数量计算总价
+	return new(big.Int).Mul(price, big.NewInt(amount)), nil
 }
 
-// GenKeyPair 生成密钥对
+// GenKeyPair 生成密钥对，curve可选P-256(默认)和secp256k1，format可选pem(默认)、jwk、sec1、sec1-compressed和legacy
 func GenKeyPair(c *gin.Context) {
-	pri, pub, err := utils.GenKeyPair()
+	var query struct {
+		Curve  string `form:"curve" json:"curve" binding:"omitempty,oneof=P-256 secp256k1"`
+		Format string `form:"format" json:"format" binding:"omitempty,oneof=pem jwk sec1 sec1-compressed legacy"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	if query.Curve == "" {
+		query.Curve = utils.CurveP256
+	}
+	format, err := utils.ParseKeyFormat(query.Format)
+	if err != nil {
+		writeError(c, invalidError(err.Error()))
+		return
+	}
+	pri, pub, err := utils.GenKeyPairWith(query.Curve, format)
+	if errors.Is(err, utils.ErrUnsupportedFormat) {
+		writeError(c, invalidError(err.Error()))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate keypair"})
+		response.Error(c, http.StatusInternalServerError, "Failed to generate keypair")
+		return
 	}
 	var output struct {
 		PrivateKey string `json:"private_key"`
@@ -166,52 +495,196 @@ func verifySellOrderSignature(sellOrder model.SellOrder, signature string, publi
 	return utils.VerifySign(string(orderJson), signature, publicKeyStr)
 }
 
-// callBuyNFTForOffline 调用合约BuyNFTForOffline方法
-func callBuyNFTForOffline(buyer string, order model.SellOrder) (string, int64, int64, error) {
-	// 获取当前区块链的ChainID
-	chainID, err := global.EthRpcClient.ChainID(context.Background())
+// verifySellerSignature 校验卖家地址对订单消息的签名，卖家为合约钱包时回退到ERC-1271
+func verifySellerSignature(orderChain *chain.Chain, sellOrder model.SellOrder, signature string) error {
+	digest := accounts.TextHash([]byte(sellOrder.Message()))
+	valid, err := orderChain.VerifySignature(context.Background(), common.HexToAddress(sellOrder.Seller), common.BytesToHash(digest), signature)
 	if err != nil {
-		fmt.Println("获取ChainID失败:", err)
-		return "", 0, 0, err
+		return fmt.Errorf("failed to verify seller signature: %w", err)
+	}
+	if !valid {
+		return errors.New("invalid seller signature")
 	}
-	// 构建参数对象
-	privateKey, _ := crypto.HexToECDSA(strings.TrimPrefix(global.BlockChainConfig.PrivateKey, "0x"))
-	opts, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	return nil
+}
+
+// callBuyNFTForOffline 调用合约BuyNFTForOffline方法，由钱包池选取白名单钱包签名交易，锁定订单并写入发件箱后广播
+// price为成交总价，一口价订单为Price，拍卖订单为结算时计算出的价格，amount为成交数量，bidId为英式拍成交的出价
+// quote为买家签名确认的中继费用报价，合约将其中的费用从买家转给发送交易的结算钱包，为nil时不收取
+// buyPermit不为空时改为调用buyNFTWithPermit/buyNFTWithPermit2，授权与购买在同一笔交易中完成
+// ERC1155订单调用buyERC1155ForOffline/buyERC1155WithPermit/buyERC1155WithPermit2，按amount转移
+// 广播后即返回pending/sent状态的发件箱记录，不等待上链，订单保持settling，由发件箱任务按链上结果完成成交或回滚
+func callBuyNFTForOffline(orderChain *chain.Chain, buyer string, fill *model.Order, price *big.Int, amount int64, buyPermit *model.BuyPermit,
+	quote *model.RelayerQuote, bidId int64) (*model.Outbox, error) {
+	ctx := context.Background()
+	order := fill.SellOrder
+	gasPrice, gasFeeCap, gasTipCap, err := settlementFees(ctx, orderChain)
 	if err != nil {
-		fmt.Println("bind.NewKeyedTransactorWithChainID error ,", err)
-		return "", 0, 0, err
+		return nil, err
+	}
+
+	relayerFee := quoteFee(quote)
+	entry, done, err := sendSettlement(ctx, orderChain, fill, buyer, price, amount, quote, bidId, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		// 设置参数，gasPrice不为nil时发送legacy交易
+		opts.GasPrice, opts.GasFeeCap, opts.GasTipCap = gasPrice, gasFeeCap, gasTipCap
+		opts.GasLimit = uint64(300000)
+
+		if order.IsERC1155() {
+			return transactERC1155(ctx, orderChain, opts, buyer, order, big.NewInt(amount), price, relayerFee, buyPermit)
+		}
+		buyerAddress, sellerAddress := common.HexToAddress(buyer), common.HexToAddress(order.Seller)
+		nftAddress, payToken, tokenId := common.HexToAddress(order.Nft), common.HexToAddress(order.PayToken), big.NewInt(order.TokenId)
+		if buyPermit == nil {
+			// 广播前先在pending区块上模拟执行，revert时直接返回解码后的原因，避免浪费gas
+			err := simulateTransact(ctx, orderChain, opts, "buyNFTForOffline", buyerAddress, sellerAddress, nftAddress, tokenId, payToken, price, relayerFee)
+			if err != nil {
+				return nil, err
+			}
+			// 调用合约 buyNFTForOffline 方法
+			return orderChain.Market.BuyNFTForOffline(opts, buyerAddress, sellerAddress, nftAddress, tokenId, payToken, price, relayerFee)
+		}
+
+		argument, err := permitArgument(orderChain, buyer, buyPermit)
+		if err != nil {
+			return nil, err
+		}
+		method := "buyNFTWithPermit"
+		if buyPermit.Type == permit.TypePermit2 {
+			method = "buyNFTWithPermit2"
+		}
+		if err := simulateTransact(ctx, orderChain, opts, method, buyerAddress, sellerAddress, nftAddress, tokenId, payToken, price, relayerFee, argument); err != nil {
+			return nil, err
+		}
+		if signature, ok := argument.(contract.NFTMarketPermit2Signature); ok {
+			return orderChain.Market.BuyNFTWithPermit2(opts, buyerAddress, sellerAddress, nftAddress, tokenId, payToken, price, relayerFee, signature)
+		}
+		return orderChain.Market.BuyNFTWithPermit(opts, buyerAddress, sellerAddress, nftAddress, tokenId, payToken, price, relayerFee,
+			argument.(contract.NFTMarketPermitSignature))
+	})
+	if err != nil {
+		return nil, err
+	}
+	done()
+	return entry, nil
+}
+
+// transactERC1155 模拟并调用ERC1155订单的购买方法，合约按amount调用safeTransferFrom
+func transactERC1155(ctx context.Context, orderChain *chain.Chain, opts *bind.TransactOpts, buyer string, order model.SellOrder, amount, price, relayerFee *big.Int,
+	buyPermit *model.BuyPermit) (*types.Transaction, error) {
+	buyerAddress, sellerAddress := common.HexToAddress(buyer), common.HexToAddress(order.Seller)
+	nftAddress, payToken, tokenId := common.HexToAddress(order.Nft), common.HexToAddress(order.PayToken), big.NewInt(order.TokenId)
+	if buyPermit == nil {
+		err := simulateTransact(ctx, orderChain, opts, "buyERC1155ForOffline", buyerAddress, sellerAddress, nftAddress, tokenId, amount, payToken, price, relayerFee)
+		if err != nil {
+			return nil, err
+		}
+		return orderChain.Market.BuyERC1155ForOffline(opts, buyerAddress, sellerAddress, nftAddress, tokenId, amount, payToken, price, relayerFee)
+	}
+
+	argument, err := permitArgument(orderChain, buyer, buyPermit)
+	if err != nil {
+		return nil, err
+	}
+	method := "buyERC1155WithPermit"
+	if buyPermit.Type == permit.TypePermit2 {
+		method = "buyERC1155WithPermit2"
+	}
+	if err := simulateTransact(ctx, orderChain, opts, method, buyerAddress, sellerAddress, nftAddress, tokenId, amount, payToken, price, relayerFee, argument); err != nil {
+		return nil, err
 	}
-	address := common.HexToAddress(global.BlockChainConfig.Address)
-	nonce, _ := global.EthRpcClient.PendingNonceAt(context.Background(), address)
-	baseFee, _ := global.EthRpcClient.BlobBaseFee(context.Background())
-	gasTipCap, _ := global.EthRpcClient.SuggestGasTipCap(context.Background())
-	// 设置参数
-	// GasFeeCap = BaseFee + TipCap
-	opts.GasFeeCap = big.NewInt(baseFee.Int64() + gasTipCap.Int64())
-	opts.GasLimit = uint64(300000)
-	opts.GasTipCap = gasTipCap
-	opts.Nonce = big.NewInt(int64(nonce))
+	if signature, ok := argument.(contract.NFTMarketPermit2Signature); ok {
+		return orderChain.Market.BuyERC1155WithPermit2(opts, buyerAddress, sellerAddress, nftAddress, tokenId, amount, payToken, price, relayerFee, signature)
+	}
+	return orderChain.Market.BuyERC1155WithPermit(opts, buyerAddress, sellerAddress, nftAddress, tokenId, amount, payToken, price, relayerFee,
+		argument.(contract.NFTMarketPermitSignature))
+}
 
-	// 调用合约 buyNFTForOffline 方法
-	tx, err := global.Market.BuyNFTForOffline(
-		opts,
-		common.HexToAddress(buyer),
-		common.HexToAddress(order.Seller),
-		common.HexToAddress(order.Nft),
-		big.NewInt(order.TokenId),
-		common.HexToAddress(order.PayToken),
-		big.NewInt(order.Price),
-	)
+// resolveTokenStandard 通过ERC165检测NFT合约的标准，未实现ERC165的合约视为ERC721，与请求指定的标准不一致时拒绝
+func resolveTokenStandard(orderChain *chain.Chain, nft string, requested string) (string, error) {
+	detected, err := erc165.Detect(context.Background(), orderChain.Client, common.HexToAddress(nft))
+	if err != nil {
+		return "", statusError(http.StatusInternalServerError, "Failed to detect nft standard")
+	}
+	if detected == "" {
+		if requested == model.TokenStandardERC1155 {
+			return "", invalidError("nft contract does not implement erc1155")
+		}
+		return model.TokenStandardERC721, nil
+	}
+	if requested != "" && requested != detected {
+		return "", invalidError(fmt.Sprintf("nft contract is %s, not %s", detected, requested))
+	}
+	return detected, nil
+}
 
+// settlementFees 结算交易的手续费，GasFeeCap = 2 * BaseFee + TipCap，预留下一个区块BaseFee上涨的空间，不超过配置的MaxGasFeeCap
+// 链未启用EIP-1559(最新区块没有BaseFee)时只返回节点建议的gasPrice，用于发送legacy交易，同样不超过MaxGasFeeCap
+func settlementFees(ctx context.Context, orderChain *chain.Chain) (gasPrice, gasFeeCap, gasTipCap *big.Int, err error) {
+	header, err := orderChain.Client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		return "", 0, 0, err
+		return nil, nil, nil, fmt.Errorf("failed to fetch latest header: %w", err)
+	}
+	if header.BaseFee == nil {
+		gasPrice, err = orderChain.Client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		gasPrice, _ = orderChain.CapFees(gasPrice, gasPrice)
+		return gasPrice, nil, nil, nil
 	}
+	gasTipCap, err = orderChain.Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to suggest gas tip cap: %w", err)
+	}
+	gasFeeCap, gasTipCap = orderChain.CapFees(new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap), gasTipCap)
+	return nil, gasFeeCap, gasTipCap, nil
+}
 
-	// 根据交易hash获取区块信息
-	block, err := config.GetBlockByTxHash(tx.Hash().Hex())
+// simulateTransact 以交易发送方身份通过eth_call在pending区块上模拟执行合约方法
+func simulateTransact(ctx context.Context, orderChain *chain.Chain, opts *bind.TransactOpts, method string, args ...interface{}) error {
+	marketAbi, err := contract.NFTMarketMetaData.GetAbi()
 	if err != nil {
-		return "", 0, 0, err
+		return err
 	}
+	data, err := marketAbi.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	marketAddress := orderChain.MarketAddress
+	_, err = orderChain.Client.PendingCallContract(ctx, ethereum.CallMsg{
+		From:      opts.From,
+		To:        &marketAddress,
+		Gas:       opts.GasLimit,
+		GasPrice:  opts.GasPrice,
+		GasFeeCap: opts.GasFeeCap,
+		GasTipCap: opts.GasTipCap,
+		Value:     opts.Value,
+		Data:      data,
+	})
+	if err == nil {
+		return nil
+	}
+	if reverted := revert.FromCallError(err); reverted != nil {
+		return reverted
+	}
+	return fmt.Errorf("failed to simulate %s: %w", method, err)
+}
 
-	return tx.Hash().Hex(), block.Number().Int64(), int64(block.Time()), nil
+// queryChain 解析可选的chain_id查询参数，未指定时返回nil
+func queryChain(c *gin.Context) (*chain.Chain, bool) {
+	var query struct {
+		ChainId int64 `form:"chain_id" json:"chain_id" binding:"omitempty,chain_id"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return nil, false
+	}
+	if query.ChainId == 0 {
+		return nil, true
+	}
+	orderChain, err := global.Chains.Get(query.ChainId)
+	if err != nil {
+		response.Invalid(c, err.Error())
+		return nil, false
+	}
+	return orderChain, true
 }
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/ethrpc"
+	"nftmarket/internal/events"
+	"nftmarket/internal/model"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOrderNotOpen 锁定订单时订单已不是上架状态或剩余数量不足，通常是并发的另一笔结算已经锁定
+	ErrOrderNotOpen = errors.New("order is no longer open")
+	// ErrQuoteUsed 中继费用报价已被另一次购买使用
+	ErrQuoteUsed = errors.New("relayer quote already used")
+)
+
+// 未完成的发件箱状态
+var unresolvedOutboxStatus = []string{model.OutboxStatusPending, model.OutboxStatusSent}
+
+// sendSettlement 由钱包池签名结算交易，在同一个数据库事务中锁定订单并写入发件箱，提交后再广播
+// 锁定时扣减订单剩余数量，剩余数量扣减为0时订单变为settling，ERC1155订单部分购买时仍为open；quote不为nil时同时标记报价已使用
+// 广播失败不回滚，交易已占用nonce，由发件箱任务重新广播
+func sendSettlement(ctx context.Context, orderChain *chain.Chain, order *model.Order, buyer string, price *big.Int, amount int64, quote *model.RelayerQuote, bidId int64,
+	sign func(opts *bind.TransactOpts) (*types.Transaction, error)) (*model.Outbox, func(), error) {
+	var entry *model.Outbox
+	_, signer, err := orderChain.SignerPool.Send(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		opts.NoSend = true
+		tx, err := sign(opts)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		entry = &model.Outbox{
+			ChainId:   orderChain.ID,
+			OrderId:   order.OrderId,
+			BidId:     bidId,
+			Buyer:     buyer,
+			Price:     price.String(),
+			Amount:    amount,
+			Signer:    opts.From.Hex(),
+			Nonce:     tx.Nonce(),
+			TxHash:    tx.Hash().Hex(),
+			RawTx:     hexutil.Encode(raw),
+			GasFeeCap: tx.GasFeeCap().String(),
+			GasTipCap: tx.GasTipCap().String(),
+			Status:    model.OutboxStatusPending,
+			SentAt:    now().Unix(),
+		}
+		entry.RelayerFee = quoteFee(quote).String()
+		if quote != nil {
+			entry.QuoteId = quote.Id
+		}
+		err = global.DBEngine.Transaction(func(db *gorm.DB) error {
+			result := db.Model(&model.Order{}).Where("order_id = ? AND status = ? AND remaining >= ?", order.OrderId, model.OrderStatusOpen, amount).
+				Updates(map[string]interface{}{
+					"remaining": gorm.Expr("remaining - ?", amount),
+					"status":    gorm.Expr("CASE WHEN remaining = ? THEN ? ELSE status END", amount, model.OrderStatusSettling),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrOrderNotOpen
+			}
+			if quote != nil {
+				result := db.Model(&model.RelayerQuote{}).Where("id = ? AND used_at = 0", quote.Id).Update("used_at", now().Unix())
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return ErrQuoteUsed
+				}
+			}
+			if err := db.Create(entry).Error; err != nil {
+				return err
+			}
+			return db.Create(outboxTx(entry)).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		events.PublishOrders(global.DBEngine, events.OrderSettling, order.OrderId)
+		broadcastOutbox(ctx, orderChain, entry, tx)
+		return tx, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, func() { orderChain.SignerPool.Done(signer) }, nil
+}
+
+// RecoverOutbox 启动时按链上状态处理所有未完成的结算交易：已上链的完成成交或回滚订单，未上链的重新广播
+func RecoverOutbox(ctx context.Context) error {
+	var entries []model.Outbox
+	if err := global.DBEngine.Where("status IN ?", unresolvedOutboxStatus).Order("id").Find(&entries).Error; err != nil {
+		return err
+	}
+	for i := range entries {
+		orderChain, err := global.Chains.Get(entries[i].ChainId)
+		if err != nil {
+			log.Printf("outbox %d: %v", entries[i].Id, err)
+			continue
+		}
+		if err := resolveOutbox(ctx, orderChain, &entries[i]); err != nil {
+			log.Printf("outbox %d: %v", entries[i].Id, err)
+		}
+	}
+	return nil
+}
+
+// RunOutboxDispatcher 定时处理未完成的结算交易，直到ctx结束
+func RunOutboxDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := RecoverOutbox(ctx); err != nil {
+			log.Printf("outbox dispatcher: failed to fetch outbox: %v", err)
+		}
+	}
+}
+
+// resolveOutbox 根据链上状态处理一条发件箱记录，原始交易和所有替换交易中任意一笔上链都归属到该订单
+// 先查询nonce再查询收据，避免交易恰好在两次查询之间上链时被误判为丢弃
+func resolveOutbox(ctx context.Context, orderChain *chain.Chain, entry *model.Outbox) error {
+	confirmedNonce, err := orderChain.Client.NonceAt(ctx, common.HexToAddress(entry.Signer), nil)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce of %s: %w", entry.Signer, err)
+	}
+	var txs []model.OutboxTx
+	if err := global.DBEngine.Where("outbox_id = ?", entry.Id).Order("id DESC").Find(&txs).Error; err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		txs = []model.OutboxTx{*outboxTx(entry)}
+	}
+	for _, sent := range txs {
+		receipt, err := orderChain.Client.TransactionReceipt(ctx, common.HexToHash(sent.TxHash))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get transaction receipt: %w", err)
+		}
+		head, err := orderChain.Client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get block number: %w", err)
+		}
+		// 未达到确认数时等待下一轮
+		if orderChain.Confirmations > 1 && head < receipt.BlockNumber.Uint64()+orderChain.Confirmations-1 {
+			return nil
+		}
+		entry.TxHash = sent.TxHash
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return rollbackOutbox(entry, model.OutboxStatusFailed, "transaction reverted")
+		}
+		header, err := orderChain.Client.HeaderByHash(ctx, receipt.BlockHash)
+		if err != nil {
+			return fmt.Errorf("failed to get block by hash: %w", err)
+		}
+		return completeOutbox(entry, receipt, int64(header.Time), effectiveGasPrice(receipt, header, sent))
+	}
+	if confirmedNonce > entry.Nonce {
+		return rollbackOutbox(entry, model.OutboxStatusDropped, "nonce used by another transaction")
+	}
+
+	var tx types.Transaction
+	raw, err := hexutil.Decode(entry.RawTx)
+	if err != nil {
+		return err
+	}
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return err
+	}
+	// 超过等待时间仍未上链时提高手续费替换，达到上限无法替换时继续广播当前交易
+	if orderChain.StuckTxTimeout > 0 && now().Unix()-entry.SentAt >= int64(orderChain.StuckTxTimeout.Seconds()) {
+		err := replaceOutbox(ctx, orderChain, entry, &tx)
+		if err == nil {
+			return nil
+		}
+		log.Printf("outbox %d: %v", entry.Id, err)
+	}
+	// 仍在交易池中时等待上链，否则使用落库的原始交易重新广播
+	if _, _, err := orderChain.Client.TransactionByHash(ctx, tx.Hash()); err == nil {
+		return nil
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	broadcastOutbox(ctx, orderChain, entry, &tx)
+	return nil
+}
+
+// replaceOutbox 以相同nonce重新签名卡住的交易，GasTipCap和GasFeeCap至少提高FeeBumpPercent，且不低于当前BaseFee的要求
+// 提高后超过MaxGasFeeCap时按上限截断，截断后达不到节点的替换要求则放弃替换，见replacementFees
+// 原交易为legacy交易(链未启用EIP-1559)时替换交易也是legacy交易，gasPrice按GasFeeCap提高
+func replaceOutbox(ctx context.Context, orderChain *chain.Chain, entry *model.Outbox, stuck *types.Transaction) error {
+	header, err := orderChain.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest header: %w", err)
+	}
+	gasFeeCap, gasTipCap, err := replacementFees(orderChain, stuck, header.BaseFee)
+	if err != nil {
+		return fmt.Errorf("transaction %s is stuck: %w", entry.TxHash, err)
+	}
+
+	var data types.TxData = &types.DynamicFeeTx{
+		ChainID:   stuck.ChainId(),
+		Nonce:     stuck.Nonce(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       stuck.Gas(),
+		To:        stuck.To(),
+		Value:     stuck.Value(),
+		Data:      stuck.Data(),
+	}
+	// 未启用EIP-1559的链上发送的legacy交易，GasTipCap和GasFeeCap都等于gasPrice，替换交易同样为legacy交易
+	if stuck.Type() == types.LegacyTxType {
+		gasTipCap = gasFeeCap
+		data = &types.LegacyTx{
+			Nonce:    stuck.Nonce(),
+			GasPrice: gasFeeCap,
+			Gas:      stuck.Gas(),
+			To:       stuck.To(),
+			Value:    stuck.Value(),
+			Data:     stuck.Data(),
+		}
+	}
+	replacement, err := orderChain.SignerPool.SignTx(common.HexToAddress(entry.Signer), types.NewTx(data))
+	if err != nil {
+		return err
+	}
+	raw, err := replacement.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	replaced := *entry
+	replaced.TxHash = replacement.Hash().Hex()
+	replaced.RawTx = hexutil.Encode(raw)
+	replaced.GasFeeCap = gasFeeCap.String()
+	replaced.GasTipCap = gasTipCap.String()
+	replaced.Replacements++
+	replaced.SentAt = now().Unix()
+	err = global.DBEngine.Transaction(func(db *gorm.DB) error {
+		result := db.Model(&model.Outbox{}).Where("id = ? AND status IN ?", entry.Id, unresolvedOutboxStatus).Updates(map[string]interface{}{
+			"tx_hash":      replaced.TxHash,
+			"raw_tx":       replaced.RawTx,
+			"gas_fee_cap":  replaced.GasFeeCap,
+			"gas_tip_cap":  replaced.GasTipCap,
+			"replacements": replaced.Replacements,
+			"sent_at":      replaced.SentAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("outbox already resolved")
+		}
+		return db.Create(outboxTx(&replaced)).Error
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("outbox %d: replaced stuck transaction %s with %s, gas fee cap %s, tip cap %s",
+		entry.Id, entry.TxHash, replaced.TxHash, gasFeeCap, gasTipCap)
+	*entry = replaced
+	broadcastOutbox(ctx, orderChain, entry, replacement)
+	return nil
+}
+
+// replacementFees 计算替换交易的GasFeeCap和GasTipCap，baseFee为nil(链未启用EIP-1559)时只按涨幅提高
+func replacementFees(orderChain *chain.Chain, stuck *types.Transaction, baseFee *big.Int) (*big.Int, *big.Int, error) {
+	gasTipCap := bumpFee(stuck.GasTipCap(), orderChain.FeeBumpPercent)
+	gasFeeCap := bumpFee(stuck.GasFeeCap(), orderChain.FeeBumpPercent)
+	if baseFee != nil {
+		if required := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), gasTipCap); gasFeeCap.Cmp(required) < 0 {
+			gasFeeCap = required
+		}
+	}
+	gasFeeCap, gasTipCap = orderChain.CapFees(gasFeeCap, gasTipCap)
+	if gasFeeCap.Cmp(bumpFee(stuck.GasFeeCap(), chain.MinFeeBumpPercent)) < 0 || gasTipCap.Cmp(bumpFee(stuck.GasTipCap(), chain.MinFeeBumpPercent)) < 0 {
+		return nil, nil, fmt.Errorf("fees already reach MaxGasFeeCap %s", orderChain.MaxGasFeeCap)
+	}
+	return gasFeeCap, gasTipCap, nil
+}
+
+// bumpFee 手续费按百分比向上取整提高，至少提高1 wei
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99)).Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+// outboxTx 发件箱当前交易对应的交易记录
+func outboxTx(entry *model.Outbox) *model.OutboxTx {
+	return &model.OutboxTx{
+		OutboxId:  entry.Id,
+		TxHash:    entry.TxHash,
+		RawTx:     entry.RawTx,
+		GasFeeCap: entry.GasFeeCap,
+		GasTipCap: entry.GasTipCap,
+	}
+}
+
+// broadcastOutbox 广播交易并记录广播次数和错误，节点已有该交易时视为广播成功
+func broadcastOutbox(ctx context.Context, orderChain *chain.Chain, entry *model.Outbox, tx *types.Transaction) {
+	updates := map[string]interface{}{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"broadcast_at": now().Unix(),
+		"last_error":   "",
+	}
+	if err := orderChain.Client.SendTransaction(ctx, tx); err != nil && !ethrpc.IsKnownTransaction(err) {
+		log.Printf("outbox %d: failed to broadcast %s: %v", entry.Id, entry.TxHash, err)
+		updates["last_error"] = err.Error()
+	} else if entry.Status == model.OutboxStatusPending {
+		updates["status"] = model.OutboxStatusSent
+		entry.Status = model.OutboxStatusSent
+	}
+	if err := global.DBEngine.Model(&model.Outbox{}).Where("id = ? AND status IN ?", entry.Id, unresolvedOutboxStatus).
+		Updates(updates).Error; err != nil {
+		log.Printf("outbox %d: failed to update broadcast status: %v", entry.Id, err)
+	}
+}
+
+// effectiveGasPrice 结算交易的实际gas价格，节点收据没有effectiveGasPrice时按BaseFee + min(TipCap, FeeCap - BaseFee)计算，没有BaseFee时为GasFeeCap
+func effectiveGasPrice(receipt *types.Receipt, header *types.Header, sent model.OutboxTx) *big.Int {
+	if receipt.EffectiveGasPrice != nil {
+		return receipt.EffectiveGasPrice
+	}
+	feeCap, _ := new(big.Int).SetString(sent.GasFeeCap, 10)
+	tipCap, _ := new(big.Int).SetString(sent.GasTipCap, 10)
+	if feeCap == nil || tipCap == nil {
+		return nil
+	}
+	// legacy交易的GasFeeCap即gasPrice
+	if header.BaseFee == nil {
+		return feeCap
+	}
+	price := new(big.Int).Add(header.BaseFee, tipCap)
+	if price.Cmp(feeCap) > 0 {
+		return feeCap
+	}
+	return price
+}
+
+// completeOutbox 结算交易上链成功，在同一事务中标记发件箱完成并记录成交及gas消耗，已处理过的记录直接返回
+// 订单剩余数量为0且没有其他未完成的结算时标记订单成交，否则为ERC1155订单的部分成交
+func completeOutbox(entry *model.Outbox, receipt *types.Receipt, blockTimestamp int64, gasPrice *big.Int) error {
+	blockNumber := receipt.BlockNumber.Int64()
+	gasUsed := int64(receipt.GasUsed)
+	fill := &model.Fill{
+		OrderId:        entry.OrderId,
+		OutboxId:       entry.Id,
+		Buyer:          entry.Buyer,
+		Amount:         entry.Amount,
+		Price:          entry.Price,
+		TxHash:         entry.TxHash,
+		BlockNumber:    blockNumber,
+		BlockTimestamp: blockTimestamp,
+		GasUsed:        &gasUsed,
+		RelayerFee:     entry.RelayerFee,
+	}
+	if gasPrice != nil {
+		effective, cost := gasPrice.String(), new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)).String()
+		fill.EffectiveGasPrice, fill.GasCost = &effective, &cost
+	}
+	completed, filled := false, false
+	err := global.DBEngine.Transaction(func(db *gorm.DB) error {
+		result := db.Model(&model.Outbox{}).Where("id = ? AND status IN ?", entry.Id, unresolvedOutboxStatus).
+			Updates(map[string]interface{}{"status": model.OutboxStatusConfirmed, "tx_hash": entry.TxHash})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		completed = true
+		if err := db.Create(fill).Error; err != nil {
+			return err
+		}
+		var order model.Order
+		if err := db.First(&order, entry.OrderId).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"filled_tx_hash":  entry.TxHash,
+			"block_number":    blockNumber,
+			"block_timestamp": blockTimestamp,
+			"status":          model.OrderStatusFilled,
+		}
+		// ERC1155订单有多个买家，买家和成交价格只记录在order_fill中
+		if !order.SellOrder.IsERC1155() {
+			updates["buyer"] = entry.Buyer
+			updates["filled_price"] = entry.Price
+		}
+		pending := db.Model(&model.Outbox{}).Select("1").Where("order_id = ? AND status IN ?", entry.OrderId, unresolvedOutboxStatus)
+		result = db.Model(&model.Order{}).Where("order_id = ? AND status = ? AND remaining = 0 AND NOT EXISTS (?)",
+			entry.OrderId, model.OrderStatusSettling, pending).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		filled = result.RowsAffected > 0
+		if entry.BidId != 0 {
+			return db.Model(&model.Bid{}).Where("bid_id = ?", entry.BidId).Update("status", model.BidStatusWon).Error
+		}
+		return nil
+	})
+	if err == nil && filled {
+		events.PublishOrders(global.DBEngine, events.OrderFilled, entry.OrderId)
+	} else if err == nil && completed {
+		events.PublishOrders(global.DBEngine, events.OrderPartiallyFilled, entry.OrderId)
+	}
+	return err
+}
+
+// rollbackOutbox 结算交易失败或被丢弃，取消这次结算：不记录成交，锁定的数量退回订单并重新上架，过期订单由清理任务处理
+// 订单不标记为cancelled，卖家签名仍然有效，撤单只能由卖家发起；英式拍的出价上链执行失败时标记为invalid，下次结算次高出价，
+// 交易被丢弃不归咎于出价
+func rollbackOutbox(entry *model.Outbox, status string, reason string) error {
+	log.Printf("outbox %d: order %d settlement %s: %s", entry.Id, entry.OrderId, status, reason)
+	reopened := false
+	err := global.DBEngine.Transaction(func(db *gorm.DB) error {
+		result := db.Model(&model.Outbox{}).Where("id = ? AND status IN ?", entry.Id, unresolvedOutboxStatus).
+			Updates(map[string]interface{}{"status": status, "last_error": reason, "tx_hash": entry.TxHash})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if entry.BidId != 0 && status == model.OutboxStatusFailed {
+			if err := db.Model(&model.Bid{}).Where("bid_id = ?", entry.BidId).Update("status", model.BidStatusInvalid).Error; err != nil {
+				return err
+			}
+		}
+		result = db.Model(&model.Order{}).Where("order_id = ? AND status IN ?", entry.OrderId,
+			[]string{model.OrderStatusSettling, model.OrderStatusOpen}).Updates(map[string]interface{}{
+			"remaining": gorm.Expr("remaining + ?", entry.Amount),
+			"status":    model.OrderStatusOpen,
+		})
+		reopened = result.RowsAffected > 0
+		return result.Error
+	})
+	if err == nil && reopened {
+		events.PublishOrders(global.DBEngine, events.OrderReopened, entry.OrderId)
+	}
+	return err
+}
@@ -0,0 +1,546 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/ethrpc"
+	"nftmarket/internal/model"
+	"nftmarket/wallet"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// 模拟链的chain id，见simulated.NewBackend
+const simulatedChainId = 1337
+
+// 任何调用都revert的合约
+const revertRuntime = "0x60006000fd"
+
+// 代理对eth_sendRawTransaction的处理方式
+const (
+	sendForward = iota
+	sendReject  // 不转发，返回503：进程在广播前退出或节点不可达
+	sendLost    // 转发后返回503：节点已收到交易但响应丢失
+)
+
+// simulatedChain 通过HTTP连接模拟链的chain.Chain，请求经过代理以模拟广播失败
+type simulatedChain struct {
+	*chain.Chain
+	backend   *simulated.Backend
+	key       *ecdsa.PrivateKey // 结算钱包私钥
+	reverting common.Address    // 部署了revertRuntime的地址
+	sendMode  atomic.Int32
+	legacy    atomic.Bool // 去掉区块头的baseFeePerGas，模拟未启用EIP-1559的链，结算交易为legacy交易
+}
+
+// newSimulatedChain 启动开启HTTP的模拟链，结算钱包池只有一个钱包，并注册为global.Chains
+func newSimulatedChain(t *testing.T) *simulatedChain {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reverting := common.HexToAddress("0x000000000000000000000000000000000000dead")
+	alloc := types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(1e18)},
+		reverting:                             {Code: hexutil.MustDecode(revertRuntime)},
+	}
+	port := freePort(t)
+	backend := simulated.NewBackend(alloc, func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+		nodeConf.HTTPHost, nodeConf.HTTPPort = "127.0.0.1", port
+		nodeConf.HTTPModules, nodeConf.HTTPVirtualHosts = []string{"eth"}, []string{"*"}
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	sc := &simulatedChain{backend: backend, key: key, reverting: reverting}
+	target, err := url.Parse("http://127.0.0.1:" + strconv.Itoa(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httptest.NewServer(sc.proxy(httputil.NewSingleHostReverseProxy(target)))
+	t.Cleanup(proxy.Close)
+
+	client, err := ethrpc.Dial(context.Background(), []string{proxy.URL}, ethrpc.Options{Timeout: 5 * time.Second, Cooldown: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := wallet.NewPool(client, big.NewInt(simulatedChainId), []string{hex.EncodeToString(crypto.FromECDSA(key))}, big.NewInt(0), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Chain = &chain.Chain{ID: simulatedChainId, Client: client, SignerPool: pool}
+	registry, err := chain.NewRegistry([]*chain.Chain{sc.Chain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := global.Chains
+	global.Chains = registry
+	t.Cleanup(func() { global.Chains = previous })
+	// 只有创世区块时交易索引一直处于未完成状态
+	sc.commit(t)
+	return sc
+}
+
+// commit 出块并等待交易索引完成
+func (sc *simulatedChain) commit(t *testing.T) {
+	t.Helper()
+	sc.backend.Commit()
+	sc.waitIndexed(t)
+}
+
+// waitIndexed 等待节点完成交易索引，索引期间查询收据返回错误而不是NotFound
+func (sc *simulatedChain) waitIndexed(t *testing.T) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		_, err := sc.Client.TransactionReceipt(context.Background(), common.Hash{})
+		if errors.Is(err, ethereum.NotFound) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("transaction indexing not finished: %v", err)
+		}
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// proxy 转发JSON-RPC请求，按sendMode处理eth_sendRawTransaction，legacy时去掉区块头的baseFeePerGas
+func (sc *simulatedChain) proxy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.Unmarshal(body, &req)
+		if req.Method == "eth_getBlockByNumber" && sc.legacy.Load() {
+			// 需要读取并修改响应，不接受gzip压缩
+			r.Header.Del("Accept-Encoding")
+			recorder := httptest.NewRecorder()
+			next.ServeHTTP(recorder, r)
+			var resp map[string]interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			if block, ok := resp["result"].(map[string]interface{}); ok {
+				delete(block, "baseFeePerGas")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+		if req.Method != "eth_sendRawTransaction" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch sc.sendMode.Load() {
+		case sendReject:
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		case sendLost:
+			next.ServeHTTP(httptest.NewRecorder(), r)
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// settle 锁定订单并发送一笔发往to的结算交易，to为reverting时交易上链后执行失败，bidId为英式拍成交的出价
+func (sc *simulatedChain) settle(t *testing.T, order *model.Order, to common.Address, bidId int64) *model.Outbox {
+	t.Helper()
+	entry, done, err := sendSettlement(context.Background(), sc.Chain, order, "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC", big.NewInt(1000), 1, nil, bidId,
+		func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			if sc.legacy.Load() {
+				return opts.Signer(opts.From, types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64(), GasPrice: big.NewInt(1e11), Gas: 100000, To: &to}))
+			}
+			return opts.Signer(opts.From, types.NewTx(&types.DynamicFeeTx{
+				ChainID:   big.NewInt(simulatedChainId),
+				Nonce:     opts.Nonce.Uint64(),
+				GasTipCap: big.NewInt(1e9),
+				GasFeeCap: big.NewInt(1e11),
+				Gas:       100000,
+				To:        &to,
+			}))
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done()
+	return entry
+}
+
+// createOpenOrder 保存一个上架中的ERC721订单
+func createOpenOrder(t *testing.T) *model.Order {
+	t.Helper()
+	order := &model.Order{
+		SellOrder: model.SellOrder{
+			Seller:   "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+			Nft:      "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+			TokenId:  1,
+			Price:    1000,
+			ChainId:  simulatedChainId,
+			Deadline: time.Now().Add(time.Hour).Unix(),
+		},
+		Status:    model.OrderStatusOpen,
+		Remaining: 1,
+	}
+	if err := global.DBEngine.Create(order).Error; err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+// createBid 保存订单的一个有效出价
+func createBid(t *testing.T, order *model.Order) *model.Bid {
+	t.Helper()
+	bid := &model.Bid{OrderId: order.OrderId, Bidder: "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC", Amount: "1000", Status: model.BidStatusActive}
+	if err := global.DBEngine.Create(bid).Error; err != nil {
+		t.Fatal(err)
+	}
+	return bid
+}
+
+func expectBidStatus(t *testing.T, bidId int64, status string) {
+	t.Helper()
+	var bid model.Bid
+	if err := global.DBEngine.First(&bid, bidId).Error; err != nil {
+		t.Fatal(err)
+	}
+	if bid.Status != status {
+		t.Fatalf("bid status = %s, want %s", bid.Status, status)
+	}
+}
+
+func setupOutboxTest(t *testing.T) (*simulatedChain, *model.Order) {
+	t.Helper()
+	setupTestDB(t, &model.Order{}, &model.Outbox{}, &model.OutboxTx{}, &model.Fill{}, &model.Bid{})
+	return newSimulatedChain(t), createOpenOrder(t)
+}
+
+// expectState 校验发件箱和订单状态
+func expectState(t *testing.T, entryId int64, outboxStatus string, orderId int64, orderStatus string, remaining int64) (*model.Outbox, *model.Order) {
+	t.Helper()
+	var entry model.Outbox
+	if err := global.DBEngine.First(&entry, entryId).Error; err != nil {
+		t.Fatal(err)
+	}
+	var order model.Order
+	if err := global.DBEngine.First(&order, orderId).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != outboxStatus || order.Status != orderStatus || order.Remaining != remaining {
+		t.Fatalf("outbox %s (%s), order %s remaining %d; want outbox %s, order %s remaining %d",
+			entry.Status, entry.LastError, order.Status, order.Remaining, outboxStatus, orderStatus, remaining)
+	}
+	return &entry, &order
+}
+
+func TestOutboxMinedCompletes(t *testing.T) {
+	sc, order := setupOutboxTest(t)
+	buyer := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	entry := sc.settle(t, order, buyer, 0)
+	expectState(t, entry.Id, model.OutboxStatusSent, order.OrderId, model.OrderStatusSettling, 0)
+
+	// 未上链时保持sent
+	if err := resolveOutbox(context.Background(), sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	expectState(t, entry.Id, model.OutboxStatusSent, order.OrderId, model.OrderStatusSettling, 0)
+
+	sc.commit(t)
+	head, err := sc.Client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resolveOutbox(context.Background(), sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	_, filled := expectState(t, entry.Id, model.OutboxStatusConfirmed, order.OrderId, model.OrderStatusFilled, 0)
+	if filled.FilledTxHash == nil || *filled.FilledTxHash != entry.TxHash || filled.Buyer == nil || *filled.Buyer != buyer.Hex() ||
+		filled.FilledPrice == nil || *filled.FilledPrice != "1000" || filled.BlockNumber == nil || *filled.BlockNumber != int64(head) {
+		t.Fatalf("filled order = %+v", filled)
+	}
+	var fill model.Fill
+	if err := global.DBEngine.Where("outbox_id = ?", entry.Id).First(&fill).Error; err != nil {
+		t.Fatal(err)
+	}
+	if fill.TxHash != entry.TxHash || fill.GasUsed == nil || *fill.GasUsed != 21000 || fill.EffectiveGasPrice == nil || fill.GasCost == nil {
+		t.Fatalf("fill = %+v", fill)
+	}
+
+	// 重复处理不会再次记录成交
+	if err := completeOutbox(entry, &types.Receipt{BlockNumber: big.NewInt(1), GasUsed: 21000}, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	var fills int64
+	global.DBEngine.Model(&model.Fill{}).Where("order_id = ?", order.OrderId).Count(&fills)
+	if fills != 1 {
+		t.Fatalf("%d fills recorded, want 1", fills)
+	}
+}
+
+func TestOutboxRevertedRollsBack(t *testing.T) {
+	sc, order := setupOutboxTest(t)
+	bid := createBid(t, order)
+	entry := sc.settle(t, order, sc.reverting, bid.BidId)
+	sc.commit(t)
+
+	if err := resolveOutbox(context.Background(), sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	failed, _ := expectState(t, entry.Id, model.OutboxStatusFailed, order.OrderId, model.OrderStatusOpen, 1)
+	if failed.LastError != "transaction reverted" {
+		t.Fatalf("last error = %q", failed.LastError)
+	}
+	var fills int64
+	global.DBEngine.Model(&model.Fill{}).Where("order_id = ?", order.OrderId).Count(&fills)
+	if fills != 0 {
+		t.Fatalf("%d fills recorded for reverted settlement", fills)
+	}
+	// 上链执行失败的出价不再参与结算
+	expectBidStatus(t, bid.BidId, model.BidStatusInvalid)
+}
+
+// TestOutboxDroppedReopensOrder 结算交易被丢弃时取消这次结算，订单重新上架而不是标记为cancelled，出价保持有效
+func TestOutboxDroppedReopensOrder(t *testing.T) {
+	sc, order := setupOutboxTest(t)
+	bid := createBid(t, order)
+	sc.sendMode.Store(sendReject)
+	entry := sc.settle(t, order, common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"), bid.BidId)
+
+	// 结算钱包用同一nonce发送了另一笔交易
+	sc.sendMode.Store(sendForward)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	other, err := types.SignNewTx(sc.key, types.LatestSignerForChainID(big.NewInt(simulatedChainId)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(simulatedChainId), Nonce: entry.Nonce, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(1e11), Gas: 21000, To: &to,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.Client.SendTransaction(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	sc.commit(t)
+
+	if err := resolveOutbox(context.Background(), sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	dropped, _ := expectState(t, entry.Id, model.OutboxStatusDropped, order.OrderId, model.OrderStatusOpen, 1)
+	if dropped.LastError != "nonce used by another transaction" {
+		t.Fatalf("last error = %q", dropped.LastError)
+	}
+	var fills int64
+	global.DBEngine.Model(&model.Fill{}).Where("order_id = ?", order.OrderId).Count(&fills)
+	if fills != 0 {
+		t.Fatalf("%d fills recorded for dropped settlement", fills)
+	}
+	expectBidStatus(t, bid.BidId, model.BidStatusActive)
+}
+
+// TestOutboxReplacesStuckTransaction 卡住的交易以相同nonce提高手续费替换，上链的替换交易归属到订单
+func TestOutboxReplacesStuckTransaction(t *testing.T) {
+	sc, order := setupOutboxTest(t)
+	sc.StuckTxTimeout, sc.FeeBumpPercent = time.Minute, 20
+	start := time.Now()
+	clock := setClock(t, start)
+	// 不出块，原交易一直停留在交易池中
+	entry := sc.settle(t, order, common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"), 0)
+	original := entry.TxHash
+
+	*clock = start.Add(30 * time.Second)
+	if err := resolveOutbox(context.Background(), sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.TxHash != original {
+		t.Fatal("transaction replaced before StuckTxTimeout")
+	}
+
+	*clock = start.Add(time.Minute)
+	if err := resolveOutbox(context.Background(), sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	replaced, _ := expectState(t, entry.Id, model.OutboxStatusSent, order.OrderId, model.OrderStatusSettling, 0)
+	if replaced.TxHash == original || replaced.Replacements != 1 || replaced.GasTipCap != "1200000000" || replaced.GasFeeCap != "120000000000" {
+		t.Fatalf("replaced outbox = %+v", replaced)
+	}
+
+	sc.commit(t)
+	if err := resolveOutbox(context.Background(), sc.Chain, replaced); err != nil {
+		t.Fatal(err)
+	}
+	_, filled := expectState(t, entry.Id, model.OutboxStatusConfirmed, order.OrderId, model.OrderStatusFilled, 0)
+	if filled.FilledTxHash == nil || *filled.FilledTxHash != replaced.TxHash {
+		t.Fatalf("filled tx hash = %v, want %s", filled.FilledTxHash, replaced.TxHash)
+	}
+	var txs []model.OutboxTx
+	if err := global.DBEngine.Where("outbox_id = ?", entry.Id).Order("id").Find(&txs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].TxHash != original || txs[1].TxHash != replaced.TxHash {
+		t.Fatalf("outbox txs = %+v", txs)
+	}
+}
+
+// TestLegacyChainFees 区块头没有BaseFee的链按gasPrice估算手续费，卡住的legacy交易替换为legacy交易
+func TestLegacyChainFees(t *testing.T) {
+	sc, order := setupOutboxTest(t)
+	sc.legacy.Store(true)
+	sc.StuckTxTimeout, sc.FeeBumpPercent = time.Minute, 20
+	ctx := context.Background()
+	suggested, err := sc.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gasPrice, gasFeeCap, gasTipCap, err := settlementFees(ctx, sc.Chain)
+	if err != nil || gasPrice.Cmp(suggested) != 0 || gasFeeCap != nil || gasTipCap != nil {
+		t.Fatalf("settlementFees = %v, %v, %v, %v; want gas price %s", gasPrice, gasFeeCap, gasTipCap, err, suggested)
+	}
+	sc.MaxGasFeeCap = big.NewInt(1)
+	if gasPrice, _, _, err := settlementFees(ctx, sc.Chain); err != nil || gasPrice.Int64() != 1 {
+		t.Fatalf("capped gas price = %v, %v; want 1", gasPrice, err)
+	}
+	sc.MaxGasFeeCap = nil
+	if estimated, err := estimateGasPrice(ctx, sc.Chain); err != nil || estimated.Cmp(suggested) != 0 {
+		t.Fatalf("estimateGasPrice = %v, %v; want %s", estimated, err, suggested)
+	}
+
+	start := time.Now()
+	clock := setClock(t, start)
+	entry := sc.settle(t, order, common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"), 0)
+	*clock = start.Add(time.Minute)
+	if err := resolveOutbox(ctx, sc.Chain, entry); err != nil {
+		t.Fatal(err)
+	}
+	replaced, _ := expectState(t, entry.Id, model.OutboxStatusSent, order.OrderId, model.OrderStatusSettling, 0)
+	if replaced.Replacements != 1 || replaced.GasFeeCap != "120000000000" || replaced.GasTipCap != "120000000000" {
+		t.Fatalf("replaced outbox = %+v", replaced)
+	}
+	tx, _, err := sc.Client.TransactionByHash(ctx, common.HexToHash(replaced.TxHash))
+	if err != nil || tx.Type() != types.LegacyTxType {
+		t.Fatalf("replacement = %v, %v; want legacy transaction", tx, err)
+	}
+
+	sc.commit(t)
+	if err := resolveOutbox(ctx, sc.Chain, replaced); err != nil {
+		t.Fatal(err)
+	}
+	expectState(t, entry.Id, model.OutboxStatusConfirmed, order.OrderId, model.OrderStatusFilled, 0)
+}
+
+func TestReplacementFees(t *testing.T) {
+	stuck := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000)})
+	tests := []struct {
+		name             string
+		feeBump          int64
+		maxGasFeeCap     int64
+		baseFee          *big.Int
+		wantFee, wantTip int64
+		wantErr          bool
+	}{
+		{"bump", 20, 0, big.NewInt(100), 1200, 120, false},
+		// 不低于2 * BaseFee + TipCap
+		{"base fee spike", 20, 0, big.NewInt(1000), 2120, 120, false},
+		// 链未启用EIP-1559时区块头没有BaseFee，只按涨幅提高
+		{"no base fee", 20, 0, nil, 1200, 120, false},
+		{"capped", 20, 1150, big.NewInt(100), 1150, 120, false},
+		{"cap below replacement minimum", 20, 1050, big.NewInt(100), 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orderChain := &chain.Chain{FeeBumpPercent: tt.feeBump}
+			if tt.maxGasFeeCap != 0 {
+				orderChain.MaxGasFeeCap = big.NewInt(tt.maxGasFeeCap)
+			}
+			gasFeeCap, gasTipCap, err := replacementFees(orderChain, stuck, tt.baseFee)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("replacementFees = %s, %s; want error", gasFeeCap, gasTipCap)
+				}
+				return
+			}
+			if err != nil || gasFeeCap.Int64() != tt.wantFee || gasTipCap.Int64() != tt.wantTip {
+				t.Fatalf("replacementFees = %v, %v, %v; want %d, %d", gasFeeCap, gasTipCap, err, tt.wantFee, tt.wantTip)
+			}
+		})
+	}
+}
+
+func TestOutboxRecoversAfterCrash(t *testing.T) {
+	tests := []struct {
+		name      string
+		sendMode  int32
+		recovered string // 重启恢复后的发件箱状态，此时交易都已在交易池中
+	}{
+		// 交易没有到达节点，恢复时用落库的原始交易重新广播
+		{"broadcast never reached node", sendReject, model.OutboxStatusSent},
+		// 节点已收到交易，恢复时发现交易在交易池中，等待上链
+		{"broadcast response lost", sendLost, model.OutboxStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, order := setupOutboxTest(t)
+			sc.sendMode.Store(tt.sendMode)
+			entry := sc.settle(t, order, common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"), 0)
+			pending, _ := expectState(t, entry.Id, model.OutboxStatusPending, order.OrderId, model.OrderStatusSettling, 0)
+			if pending.LastError == "" {
+				t.Fatal("broadcast error not recorded")
+			}
+
+			// 进程重启，启动恢复只依赖数据库中的发件箱记录
+			sc.sendMode.Store(sendForward)
+			if err := RecoverOutbox(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			expectState(t, entry.Id, tt.recovered, order.OrderId, model.OrderStatusSettling, 0)
+			if _, isPending, err := sc.Client.TransactionByHash(context.Background(), common.HexToHash(entry.TxHash)); err != nil || !isPending {
+				t.Fatalf("transaction not in pool after recovery: pending %v, %v", isPending, err)
+			}
+
+			sc.commit(t)
+			if err := RecoverOutbox(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			expectState(t, entry.Id, model.OutboxStatusConfirmed, order.OrderId, model.OrderStatusFilled, 0)
+		})
+	}
+}
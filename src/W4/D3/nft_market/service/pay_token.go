@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"nftmarket/chain"
+	"nftmarket/contract"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ethPayToken 内置的ETH支付代币，所有链均支持
+func ethPayToken(chainId int64) model.PayToken {
+	return model.PayToken{ChainId: chainId, Address: validate.ETHFlag.Hex(), Symbol: "ETH", Decimals: 18, BuiltIn: true}
+}
+
+// ListPayTokens 展示链上允许的支付代币，未指定chain_id时为默认链
+func ListPayTokens(c *gin.Context) {
+	tokenChain, ok := queryChain(c)
+	if !ok {
+		return
+	}
+	if tokenChain == nil {
+		tokenChain = global.Chains.Default()
+	}
+	tokens, err := PayTokens(tokenChain)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// AddPayToken 登记支付代币(管理员接口)
+func AddPayToken(c *gin.Context) {
+	var input struct {
+		Address string `json:"address" binding:"required,eth_addr"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	tokenChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	token, err := RegisterPayToken(c.Request.Context(), tokenChain, input.Address)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+// RemovePayToken 取消登记支付代币(管理员接口)，已上架的订单不受影响
+func RemovePayToken(c *gin.Context) {
+	var input struct {
+		Address string `json:"address" binding:"required,eth_addr"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	tokenChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	token, err := UnregisterPayToken(tokenChain, input.Address)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+// PayTokens 链上允许的支付代币，内置的ETH在最前
+func PayTokens(tokenChain *chain.Chain) ([]model.PayToken, error) {
+	var tokens []model.PayToken
+	if err := global.DBEngine.Where("chain_id = ?", tokenChain.ID).Order("id").Find(&tokens).Error; err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to fetch pay tokens")
+	}
+	return append([]model.PayToken{ethPayToken(tokenChain.ID)}, tokens...), nil
+}
+
+// RegisterPayToken 从ERC20合约读取symbol和decimals后登记支付代币，已登记时返回409
+func RegisterPayToken(ctx context.Context, tokenChain *chain.Chain, address string) (*model.PayToken, error) {
+	token := common.HexToAddress(address)
+	if token == validate.ETHFlag {
+		return nil, invalidError("ETH is a built-in pay token")
+	}
+	symbol, decimals, err := erc20Metadata(ctx, tokenChain, token)
+	if err != nil {
+		return nil, invalidError(fmt.Sprintf("failed to read ERC20 metadata of %s on chain %d: %v", token.Hex(), tokenChain.ID, err))
+	}
+	record := &model.PayToken{ChainId: tokenChain.ID, Address: token.Hex(), Symbol: symbol, Decimals: decimals}
+	result := global.DBEngine.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to save pay token")
+	}
+	if result.RowsAffected == 0 {
+		return nil, statusError(http.StatusConflict, "Pay token already registered")
+	}
+	return record, nil
+}
+
+// UnregisterPayToken 取消登记支付代币，之后不能再用它上架新订单
+func UnregisterPayToken(tokenChain *chain.Chain, address string) (*model.PayToken, error) {
+	if common.HexToAddress(address) == validate.ETHFlag {
+		return nil, invalidError("ETH is a built-in pay token")
+	}
+	token, err := findPayToken(tokenChain.ID, address)
+	if err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to fetch pay token")
+	}
+	if token == nil {
+		return nil, statusError(http.StatusNotFound, "Pay token not registered")
+	}
+	if err := global.DBEngine.Delete(token).Error; err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to remove pay token")
+	}
+	return token, nil
+}
+
+// ImportPayTokens 将配置的支付代币登记到每条链上，已登记的跳过，读取合约失败时只记录日志
+func ImportPayTokens(ctx context.Context, addresses []string) {
+	for _, tokenChain := range global.Chains.All() {
+		for _, address := range addresses {
+			_, err := RegisterPayToken(ctx, tokenChain, address)
+			var serviceErr *Error
+			if errors.As(err, &serviceErr) && serviceErr.Status == http.StatusConflict {
+				continue
+			}
+			if err != nil {
+				log.Printf("import pay token %s on chain %d: %v", address, tokenChain.ID, err)
+			}
+		}
+	}
+}
+
+// findPayToken 查询订单所在链上登记的支付代币，未登记时返回nil
+func findPayToken(chainId int64, address string) (*model.PayToken, error) {
+	tokenChain, err := global.Chains.Get(chainId)
+	if err != nil {
+		return nil, err
+	}
+	if common.HexToAddress(address) == validate.ETHFlag {
+		token := ethPayToken(tokenChain.ID)
+		return &token, nil
+	}
+	var token model.PayToken
+	err = global.DBEngine.Where("chain_id = ? AND address = ?", tokenChain.ID, common.HexToAddress(address).Hex()).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// erc20Metadata 读取ERC20合约的symbol和decimals
+func erc20Metadata(ctx context.Context, tokenChain *chain.Chain, token common.Address) (string, uint8, error) {
+	metadataAbi, err := abi.JSON(strings.NewReader(contract.ERC20MetadataABI))
+	if err != nil {
+		return "", 0, err
+	}
+	bound := bind.NewBoundContract(token, metadataAbi, tokenChain.Client, tokenChain.Client, tokenChain.Client)
+	var symbol, decimals []interface{}
+	if err := bound.Call(&bind.CallOpts{Context: ctx}, &symbol, "symbol"); err != nil {
+		return "", 0, err
+	}
+	if err := bound.Call(&bind.CallOpts{Context: ctx}, &decimals, "decimals"); err != nil {
+		return "", 0, err
+	}
+	return symbol[0].(string), decimals[0].(uint8), nil
+}
+
+// parseTokenAmount 解析按代币单位填写的价格，如"1.5"或"1.5 USDC"，带符号时需与支付代币一致
+func parseTokenAmount(value string, token *model.PayToken) (*big.Int, error) {
+	amount, symbol, _ := strings.Cut(strings.TrimSpace(value), " ")
+	if symbol = strings.TrimSpace(symbol); symbol != "" && !strings.EqualFold(symbol, token.Symbol) {
+		return nil, fmt.Errorf("%q is not priced in %s", value, token.Symbol)
+	}
+	raw, err := utils.ParseUnits(amount, token.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	if raw.Sign() <= 0 {
+		return nil, fmt.Errorf("%q must be positive", value)
+	}
+	return raw, nil
+}
+
+// formatTokenAmount 将最小单位的价格格式化为"1.5 USDC"，value为空时返回空
+func formatTokenAmount(value string, token *model.PayToken) string {
+	raw, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return ""
+	}
+	return utils.FormatUnits(raw, token.Decimals) + " " + token.Symbol
+}
+
+// resolveHumanPrices 将请求中按代币单位填写的价格换算为最小单位，同时填写两种形式时必须一致
+func resolveHumanPrices(request *model.SellOrderRequest, token *model.PayToken) error {
+	resolve := func(name, human string, raw *string) error {
+		if human == "" {
+			return nil
+		}
+		amount, err := parseTokenAmount(human, token)
+		if err != nil {
+			return fmt.Errorf("human_%s: %w", name, err)
+		}
+		if *raw != "" && *raw != amount.String() {
+			return fmt.Errorf("%s %s does not match human_%s %s", name, *raw, name, human)
+		}
+		*raw = amount.String()
+		return nil
+	}
+	var price string
+	if request.Price != 0 {
+		price = fmt.Sprint(request.Price)
+	}
+	if err := resolve("price", request.HumanPrice, &price); err != nil {
+		return err
+	}
+	if price != "" {
+		raw, _ := new(big.Int).SetString(price, 10)
+		if !raw.IsInt64() {
+			return fmt.Errorf("price %s exceeds the supported range", price)
+		}
+		request.Price = raw.Int64()
+	}
+	if err := resolve("start_price", request.HumanStartPrice, &request.StartPrice); err != nil {
+		return err
+	}
+	if err := resolve("end_price", request.HumanEndPrice, &request.EndPrice); err != nil {
+		return err
+	}
+	if err := resolve("discount_price", request.HumanDiscountPrice, &request.DiscountPrice); err != nil {
+		return err
+	}
+	if (request.MerkleRoot == "") != (request.DiscountPrice == "") {
+		return errors.New("merkle_root and discount_price must be set together")
+	}
+	return nil
+}
+
+// fillPriceDisplay 按支付代币精度填写订单价格的可读形式，支付代币未登记时不填写
+func fillPriceDisplay(order *model.Order, token *model.PayToken) {
+	if token == nil {
+		return
+	}
+	sellOrder := order.SellOrder
+	display := &model.PriceDisplay{
+		Symbol:        token.Symbol,
+		Decimals:      token.Decimals,
+		StartPrice:    formatTokenAmount(sellOrder.StartPrice, token),
+		EndPrice:      formatTokenAmount(sellOrder.EndPrice, token),
+		DiscountPrice: formatTokenAmount(sellOrder.DiscountPrice, token),
+		CurrentPrice:  formatTokenAmount(order.CurrentPrice, token),
+		HighestBid:    formatTokenAmount(order.HighestBid, token),
+	}
+	if sellOrder.Price != 0 {
+		display.Price = formatTokenAmount(fmt.Sprint(sellOrder.Price), token)
+	}
+	if order.FilledPrice != nil {
+		display.FilledPrice = formatTokenAmount(*order.FilledPrice, token)
+	}
+	order.PriceDisplay = display
+}
+
+// describeOrder 查询订单的支付代币并填写价格的可读形式，查询失败时不填写
+func describeOrder(order *model.Order) {
+	token, err := findPayToken(order.SellOrder.ChainId, order.SellOrder.PayToken)
+	if err != nil {
+		log.Printf("find pay token of order %d: %v", order.OrderId, err)
+		return
+	}
+	fillPriceDisplay(order, token)
+}
+
+// describeOrders 批量填写订单价格的可读形式，只查询一次支付代币登记表
+func describeOrders(orders []model.Order) error {
+	lookup, err := payTokenLookup()
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		fillPriceDisplay(&orders[i], lookup(orders[i].SellOrder.ChainId, orders[i].SellOrder.PayToken))
+	}
+	return nil
+}
+
+// payTokenLookup 一次读取整个支付代币登记表，返回按链和地址查询的函数，未登记时返回nil
+func payTokenLookup() (func(chainId int64, address string) *model.PayToken, error) {
+	var tokens []model.PayToken
+	if err := global.DBEngine.Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	registry := make(map[string]*model.PayToken, len(tokens))
+	for i := range tokens {
+		registry[fmt.Sprintf("%d/%s", tokens[i].ChainId, tokens[i].Address)] = &tokens[i]
+	}
+	return func(chainId int64, address string) *model.PayToken {
+		tokenChain, err := global.Chains.Get(chainId)
+		if err != nil {
+			return nil
+		}
+		payToken := common.HexToAddress(address)
+		if payToken == validate.ETHFlag {
+			eth := ethPayToken(tokenChain.ID)
+			return &eth
+		}
+		return registry[fmt.Sprintf("%d/%s", tokenChain.ID, payToken.Hex())]
+	}, nil
+}
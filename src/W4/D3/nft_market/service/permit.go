@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"nftmarket/chain"
+	"nftmarket/contract"
+	"nftmarket/internal/model"
+	"nftmarket/internal/permit"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// verifyBuyPermit 离线校验买家的授权签名：签名人为买家、spender为市场合约、额度等于成交价格、未过期且nonce有效
+func verifyBuyPermit(orderChain *chain.Chain, buyer string, payToken string, price *big.Int, buyPermit *model.BuyPermit) error {
+	token := common.HexToAddress(payToken)
+	if token == validate.ETHFlag {
+		return errors.New("permit is not supported for ETH orders")
+	}
+	if buyPermit.Deadline < now().Unix() {
+		return errors.New("permit deadline exceeded")
+	}
+	owner := common.HexToAddress(buyer)
+	deadline := big.NewInt(buyPermit.Deadline)
+
+	var digest common.Hash
+	switch buyPermit.Type {
+	case permit.TypeEIP2612:
+		tokenAbi, err := abi.JSON(strings.NewReader(contract.ERC20PermitABI))
+		if err != nil {
+			return err
+		}
+		bound := bind.NewBoundContract(token, tokenAbi, orderChain.Client, orderChain.Client, orderChain.Client)
+		var domainSeparator, nonce []interface{}
+		if err := bound.Call(nil, &domainSeparator, "DOMAIN_SEPARATOR"); err != nil {
+			return fmt.Errorf("pay token does not support EIP-2612 permit: %w", err)
+		}
+		if err := bound.Call(nil, &nonce, "nonces", owner); err != nil {
+			return fmt.Errorf("pay token does not support EIP-2612 permit: %w", err)
+		}
+		digest = permit.EIP2612Digest(domainSeparator[0].([32]byte), owner, orderChain.MarketAddress, price, nonce[0].(*big.Int), deadline)
+	case permit.TypePermit2:
+		nonce, ok := new(big.Int).SetString(buyPermit.Nonce, 10)
+		if !ok || nonce.Sign() < 0 {
+			return errors.New("invalid permit2 nonce")
+		}
+		permit2Abi, err := abi.JSON(strings.NewReader(contract.Permit2ABI))
+		if err != nil {
+			return err
+		}
+		bound := bind.NewBoundContract(permit.Permit2Address, permit2Abi, orderChain.Client, orderChain.Client, orderChain.Client)
+		var bitmap []interface{}
+		if err := bound.Call(nil, &bitmap, "nonceBitmap", owner, permit.Permit2WordPos(nonce)); err != nil {
+			return fmt.Errorf("failed to query permit2 nonce: %w", err)
+		}
+		if permit.Permit2NonceUsed(bitmap[0].(*big.Int), nonce) {
+			return errors.New("permit2 nonce already used")
+		}
+		// Permit2对合约钱包owner调用ERC-1271 isValidSignature校验
+		digest := permit.Permit2Digest(big.NewInt(orderChain.ID), token, price, orderChain.MarketAddress, nonce, deadline)
+		valid, err := orderChain.VerifySignature(context.Background(), owner, digest, buyPermit.Signature)
+		if err != nil {
+			return fmt.Errorf("invalid permit signature: %w", err)
+		}
+		if !valid {
+			return errors.New("permit is not signed by buyer")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported permit type %q", buyPermit.Type)
+	}
+
+	// EIP-2612的permit在代币合约中只通过ecrecover校验，合约钱包无法使用
+	signer, err := utils.RecoverHash(digest.Bytes(), buyPermit.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid permit signature: %w", err)
+	}
+	if signer != owner {
+		return errors.New("permit is not signed by buyer")
+	}
+	return nil
+}
+
+// permitArgument 将已校验的授权签名转换为合约buyNFTWithPermit/buyNFTWithPermit2的参数
+func permitArgument(orderChain *chain.Chain, buyer string, buyPermit *model.BuyPermit) (interface{}, error) {
+	signature, err := hexutil.Decode(buyPermit.Signature)
+	if err != nil {
+		return nil, err
+	}
+	deadline := big.NewInt(buyPermit.Deadline)
+	if buyPermit.Type == permit.TypePermit2 {
+		// 合约钱包的签名原样交给isValidSignature，只有EOA签名需要调整v值
+		isContract, err := orderChain.Signatures.IsContract(context.Background(), common.HexToAddress(buyer))
+		if err != nil {
+			return nil, err
+		}
+		if !isContract {
+			normalizeV(signature)
+		}
+		nonce, _ := new(big.Int).SetString(buyPermit.Nonce, 10)
+		return contract.NFTMarketPermit2Signature{Nonce: nonce, Deadline: deadline, Signature: signature}, nil
+	}
+	if len(signature) != 65 {
+		return nil, errors.New("invalid permit signature length")
+	}
+	normalizeV(signature)
+	var r, s [32]byte
+	copy(r[:], signature[:32])
+	copy(s[:], signature[32:64])
+	return contract.NFTMarketPermitSignature{Deadline: deadline, V: signature[64], R: r, S: s}, nil
+}
+
+// normalizeV 合约中ecrecover要求v为27/28
+func normalizeV(signature []byte) {
+	if len(signature) == 65 && signature[64] < 27 {
+		signature[64] += 27
+	}
+}
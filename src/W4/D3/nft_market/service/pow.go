@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/pow"
+	"nftmarket/internal/validate"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// 需要工作量证明印章的资源
+const (
+	PowResourceCreate  = "create"  // /market/create 和 gRPC CreateOrder
+	PowResourceKeyPair = "keypair" // /keypair
+)
+
+// powIssuer 为nil时不要求印章
+var powIssuer *pow.Issuer
+
+// SetProofOfWork 设置工作量证明签发器，issuer为nil时关闭，在启动服务前调用
+func SetProofOfWork(issuer *pow.Issuer) {
+	powIssuer = issuer
+}
+
+// PowChallengeResponse 挑战，Required为false时不需要提交印章
+type PowChallengeResponse struct {
+	Required bool `json:"required"`
+	*pow.Challenge
+}
+
+// PowChallenge 获取上架或生成密钥对前需要求解的工作量证明挑战
+func PowChallenge(c *gin.Context) {
+	var query struct {
+		Resource string `form:"resource" json:"resource" binding:"required,oneof=create keypair"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	challenge, err := IssuePowChallenge(c.Request.Context(), query.Resource, PowClient(c.GetInt64(ApiKeyIdContextKey), c.ClientIP()))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, challenge)
+}
+
+// IssuePowChallenge 为调用方签发resource的挑战，未开启时返回Required为false
+func IssuePowChallenge(ctx context.Context, resource, client string) (*PowChallengeResponse, error) {
+	if powIssuer == nil {
+		return &PowChallengeResponse{}, nil
+	}
+	challenge, err := powIssuer.Issue(ctx, resource, client)
+	if err != nil {
+		log.Printf("issue pow challenge: %v", err)
+		return nil, statusError(http.StatusInternalServerError, "Failed to issue challenge")
+	}
+	return &PowChallengeResponse{Required: true, Challenge: challenge}, nil
+}
+
+// VerifyPowStamp 校验调用方提交的resource印章，未开启时直接通过
+func VerifyPowStamp(ctx context.Context, stamp, resource, client string) error {
+	if powIssuer == nil {
+		return nil
+	}
+	if stamp == "" {
+		return statusError(http.StatusForbidden, fmt.Sprintf("Proof-of-work stamp required, get a challenge from /pow/challenge?resource=%s", resource))
+	}
+	err := powIssuer.Verify(ctx, stamp, resource, client)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, pow.ErrInvalidStamp):
+		// 印章不是签发给该调用方和资源的，或被篡改
+		return statusError(http.StatusForbidden, "Invalid proof-of-work stamp")
+	case errors.Is(err, pow.ErrStampExpired), errors.Is(err, pow.ErrStampReplayed), errors.Is(err, pow.ErrInsufficientWork):
+		return statusError(http.StatusForbidden, err.Error())
+	default:
+		log.Printf("verify pow stamp: %v", err)
+		return statusError(http.StatusInternalServerError, "Failed to verify proof-of-work stamp")
+	}
+}
+
+// 每记录多少个印章清理一次过期记录
+const powStampPruneInterval = 256
+
+var powStampUses atomic.Int64
+
+// PowStampStore 将已使用的印章保存在数据库中，nonce为主键，多实例部署时共享重放记录和调用方的使用频率
+type PowStampStore struct{}
+
+func (PowStampStore) Use(ctx context.Context, nonce, client string, usedAt, keepUntil time.Time) error {
+	db := global.DBEngine.WithContext(ctx)
+	if powStampUses.Add(1)%powStampPruneInterval == 0 {
+		db.Where("expires_at < ?", usedAt.Unix()).Delete(&model.PowStamp{})
+	}
+	record := &model.PowStamp{Nonce: nonce, Client: client, UsedAt: usedAt.Unix(), ExpiresAt: keepUntil.Unix()}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return pow.ErrStampReplayed
+	}
+	return nil
+}
+
+func (PowStampStore) Count(ctx context.Context, client string, since time.Time) (int, error) {
+	var count int64
+	err := global.DBEngine.WithContext(ctx).Model(&model.PowStamp{}).
+		Where("client = ? AND used_at >= ?", client, since.Unix()).Count(&count).Error
+	return int(count), err
+}
+
+// PowClient 挑战绑定的调用方，有API key时按key区分，否则按客户端IP
+// ip需为只信任可信代理的ClientIP(见RateLimit.TrustedProxies)，否则调用方可伪造X-Forwarded-For重置难度
+func PowClient(apiKeyId int64, ip string) string {
+	if apiKeyId != 0 {
+		return fmt.Sprintf("key-%d", apiKeyId)
+	}
+	return "ip-" + ip
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"nftmarket/internal/model"
+	"nftmarket/internal/pow"
+	"testing"
+	"time"
+)
+
+func TestPowStampStore(t *testing.T) {
+	setupTestDB(t, &model.PowStamp{})
+	ctx := context.Background()
+	store := PowStampStore{}
+	start := time.Unix(1700000000, 0)
+
+	for i, nonce := range []string{"n1", "n2", "n3"} {
+		usedAt := start.Add(time.Duration(i) * 20 * time.Second)
+		if err := store.Use(ctx, nonce, "ip-1", usedAt, usedAt.Add(2*time.Minute)); err != nil {
+			t.Fatalf("Use(%s): %v", nonce, err)
+		}
+	}
+	if err := store.Use(ctx, "other", "ip-2", start, start.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	// nonce唯一，其他调用方或实例提交同一印章同样被拒绝
+	if err := store.Use(ctx, "n2", "ip-2", start.Add(time.Minute), start.Add(3*time.Minute)); !errors.Is(err, pow.ErrStampReplayed) {
+		t.Fatalf("replayed nonce: %v", err)
+	}
+
+	tests := []struct {
+		client string
+		since  time.Time
+		want   int
+	}{
+		{"ip-1", start, 3},
+		{"ip-1", start.Add(20 * time.Second), 2},
+		{"ip-1", start.Add(41 * time.Second), 0},
+		{"ip-2", start, 1},
+		{"ip-3", start, 0},
+	}
+	for _, tt := range tests {
+		count, err := store.Count(ctx, tt.client, tt.since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tt.want {
+			t.Errorf("Count(%s, +%v) = %d, want %d", tt.client, tt.since.Sub(start), count, tt.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/response"
+	"nftmarket/internal/validate"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RelayerFeePolicy 中继费用策略，结算交易由平台钱包发送，按策略向买家收取费用以回收gas
+type RelayerFeePolicy struct {
+	Mode          string        // 见model.RelayerFee*，none时购买不需要报价
+	Flat          *big.Int      // flat模式的每笔费用
+	PercentBps    int64         // percent模式按成交价格收取的万分比
+	GasMarkupBps  int64         // gas_plus模式在预估gas费用上加收的万分比
+	GasEstimate   uint64        // gas_plus模式预估的结算交易gas用量
+	QuoteTTL      time.Duration // 报价有效期
+	PriceCurrency string        // gas_plus模式将gas费用换算为ERC20支付代币时使用的FiatPrices币种
+}
+
+var relayerFeePolicy = RelayerFeePolicy{Mode: model.RelayerFeeNone, QuoteTTL: 2 * time.Minute, PriceCurrency: "USD"}
+
+// errNoTokenPrice 价格表中缺少换算gas费用所需的价格，无法按gas_plus模式报价
+var errNoTokenPrice = errors.New("no price to convert gas cost into the pay token")
+
+// SetRelayerFeePolicy 设置中继费用策略，在启动服务前调用
+func SetRelayerFeePolicy(policy RelayerFeePolicy) {
+	relayerFeePolicy = policy
+}
+
+// QuoteResponse 中继费用报价，买家需对Message签名，授权额度为Total
+type QuoteResponse struct {
+	Quote   *model.RelayerQuote `json:"quote"`
+	Total   string              `json:"total"`   // 成交价格 + 中继费用
+	Message string              `json:"message"` // 需要personal_sign签名的报价消息
+}
+
+// RelayerPnL 按天或NFT合约统计的中继盈亏，费用以支付代币计，gas以wei计，只有ETH支付时净收益才有意义
+type RelayerPnL struct {
+	Key            string  `json:"key"` // 日期(UTC，YYYY-MM-DD)或NFT合约地址
+	PayToken       string  `json:"pay_token"`
+	Fills          int64   `json:"fills"`
+	UntrackedFills int64   `json:"untracked_fills"` // 没有记录gas消耗的历史成交
+	GasUsed        int64   `json:"gas_used"`
+	GasCost        string  `json:"gas_cost"`
+	RelayerFee     string  `json:"relayer_fee"`
+	Net            *string `json:"net,omitempty"` // relayer_fee - gas_cost，仅ETH支付
+}
+
+// QuoteBuy 购买前获取中继费用报价
+func QuoteBuy(c *gin.Context) {
+	var input model.QuoteRequest
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	quote, err := CreateQuote(c.Request.Context(), input)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// CreateQuote 按当前价格和中继费用策略生成报价并落库，报价只在有效期内、对同一订单、买家和数量有效
+func CreateQuote(ctx context.Context, input model.QuoteRequest) (*QuoteResponse, error) {
+	var order model.Order
+	if err := global.DBEngine.First(&order, input.OrderId).Error; err != nil {
+		return nil, statusError(http.StatusNotFound, "Order not found")
+	}
+	amount := buyAmount(input.Amount)
+	current := now()
+	price, err := purchasePrice(&order, input.Buyer, amount, input.Proof, current)
+	if err != nil {
+		return nil, err
+	}
+	orderChain, err := global.Chains.Get(order.SellOrder.ChainId)
+	if err != nil {
+		return nil, statusError(http.StatusBadRequest, err.Error())
+	}
+	fee, gasCost, err := relayerFee(ctx, orderChain, order.SellOrder.PayToken, price)
+	if errors.Is(err, errNoTokenPrice) {
+		return nil, statusError(http.StatusServiceUnavailable, err.Error())
+	}
+	if err != nil {
+		return nil, statusError(http.StatusBadGateway, "Failed to estimate gas cost")
+	}
+
+	quote := &model.RelayerQuote{
+		ChainId:    orderChain.ID,
+		OrderId:    order.OrderId,
+		Buyer:      common.HexToAddress(input.Buyer).Hex(),
+		Amount:     amount,
+		Price:      price.String(),
+		RelayerFee: fee.String(),
+		FeeMode:    relayerFeePolicy.Mode,
+		ExpiresAt:  current.Add(relayerFeePolicy.QuoteTTL).Unix(),
+	}
+	if gasCost != nil {
+		quote.GasCost = gasCost.String()
+	}
+	if err := global.DBEngine.Create(quote).Error; err != nil {
+		return nil, statusError(http.StatusInternalServerError, "Failed to create quote")
+	}
+	return &QuoteResponse{
+		Quote:   quote,
+		Total:   new(big.Int).Add(price, fee).String(),
+		Message: quote.Message(),
+	}, nil
+}
+
+// relayerFee 按策略计算中继费用，gas_plus模式同时返回预估的gas费用(wei)
+// gas价格见estimateGasPrice，ERC20支付的订单按价格表将加成后的gas费用换算为支付代币
+func relayerFee(ctx context.Context, orderChain *chain.Chain, payToken string, price *big.Int) (*big.Int, *big.Int, error) {
+	policy := relayerFeePolicy
+	flat := new(big.Int)
+	if policy.Flat != nil {
+		flat.Set(policy.Flat)
+	}
+	switch policy.Mode {
+	case model.RelayerFeeFlat:
+		return flat, nil, nil
+	case model.RelayerFeePercent:
+		fee := new(big.Int).Mul(price, big.NewInt(policy.PercentBps))
+		return fee.Div(fee, big.NewInt(10000)), nil, nil
+	case model.RelayerFeeGasPlus:
+		gasPrice, err := estimateGasPrice(ctx, orderChain)
+		if err != nil {
+			return nil, nil, err
+		}
+		gasCost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(policy.GasEstimate))
+		fee := new(big.Int).Mul(gasCost, big.NewInt(10000+policy.GasMarkupBps))
+		fee.Div(fee, big.NewInt(10000))
+		if common.HexToAddress(payToken) == validate.ETHFlag {
+			return fee, gasCost, nil
+		}
+		tokenFee, err := gasInPayToken(orderChain.ID, payToken, fee, now().Unix())
+		if err != nil {
+			return nil, nil, err
+		}
+		return tokenFee, gasCost, nil
+	default:
+		return new(big.Int), nil, nil
+	}
+}
+
+// estimateGasPrice 预估结算交易的gas价格，BaseFee + 建议的TipCap，链未启用EIP-1559时为节点建议的gasPrice
+func estimateGasPrice(ctx context.Context, orderChain *chain.Chain) (*big.Int, error) {
+	header, err := orderChain.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return orderChain.Client.SuggestGasPrice(ctx)
+	}
+	gasTipCap, err := orderChain.Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Add(header.BaseFee, gasTipCap), nil
+}
+
+// gasInPayToken 按价格表中同一币种的ETH和支付代币价格，将以wei计的费用换算为支付代币的最小单位，向上取整
+// 支付代币的精度取自登记表
+func gasInPayToken(chainId int64, payToken string, wei *big.Int, at int64) (*big.Int, error) {
+	currency := relayerFeePolicy.PriceCurrency
+	ethPrice := fiatPrices.lookup(currency, chainId, validate.ETHFlag.Hex(), at)
+	tokenPrice := fiatPrices.lookup(currency, chainId, payToken, at)
+	if ethPrice == nil || tokenPrice == nil || tokenPrice.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: FiatPrices needs %s prices for ETH and %s on chain %d", errNoTokenPrice, currency, payToken, chainId)
+	}
+	token, err := findPayToken(chainId, payToken)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, fmt.Errorf("%w: pay token %s is not registered on chain %d", errNoTokenPrice, payToken, chainId)
+	}
+	// wei / 10^18 * ethPrice / tokenPrice * 10^decimals
+	amount := new(big.Rat).SetFrac(wei, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	amount.Mul(amount, ethPrice)
+	amount.Quo(amount, tokenPrice)
+	amount.Mul(amount, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.Decimals)), nil)))
+	fee, remainder := new(big.Int).QuoRem(amount.Num(), amount.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		fee.Add(fee, big.NewInt(1))
+	}
+	return fee, nil
+}
+
+// quoteFee 报价中的中继费用，没有报价时为0
+func quoteFee(quote *model.RelayerQuote) *big.Int {
+	if quote == nil {
+		return new(big.Int)
+	}
+	fee, ok := new(big.Int).SetString(quote.RelayerFee, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return fee
+}
+
+// verifyQuote 校验购买请求携带的报价：订单、买家和数量一致，未使用、未过期，当前价格不高于报价价格，且由买家签名
+// 中继费用策略为none时不需要报价，携带的报价同样校验
+func verifyQuote(orderChain *chain.Chain, order *model.Order, input model.BuyRequest, amount int64, price *big.Int) (*model.RelayerQuote, error) {
+	if input.Quote == nil {
+		if relayerFeePolicy.Mode != model.RelayerFeeNone {
+			return nil, statusError(http.StatusBadRequest, "Relayer fee quote required, request one from /market/quote")
+		}
+		return nil, nil
+	}
+	var quote model.RelayerQuote
+	if err := global.DBEngine.First(&quote, input.Quote.Id).Error; err != nil {
+		return nil, statusError(http.StatusBadRequest, "Quote not found")
+	}
+	if quote.OrderId != order.OrderId || !strings.EqualFold(quote.Buyer, input.Buyer) || quote.Amount != amount {
+		return nil, statusError(http.StatusBadRequest, "Quote does not match the order, buyer or amount")
+	}
+	if quote.UsedAt != 0 {
+		return nil, statusError(http.StatusConflict, "Quote already used, request a new quote")
+	}
+	if now().Unix() > quote.ExpiresAt {
+		return nil, statusError(http.StatusBadRequest, "Quote expired, request a new quote")
+	}
+	// 荷兰拍价格只会下降，买家按当前价格成交；价格高于报价时需要重新报价
+	quotedPrice, _ := new(big.Int).SetString(quote.Price, 10)
+	if quotedPrice == nil || price.Cmp(quotedPrice) > 0 {
+		return nil, statusError(http.StatusBadRequest, "Price changed since quote, request a new quote")
+	}
+	// 买家为合约钱包时通过ERC-1271校验
+	digest := accounts.TextHash([]byte(quote.Message()))
+	valid, err := orderChain.VerifySignature(context.Background(), common.HexToAddress(input.Buyer), common.BytesToHash(digest), input.Quote.Signature)
+	if err != nil || !valid {
+		return nil, statusError(http.StatusBadRequest, "Quote must be signed by the buyer")
+	}
+	return &quote, nil
+}
+
+// ListRelayerPnL 按天或NFT合约统计中继费用收入和结算gas成本
+func ListRelayerPnL(c *gin.Context) {
+	var query struct {
+		GroupBy string `form:"group_by" json:"group_by" binding:"omitempty,oneof=day collection"`
+		From    int64  `form:"from" json:"from" binding:"gte=0"` // 区块时间下限(unix秒，含)
+		To      int64  `form:"to" json:"to" binding:"gte=0"`     // 区块时间上限(unix秒，不含)
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	pnlChain, ok := queryChain(c)
+	if !ok {
+		return
+	}
+	result, err := relayerPnL(global.DBEngine, pnlChain, query.GroupBy, query.From, query.To)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to calculate relayer pnl")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// relayerPnL 按分组和支付代币聚合成交记录的gas成本与中继费用，groupBy为空时按天(UTC)
+func relayerPnL(db *gorm.DB, pnlChain *chain.Chain, groupBy string, from, to int64) ([]RelayerPnL, error) {
+	fills := db.Table("order_fill AS f").Select(`o.chain_id, o.nft, o.pay_token, f.gas_used, f.gas_cost, f.relayer_fee, f.block_timestamp,
+		to_char(to_timestamp(f.block_timestamp) AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day`).
+		Joins(`JOIN "order" o ON o.order_id = f.order_id`)
+	key := "day"
+	if groupBy == "collection" {
+		key = "nft"
+	}
+	tx := db.Table("(?) AS fill", fills).Select(key + ` AS key, pay_token,
+		COUNT(*) AS fills,
+		COUNT(*) FILTER (WHERE gas_used IS NULL) AS untracked_fills,
+		COALESCE(SUM(gas_used), 0) AS gas_used,
+		COALESCE(SUM(gas_cost::numeric), 0)::text AS gas_cost,
+		COALESCE(SUM(relayer_fee::numeric), 0)::text AS relayer_fee`)
+	if pnlChain != nil {
+		tx = tx.Scopes(pnlChain.Scope)
+	}
+	if from > 0 {
+		tx = tx.Where("block_timestamp >= ?", from)
+	}
+	if to > 0 {
+		tx = tx.Where("block_timestamp < ?", to)
+	}
+	result := []RelayerPnL{}
+	if err := tx.Group("key, pay_token").Order("key, pay_token").Scan(&result).Error; err != nil {
+		return nil, err
+	}
+	for i := range result {
+		if common.HexToAddress(result[i].PayToken) != validate.ETHFlag {
+			continue
+		}
+		fee, ok1 := new(big.Int).SetString(result[i].RelayerFee, 10)
+		cost, ok2 := new(big.Int).SetString(result[i].GasCost, 10)
+		if !ok1 || !ok2 {
+			return nil, errors.New("invalid relayer pnl amount")
+		}
+		net := new(big.Int).Sub(fee, cost).String()
+		result[i].Net = &net
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/utils"
+)
+
+// 迁移卖家公钥时每批读取的订单数
+const pubKeyMigrateBatchSize = 500
+
+// canonicalPublicKey 解析任意支持格式的公钥并转为PEM，订单只保存这一种格式
+func canonicalPublicKey(publicKey string) (string, error) {
+	pub, err := utils.ParsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return utils.FormatPublicKey(pub, utils.KeyFormatPEM)
+}
+
+// PubKeyMigration 卖家公钥迁移结果
+type PubKeyMigration struct {
+	Scanned   int     // 检查的订单数
+	Converted int     // 已(或dryRun时将)转换格式的订单数
+	Invalid   []int64 // 公钥无法解析的订单，保持原值不变
+}
+
+// MigrateSellerPubKeys 将订单中已保存的卖家公钥(旧的base64 "X+Y"格式等)转换为format，按order_id分批处理
+// 只改变公钥的编码，点不变，已有的订单签名仍然有效；dryRun为true时只统计不写库
+func MigrateSellerPubKeys(format utils.KeyFormat, dryRun bool) (*PubKeyMigration, error) {
+	result := &PubKeyMigration{}
+	var lastId int64
+	for {
+		var orders []model.Order
+		err := global.DBEngine.Select("order_id", "seller_pub_key").Where("order_id > ?", lastId).
+			Order("order_id").Limit(pubKeyMigrateBatchSize).Find(&orders).Error
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch orders: %w", err)
+		}
+		if len(orders) == 0 {
+			return result, nil
+		}
+		for _, order := range orders {
+			result.Scanned++
+			pub, err := utils.ParsePublicKey(order.SellerPubKey)
+			if err != nil {
+				log.Printf("pubkey migrate: order %d: %v", order.OrderId, err)
+				result.Invalid = append(result.Invalid, order.OrderId)
+				continue
+			}
+			converted, err := utils.FormatPublicKey(pub, format)
+			if err != nil {
+				return result, fmt.Errorf("order %d: %w", order.OrderId, err)
+			}
+			if converted == order.SellerPubKey {
+				continue
+			}
+			result.Converted++
+			if dryRun {
+				continue
+			}
+			// 只更新公钥列，不触发钩子和更新时间
+			err = global.DBEngine.Model(&model.Order{}).Where("order_id = ?", order.OrderId).
+				UpdateColumn("seller_pub_key", converted).Error
+			if err != nil {
+				return result, fmt.Errorf("failed to update order %d: %w", order.OrderId, err)
+			}
+		}
+		lastId = orders[len(orders)-1].OrderId
+	}
+}
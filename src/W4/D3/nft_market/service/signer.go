@@ -0,0 +1,25 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSigners 展示指定链结算钱包池中各钱包的余额、nonce和负载(管理员接口)
+func ListSigners(c *gin.Context) {
+	signerChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, signerChain.SignerPool.Status())
+}
+
+// ListRpcEndpoints 展示指定链各RPC节点的可用状态、延迟、区块高度和错误次数(管理员接口)
+func ListRpcEndpoints(c *gin.Context) {
+	rpcChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, rpcChain.Client.Status())
+}
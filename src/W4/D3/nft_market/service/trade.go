@@ -0,0 +1,224 @@
+package service
+
+import (
+	"net/http"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/response"
+	"nftmarket/internal/validate"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 成交记录查询的默认条数，最大500条
+const defaultTradeLimit = 50
+
+// PayTokenStat 单个支付代币维度的统计
+type PayTokenStat struct {
+	PayToken      string `json:"pay_token"`
+	FloorPrice    string `json:"floor_price"` // 上架中一口价订单的最低价格
+	Volume24h     string `json:"volume_24h"`
+	TradeCount24h int64  `json:"trade_count_24h"`
+	Volume7d      string `json:"volume_7d"`
+	TradeCount7d  int64  `json:"trade_count_7d"`
+}
+
+// CollectionStats NFT合约(collection)维度的统计
+type CollectionStats struct {
+	ChainId       int64          `json:"chain_id"`
+	Nft           string         `json:"nft"`
+	PayTokens     []PayTokenStat `json:"pay_tokens"`
+	LastSale      *model.Trade   `json:"last_sale"`
+	UniqueBuyers  int64          `json:"unique_buyers"`
+	UniqueSellers int64          `json:"unique_sellers"`
+}
+
+// ListTrades 查询成交记录
+func ListTrades(c *gin.Context) {
+	var query struct {
+		Nft      string `form:"nft" json:"nft" binding:"omitempty,eth_addr"`
+		Seller   string `form:"seller" json:"seller" binding:"omitempty,eth_addr"`
+		Buyer    string `form:"buyer" json:"buyer" binding:"omitempty,eth_addr"`
+		PayToken string `form:"pay_token" json:"pay_token" binding:"omitempty,eth_addr"`
+		From     int64  `form:"from" json:"from" binding:"gte=0"` // 区块时间下限(unix秒，含)
+		To       int64  `form:"to" json:"to" binding:"gte=0"`     // 区块时间上限(unix秒，不含)
+		Limit    int    `form:"limit" json:"limit" binding:"gte=0,lte=500"`
+		Offset   int    `form:"offset" json:"offset" binding:"gte=0"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	tradeChain, ok := queryChain(c)
+	if !ok {
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultTradeLimit
+	}
+
+	tx := trades(global.DBEngine)
+	if tradeChain != nil {
+		tx = tx.Scopes(tradeChain.Scope)
+	}
+	if query.Nft != "" {
+		tx = tx.Where("nft = ?", query.Nft)
+	}
+	if query.Seller != "" {
+		tx = tx.Where("seller = ?", query.Seller)
+	}
+	if query.Buyer != "" {
+		tx = tx.Where("buyer = ?", query.Buyer)
+	}
+	if query.PayToken != "" {
+		tx = tx.Where("pay_token = ?", query.PayToken)
+	}
+	if query.From > 0 {
+		tx = tx.Where("block_timestamp >= ?", query.From)
+	}
+	if query.To > 0 {
+		tx = tx.Where("block_timestamp < ?", query.To)
+	}
+
+	result := []model.Trade{}
+	err := tx.Order("block_timestamp DESC, fill_id DESC").Limit(query.Limit).Offset(query.Offset).Scan(&result).Error
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch trades")
+		return
+	}
+	for i := range result {
+		normalizeTrade(&result[i])
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CollectionStatistics 查询NFT合约的地板价、成交量、最近成交和买卖家数量
+func CollectionStatistics(c *gin.Context) {
+	var query struct {
+		Nft string `form:"nft" json:"nft" binding:"required,eth_addr"`
+	}
+	if !validate.BindQuery(c, &query) {
+		return
+	}
+	// 不同链上的NFT合约可能地址相同，未指定chain_id时统计默认链
+	statsChain, ok := queryChain(c)
+	if !ok {
+		return
+	}
+	if statsChain == nil {
+		statsChain = global.Chains.Default()
+	}
+	stats, err := collectionStats(statsChain, query.Nft, time.Now())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to calculate collection stats")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// collectionStats 通过SQL聚合计算统计数据，成交价格以文本存储，聚合时转为numeric避免溢出
+// 成交量和成交笔数按order_fill统计，ERC1155订单每次部分成交计为一笔，地板价为单价
+func collectionStats(statsChain *chain.Chain, nft string, now time.Time) (*CollectionStats, error) {
+	db := global.DBEngine
+	stats := &CollectionStats{ChainId: statsChain.ID, Nft: nft, PayTokens: []PayTokenStat{}}
+
+	var floors []struct {
+		PayToken   string
+		FloorPrice string
+	}
+	err := db.Model(&model.Order{}).Scopes(statsChain.Scope).
+		Select("pay_token, MIN(price)::text AS floor_price").
+		Where("nft = ? AND status = ? AND filled_tx_hash IS NULL AND COALESCE(order_type, '') IN ('', ?)",
+			nft, model.OrderStatusOpen, model.OrderTypeFixed).
+		Group("pay_token").Scan(&floors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []struct {
+		PayToken      string
+		Volume24h     string
+		TradeCount24h int64
+		Volume7d      string
+		TradeCount7d  int64
+	}
+	since24h, since7d := now.Add(-24*time.Hour).Unix(), now.Add(-7*24*time.Hour).Unix()
+	err = trades(db).Scopes(statsChain.Scope).
+		Select(`pay_token,
+			COALESCE(SUM(price::numeric) FILTER (WHERE block_timestamp >= ?), 0)::text AS volume24h,
+			COUNT(*) FILTER (WHERE block_timestamp >= ?) AS trade_count24h,
+			COALESCE(SUM(price::numeric), 0)::text AS volume7d,
+			COUNT(*) AS trade_count7d`, since24h, since24h).
+		Where("nft = ? AND block_timestamp >= ?", nft, since7d).
+		Group("pay_token").Scan(&volumes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 合并两个维度的结果
+	byToken := make(map[string]*PayTokenStat)
+	var tokens []string
+	statOf := func(payToken string) *PayTokenStat {
+		stat, ok := byToken[payToken]
+		if !ok {
+			stat = &PayTokenStat{PayToken: payToken, Volume24h: "0", Volume7d: "0"}
+			byToken[payToken] = stat
+			tokens = append(tokens, payToken)
+		}
+		return stat
+	}
+	for _, floor := range floors {
+		statOf(floor.PayToken).FloorPrice = floor.FloorPrice
+	}
+	for _, volume := range volumes {
+		stat := statOf(volume.PayToken)
+		stat.Volume24h, stat.TradeCount24h = volume.Volume24h, volume.TradeCount24h
+		stat.Volume7d, stat.TradeCount7d = volume.Volume7d, volume.TradeCount7d
+	}
+	for _, token := range tokens {
+		stats.PayTokens = append(stats.PayTokens, *byToken[token])
+	}
+
+	var last []model.Trade
+	err = trades(db).Scopes(statsChain.Scope).Where("nft = ?", nft).Order("block_timestamp DESC, fill_id DESC").Limit(1).Scan(&last).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(last) > 0 {
+		normalizeTrade(&last[0])
+		stats.LastSale = &last[0]
+	}
+
+	var unique struct {
+		UniqueBuyers  int64
+		UniqueSellers int64
+	}
+	err = trades(db).Scopes(statsChain.Scope).
+		Select("COUNT(DISTINCT buyer) AS unique_buyers, COUNT(DISTINCT seller) AS unique_sellers").
+		Where("nft = ?", nft).Scan(&unique).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.UniqueBuyers, stats.UniqueSellers = unique.UniqueBuyers, unique.UniqueSellers
+	return stats, nil
+}
+
+// trades 成交记录，每条order_fill关联其订单，作为派生表查询，筛选条件与chain.Scope可直接使用列名
+// 迁移时已为历史成交订单生成order_fill记录；历史订单没有order_type和token_standard，分别为一口价和ERC721
+func trades(db *gorm.DB) *gorm.DB {
+	fills := db.Table("order_fill AS f").Select(`f.id AS fill_id, o.order_id, o.chain_id,
+		COALESCE(NULLIF(o.order_type, ''), ?) AS order_type, COALESCE(NULLIF(o.token_standard, ''), ?) AS token_standard,
+		o.nft, o.token_id, f.amount, o.seller, f.buyer, o.pay_token, f.price, f.tx_hash, f.block_number, f.block_timestamp`,
+		model.OrderTypeFixed, model.TokenStandardERC721).
+		Joins(`JOIN "order" o ON o.order_id = f.order_id`)
+	return db.Table("(?) AS trade", fills)
+}
+
+// normalizeTrade 多链之前的历史订单属于默认链
+func normalizeTrade(trade *model.Trade) {
+	if trade.ChainId == 0 {
+		trade.ChainId = global.Chains.Default().ID
+	}
+}
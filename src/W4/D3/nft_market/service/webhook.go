@@ -0,0 +1,569 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"nftmarket/global"
+	"nftmarket/internal/events"
+	"nftmarket/internal/model"
+	"nftmarket/internal/response"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ApiKeyIdContextKey gin上下文中保存当前API key id的键，由API key中间件写入
+const ApiKeyIdContextKey = "api_key_id"
+
+const (
+	// 事件订阅的缓冲事件数
+	webhookEventBuffer = 1024
+	// 每轮投递的最大记录数
+	webhookBatchSize = 100
+	// 同时进行的投递请求数
+	webhookConcurrency = 8
+	// 记录的接收方响应内容的最大长度
+	webhookMaxErrorBody = 512
+)
+
+// errWebhookTarget webhook地址指向回环、内网、链路本地等非公网地址
+var errWebhookTarget = errors.New("webhook url must resolve to a public address")
+
+// 为true时允许投递到非公网地址，仅测试中投递到本机的httptest服务时使用
+var webhookAllowPrivate = false
+
+// 标准库没有归类为私有地址的非公网网段：CGNAT共享地址(RFC 6598)和NAT64前缀(RFC 6052，内嵌的IPv4可能是内网地址)
+var webhookDeniedNets = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("64:ff9b::/96"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// WebhookOptions webhook投递参数
+type WebhookOptions struct {
+	Interval    time.Duration // 扫描待重试投递的间隔
+	Timeout     time.Duration // 单次投递的请求超时
+	MaxAttempts int           // 最大投递次数，用完后移入死信表
+	RetryBase   time.Duration // 第一次重试的等待时间，之后每次翻倍
+	RetryMax    time.Duration // 重试等待时间上限
+}
+
+// backoff 第attempts次投递失败后的等待时间
+func (o WebhookOptions) backoff(attempts int) time.Duration {
+	wait := o.RetryBase
+	for i := 1; i < attempts && wait < o.RetryMax; i++ {
+		wait *= 2
+	}
+	if wait > o.RetryMax {
+		wait = o.RetryMax
+	}
+	return wait
+}
+
+// webhookPayload 投递的请求体
+type webhookPayload struct {
+	Id        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt int64       `json:"created_at"`
+	Data      model.Order `json:"data"`
+}
+
+// CreateWebhook 创建webhook订阅，签名密钥只在创建时返回一次
+func CreateWebhook(c *gin.Context) {
+	var input struct {
+		Url    string   `json:"url" binding:"required,url"`
+		Events []string `json:"events" binding:"omitempty,dive,oneof=order.created order.settling order.partially_filled order.filled order.reopened order.cancelled order.expired order.invalidated"`
+		Nft    string   `json:"nft" binding:"omitempty,eth_addr"`
+		Seller string   `json:"seller" binding:"omitempty,eth_addr"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	if err := checkWebhookURL(c.Request.Context(), input.Url); err != nil {
+		response.Invalid(c, err.Error())
+		return
+	}
+	secret, err := utils.GenWebhookSecret()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to generate webhook secret")
+		return
+	}
+	webhook := model.Webhook{
+		ApiKeyId: c.GetInt64(ApiKeyIdContextKey),
+		Url:      input.Url,
+		Events:   input.Events,
+		Secret:   secret,
+	}
+	if input.Nft != "" {
+		webhook.Nft = common.HexToAddress(input.Nft).Hex()
+	}
+	if input.Seller != "" {
+		webhook.Seller = common.HexToAddress(input.Seller).Hex()
+	}
+	if err := global.DBEngine.Create(&webhook).Error; err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook, "secret": secret})
+}
+
+// ListWebhooks 当前API key创建的webhook订阅
+func ListWebhooks(c *gin.Context) {
+	var webhooks []model.Webhook
+	err := global.DBEngine.Where("api_key_id = ?", c.GetInt64(ApiKeyIdContextKey)).Order("id").Find(&webhooks).Error
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch webhooks")
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook 删除webhook订阅及其待投递记录和死信
+func DeleteWebhook(c *gin.Context) {
+	webhook, ok := ownWebhook(c)
+	if !ok {
+		return
+	}
+	err := global.DBEngine.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.Id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", webhook.Id).Delete(&model.WebhookDeadLetter{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to delete webhook")
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// ListWebhookDeadLetters webhook订阅的死信，按进入死信表的时间倒序
+func ListWebhookDeadLetters(c *gin.Context) {
+	webhook, ok := ownWebhook(c)
+	if !ok {
+		return
+	}
+	var deadLetters []model.WebhookDeadLetter
+	err := global.DBEngine.Where("webhook_id = ?", webhook.Id).Order("id DESC").Limit(500).Find(&deadLetters).Error
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch dead letters")
+		return
+	}
+	c.JSON(http.StatusOK, deadLetters)
+}
+
+// ReplayWebhook 重新投递webhook订阅中未重放过的死信，ids为空时重放全部
+// 重放的事件id不变，接收方可据此去重
+func ReplayWebhook(c *gin.Context) {
+	var input struct {
+		Ids []int64 `json:"ids" binding:"omitempty,dive,gt=0"`
+	}
+	// 请求体可以为空
+	if c.Request.ContentLength != 0 && !validate.BindJSON(c, &input) {
+		return
+	}
+	webhook, ok := ownWebhook(c)
+	if !ok {
+		return
+	}
+	var replayed []model.WebhookDeadLetter
+	err := global.DBEngine.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("webhook_id = ? AND replayed_at IS NULL", webhook.Id)
+		if len(input.Ids) > 0 {
+			query = query.Where("id IN ?", input.Ids)
+		}
+		if err := query.Order("id").Find(&replayed).Error; err != nil {
+			return err
+		}
+		if len(replayed) == 0 {
+			return nil
+		}
+		current := time.Now().Unix()
+		ids := make([]int64, len(replayed))
+		deliveries := make([]model.WebhookDelivery, len(replayed))
+		for i, deadLetter := range replayed {
+			ids[i] = deadLetter.Id
+			deliveries[i] = model.WebhookDelivery{
+				WebhookId:     webhook.Id,
+				EventId:       deadLetter.EventId,
+				EventType:     deadLetter.EventType,
+				Payload:       deadLetter.Payload,
+				Status:        model.WebhookDeliveryPending,
+				NextAttemptAt: current,
+			}
+		}
+		if err := tx.Create(&deliveries).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.WebhookDeadLetter{}).Where("id IN ?", ids).Update("replayed_at", current).Error
+	})
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to replay dead letters")
+		return
+	}
+	notifyWebhookDispatcher()
+	ids := make([]int64, len(replayed))
+	for i, deadLetter := range replayed {
+		ids[i] = deadLetter.Id
+	}
+	c.JSON(http.StatusOK, gin.H{"replayed": ids})
+}
+
+// ownWebhook 读取路径中的webhook id，只能操作当前API key创建的订阅
+func ownWebhook(c *gin.Context) (*model.Webhook, bool) {
+	var uri struct {
+		Id int64 `uri:"id" binding:"required,gt=0"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.Invalid(c, "invalid webhook id")
+		return nil, false
+	}
+	var webhook model.Webhook
+	err := global.DBEngine.Where("id = ? AND api_key_id = ?", uri.Id, c.GetInt64(ApiKeyIdContextKey)).First(&webhook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "Webhook not found")
+		} else {
+			response.Error(c, http.StatusInternalServerError, "Failed to fetch webhook")
+		}
+		return nil, false
+	}
+	return &webhook, true
+}
+
+// 有新的待投递记录时唤醒投递任务，不必等到下一次扫描
+var webhookWakeup = make(chan struct{}, 1)
+
+func notifyWebhookDispatcher() {
+	select {
+	case webhookWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// RunWebhookDispatcher 订阅订单事件写入投递队列，并投递到期的记录
+// 事件只在本进程内发布，进程在事件发布前退出时该事件不会投递
+func RunWebhookDispatcher(ctx context.Context, options WebhookOptions) {
+	go enqueueWebhookEvents(ctx)
+	client := newWebhookClient(options.Timeout)
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+	for {
+		if err := deliverWebhooks(ctx, client, options); err != nil {
+			log.Printf("webhook dispatcher: failed to fetch deliveries: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-webhookWakeup:
+		}
+	}
+}
+
+// enqueueWebhookEvents 将订单事件写入匹配的webhook订阅的投递队列，订阅因积压被断开时重新订阅
+func enqueueWebhookEvents(ctx context.Context) {
+	for {
+		subscription := events.Subscribe(webhookEventBuffer)
+		for running := true; running; {
+			select {
+			case <-ctx.Done():
+				subscription.Close()
+				return
+			case event, ok := <-subscription.C:
+				if !ok {
+					log.Printf("webhook dispatcher: event subscription dropped, some events were not enqueued")
+					running = false
+					continue
+				}
+				if err := enqueueWebhooks(event); err != nil {
+					log.Printf("webhook dispatcher: failed to enqueue %s for order %d: %v", event.Type, event.Order.OrderId, err)
+				}
+			}
+		}
+	}
+}
+
+// enqueueWebhooks 为匹配事件类型和过滤条件的订阅各写入一条投递记录
+func enqueueWebhooks(event events.Event) error {
+	var webhooks []model.Webhook
+	if err := global.DBEngine.Find(&webhooks).Error; err != nil {
+		return err
+	}
+	var deliveries []model.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhookMatches(&webhook, event) {
+			continue
+		}
+		eventId, err := newEventId()
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(webhookPayload{Id: eventId, Type: event.Type, CreatedAt: event.Time, Data: event.Order})
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookId:     webhook.Id,
+			EventId:       eventId,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: event.Time,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := global.DBEngine.Create(&deliveries).Error; err != nil {
+		return err
+	}
+	notifyWebhookDispatcher()
+	return nil
+}
+
+// checkWebhookURL 校验webhook地址为http(s)，且主机名解析出的所有地址都是公网地址，防止通过webhook访问内网服务(SSRF)
+// 解析结果可能在创建后变化，投递时由newWebhookClient再次校验实际连接的地址
+func checkWebhookURL(ctx context.Context, rawUrl string) error {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("url must be an http or https url")
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s", parsed.Hostname())
+	}
+	for _, addr := range addrs {
+		if !publicWebhookIP(addr.IP) {
+			return fmt.Errorf("%w, %s resolves to %s", errWebhookTarget, parsed.Hostname(), addr.IP)
+		}
+	}
+	return nil
+}
+
+// publicWebhookIP 是否允许投递到该地址，拒绝回环、私有网段、CGNAT、NAT64、链路本地(含云服务元数据地址)、组播和未指定地址
+func publicWebhookIP(ip net.IP) bool {
+	if webhookAllowPrivate {
+		return true
+	}
+	for _, denied := range webhookDeniedNets {
+		if denied.Contains(ip) {
+			return false
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// newWebhookClient 投递使用的http客户端，在建立连接时校验实际拨号的IP，DNS重绑定或重定向到内网地址时拒绝连接
+// 不使用环境变量中的代理，否则校验的是代理地址
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicWebhookIP(ip) {
+				return fmt.Errorf("%w: %s", errWebhookTarget, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// webhookMatches 订阅的事件类型为空时匹配所有事件，nft和seller为空时不过滤
+func webhookMatches(webhook *model.Webhook, event events.Event) bool {
+	if len(webhook.Events) > 0 {
+		matched := false
+		for _, eventType := range webhook.Events {
+			if eventType == event.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	sellOrder := event.Order.SellOrder
+	if webhook.Nft != "" && common.HexToAddress(webhook.Nft) != common.HexToAddress(sellOrder.Nft) {
+		return false
+	}
+	if webhook.Seller != "" && common.HexToAddress(webhook.Seller) != common.HexToAddress(sellOrder.Seller) {
+		return false
+	}
+	return true
+}
+
+// deliverWebhooks 投递到期的记录，直到没有到期记录
+func deliverWebhooks(ctx context.Context, client *http.Client, options WebhookOptions) error {
+	for ctx.Err() == nil {
+		var deliveries []model.WebhookDelivery
+		err := global.DBEngine.Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, time.Now().Unix()).
+			Order("next_attempt_at, id").Limit(webhookBatchSize).Find(&deliveries).Error
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		webhooks, err := loadWebhooks(deliveries)
+		if err != nil {
+			return err
+		}
+		sem := make(chan struct{}, webhookConcurrency)
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			delivery := &deliveries[i]
+			webhook, ok := webhooks[delivery.WebhookId]
+			if !ok {
+				// 订阅已删除
+				global.DBEngine.Delete(delivery)
+				continue
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				deliverWebhook(ctx, client, options, webhook, delivery)
+			}()
+		}
+		wg.Wait()
+		if len(deliveries) < webhookBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+func loadWebhooks(deliveries []model.WebhookDelivery) (map[int64]*model.Webhook, error) {
+	ids := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.WebhookId)
+	}
+	var webhooks []model.Webhook
+	if err := global.DBEngine.Where("id IN ?", ids).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	byId := make(map[int64]*model.Webhook, len(webhooks))
+	for i := range webhooks {
+		byId[webhooks[i].Id] = &webhooks[i]
+	}
+	return byId, nil
+}
+
+// deliverWebhook 投递一条记录并更新投递结果，失败次数达到上限时移入死信表
+func deliverWebhook(ctx context.Context, client *http.Client, options WebhookOptions, webhook *model.Webhook, delivery *model.WebhookDelivery) {
+	statusCode, err := postWebhook(ctx, client, webhook, delivery)
+	current := time.Now().Unix()
+	attempts := delivery.Attempts + 1
+	if err == nil {
+		updateErr := global.DBEngine.Model(delivery).Updates(map[string]interface{}{
+			"status": model.WebhookDeliveryDelivered, "attempts": attempts, "last_status_code": statusCode,
+			"last_error": "", "delivered_at": current,
+		}).Error
+		if updateErr != nil {
+			log.Printf("webhook dispatcher: failed to update delivery %d: %v", delivery.Id, updateErr)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// 进程退出导致的失败不计入投递次数
+		return
+	}
+	if attempts < options.MaxAttempts {
+		updateErr := global.DBEngine.Model(delivery).Updates(map[string]interface{}{
+			"attempts": attempts, "last_status_code": statusCode, "last_error": err.Error(),
+			"next_attempt_at": current + int64(options.backoff(attempts)/time.Second),
+		}).Error
+		if updateErr != nil {
+			log.Printf("webhook dispatcher: failed to update delivery %d: %v", delivery.Id, updateErr)
+		}
+		return
+	}
+	log.Printf("webhook dispatcher: delivery %d of %s to webhook %d failed %d times, moving to dead letters: %v",
+		delivery.Id, delivery.EventType, webhook.Id, attempts, err)
+	deadLetter := model.WebhookDeadLetter{
+		WebhookId:      delivery.WebhookId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Attempts:       attempts,
+		LastStatusCode: statusCode,
+		LastError:      err.Error(),
+	}
+	txErr := global.DBEngine.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&deadLetter).Error; err != nil {
+			return err
+		}
+		return tx.Delete(delivery).Error
+	})
+	if txErr != nil {
+		log.Printf("webhook dispatcher: failed to move delivery %d to dead letters: %v", delivery.Id, txErr)
+	}
+}
+
+// postWebhook 发送签名后的事件，接收方返回2xx视为投递成功
+func postWebhook(ctx context.Context, client *http.Client, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nftmarket-webhook/1")
+	req.Header.Set("X-NFTMarket-Event", delivery.EventType)
+	req.Header.Set("X-NFTMarket-Event-Id", delivery.EventId)
+	req.Header.Set("X-NFTMarket-Signature", utils.SignWebhook(webhook.Secret, time.Now().Unix(), body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorBody))
+	return resp.StatusCode, fmt.Errorf("receiver returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+}
+
+func newEventId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(buf), nil
+}
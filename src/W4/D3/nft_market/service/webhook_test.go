@@ -0,0 +1,375 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/validate"
+	"nftmarket/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var registerValidators sync.Once
+
+// setupWebhookTest 使用内存SQLite替换数据库，允许投递到本机的httptest服务
+func setupWebhookTest(t *testing.T) {
+	t.Helper()
+	setupTestDB(t, &model.Webhook{}, &model.WebhookDelivery{}, &model.WebhookDeadLetter{})
+	webhookAllowPrivate = true
+	t.Cleanup(func() { webhookAllowPrivate = false })
+}
+
+// setupTestDB 使用内存SQLite替换数据库并建表，测试结束后恢复
+func setupTestDB(t *testing.T, models ...interface{}) {
+	t.Helper()
+	registerValidators.Do(func() {
+		if err := validate.Register(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 内存数据库每个连接独立，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	previous := global.DBEngine
+	global.DBEngine = db
+	t.Cleanup(func() {
+		global.DBEngine = previous
+		sqlDB.Close()
+	})
+}
+
+// receivedWebhook 接收方收到的一次投递
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver 记录收到的请求，按statuses依次返回状态码，用完后返回200
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	received []receivedWebhook
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		receiver.received = append(receiver.received, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(receiver.statuses) > 0 {
+			status, receiver.statuses = receiver.statuses[0], receiver.statuses[1:]
+		}
+		receiver.mu.Unlock()
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "status %d", status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) requests() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.received...)
+}
+
+// createTestWebhook 创建指向url的订阅和一条到期的投递记录
+func createTestWebhook(t *testing.T, url string) (*model.Webhook, *model.WebhookDelivery) {
+	t.Helper()
+	secret, err := utils.GenWebhookSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook := &model.Webhook{ApiKeyId: 7, Url: url, Secret: secret}
+	if err := global.DBEngine.Create(webhook).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery := &model.WebhookDelivery{
+		WebhookId:     webhook.Id,
+		EventId:       "evt_test",
+		EventType:     "order.filled",
+		Payload:       `{"id":"evt_test","type":"order.filled"}`,
+		Status:        model.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Unix(),
+	}
+	if err := global.DBEngine.Create(delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return webhook, delivery
+}
+
+func testWebhookOptions() WebhookOptions {
+	return WebhookOptions{Interval: time.Second, Timeout: 5 * time.Second, MaxAttempts: 3, RetryBase: 30 * time.Second, RetryMax: time.Minute}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	options := WebhookOptions{RetryBase: 30 * time.Second, RetryMax: 5 * time.Minute}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute},
+		{20, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := options.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverWebhookSignature(t *testing.T) {
+	setupWebhookTest(t)
+	receiver := newWebhookReceiver(t)
+	webhook, delivery := createTestWebhook(t, receiver.URL)
+
+	if err := deliverWebhooks(context.Background(), newWebhookClient(time.Second), testWebhookOptions()); err != nil {
+		t.Fatal(err)
+	}
+	requests := receiver.requests()
+	if len(requests) != 1 {
+		t.Fatalf("received %d requests, want 1", len(requests))
+	}
+	request := requests[0]
+	if string(request.body) != delivery.Payload {
+		t.Fatalf("body = %s", request.body)
+	}
+	if got := request.header.Get("X-NFTMarket-Event-Id"); got != delivery.EventId {
+		t.Fatalf("event id header = %q", got)
+	}
+	if got := request.header.Get("X-NFTMarket-Event"); got != delivery.EventType {
+		t.Fatalf("event header = %q", got)
+	}
+	signature := request.header.Get("X-NFTMarket-Signature")
+	if err := utils.VerifyWebhook(webhook.Secret, signature, request.body, time.Minute); err != nil {
+		t.Fatalf("VerifyWebhook(%q): %v", signature, err)
+	}
+	if err := utils.VerifyWebhook("whsec_other", signature, request.body, time.Minute); err == nil {
+		t.Fatal("signature verified with another secret")
+	}
+	if err := utils.VerifyWebhook(webhook.Secret, signature, append(request.body, ' '), time.Minute); err == nil {
+		t.Fatal("signature verified with a modified body")
+	}
+
+	var stored model.WebhookDelivery
+	if err := global.DBEngine.First(&stored, delivery.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != model.WebhookDeliveryDelivered || stored.Attempts != 1 || stored.DeliveredAt == nil {
+		t.Fatalf("delivery = %+v", stored)
+	}
+}
+
+func TestDeliverWebhookRetryAndDeadLetter(t *testing.T) {
+	setupWebhookTest(t)
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable)
+	webhook, delivery := createTestWebhook(t, receiver.URL)
+	options := testWebhookOptions()
+	client := newWebhookClient(time.Second)
+
+	// 前两次失败按退避时间推迟下次投递
+	for attempt, wantWait := range []time.Duration{30 * time.Second, time.Minute} {
+		before := time.Now().Unix()
+		deliverWebhook(context.Background(), client, options, webhook, delivery)
+		var stored model.WebhookDelivery
+		if err := global.DBEngine.First(&stored, delivery.Id).Error; err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != model.WebhookDeliveryPending || stored.Attempts != attempt+1 {
+			t.Fatalf("attempt %d: delivery = %+v", attempt+1, stored)
+		}
+		wait := stored.NextAttemptAt - before
+		if wait < int64(wantWait/time.Second) || wait > int64(wantWait/time.Second)+1 {
+			t.Fatalf("attempt %d: next attempt in %ds, want %v", attempt+1, wait, wantWait)
+		}
+		if stored.LastStatusCode == 0 || !strings.Contains(stored.LastError, "status") {
+			t.Fatalf("attempt %d: last status %d, error %q", attempt+1, stored.LastStatusCode, stored.LastError)
+		}
+		*delivery = stored
+	}
+
+	// 未到重试时间的记录不会被投递
+	if err := deliverWebhooks(context.Background(), client, options); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(receiver.requests()); got != 2 {
+		t.Fatalf("received %d requests before the retry is due, want 2", got)
+	}
+
+	// 第三次失败后移入死信表
+	deliverWebhook(context.Background(), client, options, webhook, delivery)
+	if err := global.DBEngine.First(&model.WebhookDelivery{}, delivery.Id).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("delivery still queued: %v", err)
+	}
+	var deadLetters []model.WebhookDeadLetter
+	if err := global.DBEngine.Where("webhook_id = ?", webhook.Id).Find(&deadLetters).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("%d dead letters, want 1", len(deadLetters))
+	}
+	deadLetter := deadLetters[0]
+	if deadLetter.EventId != delivery.EventId || deadLetter.Payload != delivery.Payload || deadLetter.Attempts != 3 ||
+		deadLetter.LastStatusCode != http.StatusServiceUnavailable || deadLetter.ReplayedAt != nil {
+		t.Fatalf("dead letter = %+v", deadLetter)
+	}
+}
+
+func TestReplayWebhook(t *testing.T) {
+	setupWebhookTest(t)
+	receiver := newWebhookReceiver(t)
+	webhook, delivery := createTestWebhook(t, receiver.URL)
+	global.DBEngine.Delete(delivery)
+	deadLetters := []model.WebhookDeadLetter{
+		{WebhookId: webhook.Id, EventId: "evt_1", EventType: "order.filled", Payload: `{"id":"evt_1"}`, Attempts: 3},
+		{WebhookId: webhook.Id, EventId: "evt_2", EventType: "order.expired", Payload: `{"id":"evt_2"}`, Attempts: 3},
+	}
+	if err := global.DBEngine.Create(&deadLetters).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	replay := func(apiKeyId int64, body string) (int, []int64) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: strconv.FormatInt(webhook.Id, 10)}}
+		c.Set(ApiKeyIdContextKey, apiKeyId)
+		ReplayWebhook(c)
+		var result struct {
+			Replayed []int64 `json:"replayed"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &result)
+		return recorder.Code, result.Replayed
+	}
+
+	// 只能重放自己API key的订阅
+	if code, _ := replay(8, ""); code != http.StatusNotFound {
+		t.Fatalf("replay by another api key returned %d", code)
+	}
+	code, replayed := replay(7, fmt.Sprintf(`{"ids":[%d]}`, deadLetters[1].Id))
+	if code != http.StatusOK || len(replayed) != 1 || replayed[0] != deadLetters[1].Id {
+		t.Fatalf("replay selected = %d %v", code, replayed)
+	}
+	code, replayed = replay(7, "")
+	if code != http.StatusOK || len(replayed) != 1 || replayed[0] != deadLetters[0].Id {
+		t.Fatalf("replay all = %d %v", code, replayed)
+	}
+	// 已重放的死信不会再次重放
+	if code, replayed = replay(7, ""); code != http.StatusOK || len(replayed) != 0 {
+		t.Fatalf("second replay = %d %v", code, replayed)
+	}
+
+	if err := deliverWebhooks(context.Background(), newWebhookClient(time.Second), testWebhookOptions()); err != nil {
+		t.Fatal(err)
+	}
+	eventIds := map[string]bool{}
+	for _, request := range receiver.requests() {
+		eventIds[request.header.Get("X-NFTMarket-Event-Id")] = true
+		if err := utils.VerifyWebhook(webhook.Secret, request.header.Get("X-NFTMarket-Signature"), request.body, time.Minute); err != nil {
+			t.Fatalf("replayed delivery signature: %v", err)
+		}
+	}
+	// 重放保持原事件id，接收方可据此去重
+	if len(eventIds) != 2 || !eventIds["evt_1"] || !eventIds["evt_2"] {
+		t.Fatalf("replayed event ids = %v", eventIds)
+	}
+	var pending int64
+	global.DBEngine.Model(&model.WebhookDelivery{}).Where("status = ?", model.WebhookDeliveryPending).Count(&pending)
+	if pending != 0 {
+		t.Fatalf("%d deliveries still pending", pending)
+	}
+}
+
+func TestCreateWebhookRejectsPrivateTargets(t *testing.T) {
+	setupWebhookTest(t)
+	webhookAllowPrivate = false
+	tests := []struct {
+		url  string
+		want int
+	}{
+		{"http://127.0.0.1:8080/hook", http.StatusBadRequest},
+		{"http://localhost/hook", http.StatusBadRequest},
+		{"http://10.1.2.3/hook", http.StatusBadRequest},
+		{"http://172.16.0.1/hook", http.StatusBadRequest},
+		{"https://192.168.1.10/hook", http.StatusBadRequest},
+		{"http://169.254.169.254/latest/meta-data", http.StatusBadRequest},
+		{"http://0.0.0.0/hook", http.StatusBadRequest},
+		{"http://[::1]/hook", http.StatusBadRequest},
+		{"http://[fe80::1]/hook", http.StatusBadRequest},
+		{"http://[fd00::1]/hook", http.StatusBadRequest},
+		{"http://[::ffff:127.0.0.1]/hook", http.StatusBadRequest},
+		{"http://100.64.0.1/hook", http.StatusBadRequest},
+		{"http://[64:ff9b::a9fe:a9fe]/hook", http.StatusBadRequest},
+		{"ftp://93.184.216.34/hook", http.StatusBadRequest},
+		{"https://93.184.216.34/hook", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(fmt.Sprintf(`{"url":%q}`, tt.url)))
+			c.Request.Header.Set("Content-Type", "application/json")
+			CreateWebhook(c)
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestWebhookClientRejectsPrivateDial(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	// 创建时通过校验的域名之后解析到内网地址(DNS重绑定)，连接时同样拒绝
+	_, err := newWebhookClient(time.Second).Get(receiver.URL)
+	if !errors.Is(err, errWebhookTarget) {
+		t.Fatalf("dial to %s: %v", receiver.URL, err)
+	}
+	if len(receiver.requests()) != 0 {
+		t.Fatal("request reached the private receiver")
+	}
+
+	webhookAllowPrivate = true
+	defer func() { webhookAllowPrivate = false }()
+	resp, err := newWebhookClient(time.Second).Get(receiver.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"nftmarket/chain"
+	"nftmarket/global"
+	"nftmarket/internal/model"
+	"nftmarket/internal/response"
+	"nftmarket/internal/validate"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 等待白名单交易上链的超时时间
+const whiteListTxTimeout = 2 * time.Minute
+
+// AddWhiteList 添加后端client至白名单(管理员接口)
+func AddWhiteList(c *gin.Context) {
+	var input struct {
+		Client string `json:"client" binding:"required,eth_addr"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	whiteListChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	record, err := AddWhiteListClient(whiteListChain, input.Client)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// RemoveWhiteList 将后端client移出白名单(管理员接口)
+func RemoveWhiteList(c *gin.Context) {
+	var input struct {
+		Client string `json:"client" binding:"required,eth_addr"`
+	}
+	if !validate.BindJSON(c, &input) {
+		return
+	}
+	whiteListChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	record, err := RemoveWhiteListClient(whiteListChain, input.Client)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// ListWhiteList 展示白名单(管理员接口)
+func ListWhiteList(c *gin.Context) {
+	whiteListChain, ok := adminChain(c)
+	if !ok {
+		return
+	}
+	records, err := ListWhiteListClients(whiteListChain)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch white list")
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// AddWhiteListClient 调用指定链上合约的setWhiteList并登记到本地白名单表
+func AddWhiteListClient(whiteListChain *chain.Chain, client string) (*model.WhiteList, error) {
+	if !common.IsHexAddress(client) {
+		return nil, errors.New("invalid client address")
+	}
+	address := common.HexToAddress(client)
+
+	record, err := findWhiteListRecord(whiteListChain.ID, address)
+	if err != nil {
+		return nil, err
+	}
+	index, err := whiteListChain.Market.WhiteList(nil, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query white list: %w", err)
+	}
+	// 链上已在白名单中（例如之前通过cast手动设置），只需补登记
+	if index.Sign() == 0 {
+		opts, err := ownerTransactOpts(whiteListChain)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := whiteListChain.Market.SetWhiteList(opts, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set white list: %w", err)
+		}
+		if err := waitTxSuccess(whiteListChain, tx); err != nil {
+			return nil, err
+		}
+		record.AddTxHash = tx.Hash().Hex()
+	}
+
+	record.Status = model.WhiteListStatusActive
+	record.RemoveTxHash = ""
+	if err := global.DBEngine.Save(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to save white list: %w", err)
+	}
+	return record, nil
+}
+
+// RemoveWhiteListClient 调用指定链上合约的cancelWhiteListSigner并更新本地白名单表
+func RemoveWhiteListClient(whiteListChain *chain.Chain, client string) (*model.WhiteList, error) {
+	if !common.IsHexAddress(client) {
+		return nil, errors.New("invalid client address")
+	}
+	address := common.HexToAddress(client)
+
+	record, err := findWhiteListRecord(whiteListChain.ID, address)
+	if err != nil {
+		return nil, err
+	}
+	index, err := whiteListChain.Market.WhiteList(nil, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query white list: %w", err)
+	}
+	if index.Sign() != 0 {
+		opts, err := ownerTransactOpts(whiteListChain)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := whiteListChain.Market.CancelWhiteListSigner(opts, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel white list: %w", err)
+		}
+		if err := waitTxSuccess(whiteListChain, tx); err != nil {
+			return nil, err
+		}
+		record.RemoveTxHash = tx.Hash().Hex()
+	}
+
+	record.Status = model.WhiteListStatusRemoved
+	if err := global.DBEngine.Save(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to save white list: %w", err)
+	}
+	return record, nil
+}
+
+// ListWhiteListClients 从本地登记表还原指定链的白名单，并逐个与链上状态核对
+func ListWhiteListClients(whiteListChain *chain.Chain) ([]model.WhiteList, error) {
+	var records []model.WhiteList
+	if err := global.DBEngine.Where("chain_id = ?", whiteListChain.ID).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for i := range records {
+		index, err := whiteListChain.Market.WhiteList(nil, common.HexToAddress(records[i].Client))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query white list: %w", err)
+		}
+		records[i].OnChainIndex = index.Int64()
+		records[i].OnChainActive = index.Sign() != 0
+	}
+	return records, nil
+}
+
+// findWhiteListRecord 查询本地登记记录，不存在时返回一条新记录
+func findWhiteListRecord(chainId int64, address common.Address) (*model.WhiteList, error) {
+	var record model.WhiteList
+	err := global.DBEngine.Where("chain_id = ? AND client = ?", chainId, address.Hex()).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.WhiteList{ChainId: chainId, Client: address.Hex()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ownerTransactOpts 使用合约owner私钥构建交易参数，发送前校验私钥对应地址是否为合约Owner()
+func ownerTransactOpts(ownerChain *chain.Chain) (*bind.TransactOpts, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(ownerChain.OwnerPrivateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner private key: %w", err)
+	}
+	owner, err := ownerChain.Market.Owner(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contract owner: %w", err)
+	}
+	if from := crypto.PubkeyToAddress(privateKey.PublicKey); from != owner {
+		return nil, fmt.Errorf("configured key %s is not the contract owner %s", from.Hex(), owner.Hex())
+	}
+	return bind.NewKeyedTransactorWithChainID(privateKey, big.NewInt(ownerChain.ID))
+}
+
+// waitTxSuccess 等待交易上链达到确认数并校验执行结果
+func waitTxSuccess(txChain *chain.Chain, tx *types.Transaction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), whiteListTxTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, txChain.Client, tx)
+	if err != nil {
+		return fmt.Errorf("failed to wait tx %s mined: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("tx %s reverted", tx.Hash().Hex())
+	}
+	return txChain.WaitConfirmations(ctx, receipt)
+}
+
+// adminChain 管理接口通过chain_id查询参数指定操作的链，未指定时为默认链
+func adminChain(c *gin.Context) (*chain.Chain, bool) {
+	adminChain, ok := queryChain(c)
+	if ok && adminChain == nil {
+		adminChain = global.Chains.Default()
+	}
+	return adminChain, ok
+}
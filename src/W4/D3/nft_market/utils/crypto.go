@@ -2,80 +2,28 @@ package utils
 
 import (
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/asn1"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"math/big"
-	"strings"
 )
 
-// GenKeyPair 生成密钥对
+// GenKeyPair 生成P-256密钥对，私钥为PKCS#8 PEM，公钥为SPKI PEM
 func GenKeyPair() (privateKey string, publicKey string, e error) {
-	priKey, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if e != nil {
-		return "", "", e
-	}
-	ecPrivateKey, e := x509.MarshalECPrivateKey(priKey)
-	if e != nil {
-		return "", "", e
-	}
-	privateKey = base64.StdEncoding.EncodeToString(ecPrivateKey)
-
-	X := priKey.X
-	Y := priKey.Y
-	xStr, e := X.MarshalText()
-	if e != nil {
-		return "", "", e
-	}
-	yStr, e := Y.MarshalText()
-	if e != nil {
-		return "", "", e
-	}
-	public := string(xStr) + "+" + string(yStr)
-	publicKey = base64.StdEncoding.EncodeToString([]byte(public))
-	return
+	return GenKeyPairWith(CurveP256, KeyFormatPEM)
 }
 
-// BuildPrivateKey 将base64编码的私钥转为PrivateKey
+// BuildPrivateKey 解析私钥，支持的格式见ParsePrivateKey
 func BuildPrivateKey(privateKeyStr string) (priKey *ecdsa.PrivateKey, e error) {
-	bytes, e := base64.StdEncoding.DecodeString(privateKeyStr)
-	if e != nil {
-		return nil, e
-	}
-	priKey, e = x509.ParseECPrivateKey(bytes)
-	if e != nil {
-		return nil, e
-	}
-	return
+	return ParsePrivateKey(privateKeyStr)
 }
 
-// BuildPublicKey 将base64编码的公钥转为PublicKey
+// BuildPublicKey 解析公钥，支持的格式见ParsePublicKey
 func BuildPublicKey(publicKeyStr string) (pubKey *ecdsa.PublicKey, e error) {
-	bytes, e := base64.StdEncoding.DecodeString(publicKeyStr)
-	if e != nil {
-		return nil, e
-	}
-	split := strings.Split(string(bytes), "+")
-	xStr := split[0]
-	yStr := split[1]
-	x := new(big.Int)
-	y := new(big.Int)
-	e = x.UnmarshalText([]byte(xStr))
-	if e != nil {
-		return nil, e
-	}
-	e = y.UnmarshalText([]byte(yStr))
-	if e != nil {
-		return nil, e
-	}
-	pub := ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
-	pubKey = &pub
-	return
+	return ParsePublicKey(publicKeyStr)
 }
 
 // Sign 使用ECDSA对给定内容进行签名。
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RecoverPersonalSign 从personal_sign(EIP-191)签名中恢复签名者地址
+func RecoverPersonalSign(message string, signatureHex string) (common.Address, error) {
+	return RecoverHash(accounts.TextHash([]byte(message)), signatureHex)
+}
+
+// RecoverHash 从对摘要(如EIP-712 typed data哈希)的65字节r||s||v签名中恢复签名者地址
+func RecoverHash(digest []byte, signatureHex string) (common.Address, error) {
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid signature length")
+	}
+	// 兼容钱包返回的v值(27/28)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// SignPersonal 用私钥对消息进行personal_sign(EIP-191)签名，返回v为27/28的65字节hex签名
+func SignPersonal(message string, key *ecdsa.PrivateKey) (string, error) {
+	return SignHash(accounts.TextHash([]byte(message)), key)
+}
+
+// SignHash 用私钥对摘要签名，返回v为27/28的65字节hex签名，与钱包的签名格式一致
+func SignHash(digest []byte, key *ecdsa.PrivateKey) (string, error) {
+	signature, err := crypto.Sign(digest, key)
+	if err != nil {
+		return "", err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(signature), nil
+}
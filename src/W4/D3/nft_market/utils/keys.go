@@ -0,0 +1,617 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 支持的曲线名称，与JWK的crv一致
+const (
+	CurveP256      = "P-256"
+	CurveSecp256k1 = "secp256k1"
+)
+
+// KeyFormat 密钥的文本格式
+type KeyFormat string
+
+const (
+	KeyFormatLegacy         KeyFormat = "legacy"          // 旧格式，公钥为base64("X十进制+Y十进制")，私钥为base64(SEC1 DER)，公钥只支持P-256
+	KeyFormatPEM            KeyFormat = "pem"             // 公钥SPKI("PUBLIC KEY")，私钥PKCS#8("PRIVATE KEY")
+	KeyFormatJWK            KeyFormat = "jwk"             // RFC 7518 EC JWK
+	KeyFormatSEC1           KeyFormat = "sec1"            // 仅公钥，{curve}:{未压缩点hex}
+	KeyFormatSEC1Compressed KeyFormat = "sec1-compressed" // 仅公钥，{curve}:{压缩点hex}
+)
+
+var (
+	ErrInvalidKey        = errors.New("invalid key")
+	ErrUnsupportedCurve  = errors.New("unsupported curve")
+	ErrUnsupportedFormat = errors.New("unsupported key format")
+)
+
+var (
+	oidPublicKeyECDSA  = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256  = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveK256  = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	errTrailingKeyData = fmt.Errorf("%w: trailing data", ErrInvalidKey)
+)
+
+// keyCurve 曲线及其编码参数，a为曲线方程 y² = x³ + ax + b 的系数
+type keyCurve struct {
+	name  string
+	curve elliptic.Curve
+	oid   asn1.ObjectIdentifier
+	a     *big.Int
+}
+
+var keyCurves = []keyCurve{
+	{name: CurveP256, curve: elliptic.P256(), oid: oidNamedCurveP256, a: big.NewInt(-3)},
+	{name: CurveSecp256k1, curve: crypto.S256(), oid: oidNamedCurveK256, a: big.NewInt(0)},
+}
+
+func (k keyCurve) byteSize() int {
+	return (k.curve.Params().BitSize + 7) / 8
+}
+
+func curveByName(name string) (keyCurve, error) {
+	for _, k := range keyCurves {
+		if strings.EqualFold(k.name, name) {
+			return k, nil
+		}
+	}
+	return keyCurve{}, fmt.Errorf("%w: %q", ErrUnsupportedCurve, name)
+}
+
+func curveByOID(oid asn1.ObjectIdentifier) (keyCurve, error) {
+	for _, k := range keyCurves {
+		if k.oid.Equal(oid) {
+			return k, nil
+		}
+	}
+	return keyCurve{}, fmt.Errorf("%w: %s", ErrUnsupportedCurve, oid)
+}
+
+func curveOf(curve elliptic.Curve) (keyCurve, error) {
+	if curve == nil {
+		return keyCurve{}, ErrUnsupportedCurve
+	}
+	for _, k := range keyCurves {
+		if k.curve == curve || k.curve.Params().Name != "" && k.curve.Params().Name == curve.Params().Name {
+			return k, nil
+		}
+	}
+	return keyCurve{}, fmt.Errorf("%w: %s", ErrUnsupportedCurve, curve.Params().Name)
+}
+
+// CurveName 公钥所在曲线的名称
+func CurveName(pub *ecdsa.PublicKey) (string, error) {
+	k, err := curveOf(pub.Curve)
+	if err != nil {
+		return "", err
+	}
+	return k.name, nil
+}
+
+// onCurve 坐标在[0, p)范围内且满足曲线方程，排除无穷远点
+func (k keyCurve) onCurve(x, y *big.Int) bool {
+	p := k.curve.Params().P
+	if x == nil || y == nil || x.Sign() < 0 || y.Sign() < 0 || x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+	return k.curve.IsOnCurve(x, y)
+}
+
+// newPublicKey 校验坐标后构造公钥
+func (k keyCurve) newPublicKey(x, y *big.Int) (*ecdsa.PublicKey, error) {
+	if !k.onCurve(x, y) {
+		return nil, fmt.Errorf("%w: point is not on curve %s", ErrInvalidKey, k.name)
+	}
+	return &ecdsa.PublicKey{Curve: k.curve, X: x, Y: y}, nil
+}
+
+// newPrivateKey 校验私钥标量在[1, n)范围内，并计算对应的公钥
+func (k keyCurve) newPrivateKey(d []byte) (*ecdsa.PrivateKey, error) {
+	if len(d) > k.byteSize() {
+		return nil, fmt.Errorf("%w: private key too long", ErrInvalidKey)
+	}
+	scalar := new(big.Int).SetBytes(d)
+	if scalar.Sign() == 0 || scalar.Cmp(k.curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("%w: private key out of range", ErrInvalidKey)
+	}
+	priv := &ecdsa.PrivateKey{D: scalar}
+	priv.Curve = k.curve
+	priv.X, priv.Y = k.curve.ScalarBaseMult(scalar.FillBytes(make([]byte, k.byteSize())))
+	return priv, nil
+}
+
+// GenerateKey 在指定曲线上生成私钥
+func GenerateKey(curve string) (*ecdsa.PrivateKey, error) {
+	k, err := curveByName(curve)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa.GenerateKey(k.curve, rand.Reader)
+}
+
+// GenKeyPairWith 在指定曲线上生成密钥对并按format编码，sec1格式只用于公钥，私钥此时按PEM编码
+func GenKeyPairWith(curve string, format KeyFormat) (privateKey string, publicKey string, e error) {
+	priKey, e := GenerateKey(curve)
+	if e != nil {
+		return "", "", e
+	}
+	privateFormat := format
+	if format == KeyFormatSEC1 || format == KeyFormatSEC1Compressed {
+		privateFormat = KeyFormatPEM
+	}
+	if privateKey, e = FormatPrivateKey(priKey, privateFormat); e != nil {
+		return "", "", e
+	}
+	if publicKey, e = FormatPublicKey(&priKey.PublicKey, format); e != nil {
+		return "", "", e
+	}
+	return
+}
+
+// ParseKeyFormat 解析格式名称，为空时返回PEM
+func ParseKeyFormat(s string) (KeyFormat, error) {
+	switch format := KeyFormat(strings.ToLower(s)); format {
+	case "":
+		return KeyFormatPEM, nil
+	case KeyFormatLegacy, KeyFormatPEM, KeyFormatJWK, KeyFormatSEC1, KeyFormatSEC1Compressed:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, s)
+}
+
+// FormatPublicKey 按format编码公钥
+func FormatPublicKey(pub *ecdsa.PublicKey, format KeyFormat) (string, error) {
+	if pub == nil {
+		return "", ErrInvalidKey
+	}
+	k, err := curveOf(pub.Curve)
+	if err != nil {
+		return "", err
+	}
+	if !k.onCurve(pub.X, pub.Y) {
+		return "", fmt.Errorf("%w: point is not on curve %s", ErrInvalidKey, k.name)
+	}
+	switch format {
+	case KeyFormatLegacy:
+		if k.name != CurveP256 {
+			return "", fmt.Errorf("%w: legacy format only supports %s", ErrUnsupportedFormat, CurveP256)
+		}
+		return base64.StdEncoding.EncodeToString([]byte(pub.X.String() + "+" + pub.Y.String())), nil
+	case KeyFormatPEM:
+		der, err := MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return "", err
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+	case KeyFormatJWK:
+		return marshalJWK(k, pub, nil)
+	case KeyFormatSEC1:
+		return k.name + ":" + hex.EncodeToString(MarshalPoint(pub, false)), nil
+	case KeyFormatSEC1Compressed:
+		return k.name + ":" + hex.EncodeToString(MarshalPoint(pub, true)), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// FormatPrivateKey 按format编码私钥，sec1格式只用于公钥
+func FormatPrivateKey(priv *ecdsa.PrivateKey, format KeyFormat) (string, error) {
+	if priv == nil || priv.D == nil {
+		return "", ErrInvalidKey
+	}
+	k, err := curveOf(priv.Curve)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case KeyFormatLegacy:
+		der, err := marshalECPrivateKey(k, priv, true)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(der), nil
+	case KeyFormatPEM:
+		der, err := MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return "", err
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+	case KeyFormatJWK:
+		return marshalJWK(k, &priv.PublicKey, priv.D)
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// ParsePublicKey 自动识别格式解析公钥：PEM(SPKI)、JWK、{curve}:{SEC1点hex}、SEC1点hex和旧格式
+// 不带曲线前缀的SEC1点：未压缩点按所在曲线识别，压缩点按以太坊惯例视为secp256k1
+func ParsePublicKey(s string) (*ecdsa.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("%w: empty public key", ErrInvalidKey)
+	case strings.HasPrefix(s, "-----BEGIN"):
+		block, rest := pem.Decode([]byte(s))
+		if block == nil || len(strings.TrimSpace(string(rest))) != 0 {
+			return nil, fmt.Errorf("%w: malformed PEM", ErrInvalidKey)
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("%w: unexpected PEM type %q", ErrInvalidKey, block.Type)
+		}
+		return ParsePKIXPublicKey(block.Bytes)
+	case strings.HasPrefix(s, "{"):
+		k, x, y, _, err := parseJWK(s)
+		if err != nil {
+			return nil, err
+		}
+		return k.newPublicKey(x, y)
+	}
+	if name, point, found := strings.Cut(s, ":"); found {
+		k, err := curveByName(name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := hex.DecodeString(point)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		return UnmarshalPoint(k.name, data)
+	}
+	if data, err := hex.DecodeString(strings.TrimPrefix(s, "0x")); err == nil {
+		if len(data) > 0 && data[0] == 4 {
+			for _, k := range keyCurves {
+				if pub, err := UnmarshalPoint(k.name, data); err == nil {
+					return pub, nil
+				}
+			}
+			return nil, fmt.Errorf("%w: point is not on a supported curve", ErrInvalidKey)
+		}
+		return UnmarshalPoint(CurveSecp256k1, data)
+	}
+	return parseLegacyPublicKey(s)
+}
+
+// parseLegacyPublicKey 解析旧格式base64("X十进制+Y十进制")的P-256公钥
+func parseLegacyPublicKey(s string) (*ecdsa.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unrecognized public key format", ErrInvalidKey)
+	}
+	xStr, yStr, found := strings.Cut(string(data), "+")
+	if !found {
+		return nil, fmt.Errorf("%w: legacy public key must be X+Y", ErrInvalidKey)
+	}
+	x, okX := new(big.Int).SetString(xStr, 10)
+	y, okY := new(big.Int).SetString(yStr, 10)
+	if !okX || !okY {
+		return nil, fmt.Errorf("%w: legacy public key coordinates must be decimal", ErrInvalidKey)
+	}
+	k, _ := curveByName(CurveP256)
+	return k.newPublicKey(x, y)
+}
+
+// ParsePrivateKey 自动识别格式解析私钥：PEM(PKCS#8或EC PRIVATE KEY)、JWK、旧格式base64(SEC1 DER)，
+// 以及0x开头的32字节hex(以太坊私钥，secp256k1)
+func ParsePrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("%w: empty private key", ErrInvalidKey)
+	case strings.HasPrefix(s, "-----BEGIN"):
+		block, rest := pem.Decode([]byte(s))
+		if block == nil || len(strings.TrimSpace(string(rest))) != 0 {
+			return nil, fmt.Errorf("%w: malformed PEM", ErrInvalidKey)
+		}
+		switch block.Type {
+		case "PRIVATE KEY":
+			return ParsePKCS8PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return parseECPrivateKey(block.Bytes, nil)
+		}
+		return nil, fmt.Errorf("%w: unexpected PEM type %q", ErrInvalidKey, block.Type)
+	case strings.HasPrefix(s, "{"):
+		k, x, y, d, err := parseJWK(s)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			return nil, fmt.Errorf("%w: JWK has no private part", ErrInvalidKey)
+		}
+		priv, err := k.newPrivateKey(d)
+		if err != nil {
+			return nil, err
+		}
+		if priv.X.Cmp(x) != 0 || priv.Y.Cmp(y) != 0 {
+			return nil, fmt.Errorf("%w: JWK public part does not match d", ErrInvalidKey)
+		}
+		return priv, nil
+	case strings.HasPrefix(s, "0x"):
+		d, err := hex.DecodeString(s[2:])
+		if err != nil || len(d) != 32 {
+			return nil, fmt.Errorf("%w: hex private key must be 32 bytes", ErrInvalidKey)
+		}
+		k, _ := curveByName(CurveSecp256k1)
+		return k.newPrivateKey(d)
+	}
+	der, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unrecognized private key format", ErrInvalidKey)
+	}
+	return parseECPrivateKey(der, nil)
+}
+
+// MarshalPoint 将公钥编码为SEC1点，压缩点为 02|03 || X，未压缩点为 04 || X || Y
+func MarshalPoint(pub *ecdsa.PublicKey, compressed bool) []byte {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	if compressed {
+		out := make([]byte, 1+size)
+		out[0] = byte(2 + pub.Y.Bit(0))
+		pub.X.FillBytes(out[1:])
+		return out
+	}
+	out := make([]byte, 1+2*size)
+	out[0] = 4
+	pub.X.FillBytes(out[1 : 1+size])
+	pub.Y.FillBytes(out[1+size:])
+	return out
+}
+
+// UnmarshalPoint 解析指定曲线上的SEC1压缩或未压缩点
+func UnmarshalPoint(curve string, data []byte) (*ecdsa.PublicKey, error) {
+	k, err := curveByName(curve)
+	if err != nil {
+		return nil, err
+	}
+	size := k.byteSize()
+	switch {
+	case len(data) == 1+2*size && data[0] == 4:
+		return k.newPublicKey(new(big.Int).SetBytes(data[1:1+size]), new(big.Int).SetBytes(data[1+size:]))
+	case len(data) == 1+size && (data[0] == 2 || data[0] == 3):
+		x := new(big.Int).SetBytes(data[1:])
+		params := k.curve.Params()
+		if x.Cmp(params.P) >= 0 {
+			return nil, fmt.Errorf("%w: x coordinate out of range", ErrInvalidKey)
+		}
+		// y² = x³ + ax + b (mod p)
+		y2 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+		y2.Add(y2, new(big.Int).Mul(k.a, x))
+		y2.Add(y2, params.B)
+		y2.Mod(y2, params.P)
+		y := new(big.Int).ModSqrt(y2, params.P)
+		if y == nil {
+			return nil, fmt.Errorf("%w: point is not on curve %s", ErrInvalidKey, k.name)
+		}
+		if y.Bit(0) != uint(data[0]&1) {
+			y.Sub(params.P, y)
+		}
+		return k.newPublicKey(x, y)
+	}
+	return nil, fmt.Errorf("%w: malformed SEC1 point for %s", ErrInvalidKey, k.name)
+}
+
+// pkixAlgorithm AlgorithmIdentifier，EC密钥的参数为曲线OID
+type pkixAlgorithm struct {
+	Algorithm asn1.ObjectIdentifier
+	Curve     asn1.ObjectIdentifier
+}
+
+type pkixPublicKey struct {
+	Algorithm pkixAlgorithm
+	PublicKey asn1.BitString
+}
+
+// pkcs8 PrivateKeyInfo，省略可选的attributes
+type pkcs8 struct {
+	Version    int
+	Algorithm  pkixAlgorithm
+	PrivateKey []byte
+}
+
+// ecPrivateKey RFC 5915 ECPrivateKey
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// MarshalPKIXPublicKey 将公钥编码为SPKI DER，标准库x509不支持secp256k1，此处直接编码
+func MarshalPKIXPublicKey(pub *ecdsa.PublicKey) ([]byte, error) {
+	k, err := curveOf(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+	point := MarshalPoint(pub, false)
+	return asn1.Marshal(pkixPublicKey{
+		Algorithm: pkixAlgorithm{Algorithm: oidPublicKeyECDSA, Curve: k.oid},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+// ParsePKIXPublicKey 解析SPKI DER编码的EC公钥
+func ParsePKIXPublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var info pkixPublicKey
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if len(rest) != 0 {
+		return nil, errTrailingKeyData
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("%w: not an EC public key", ErrInvalidKey)
+	}
+	k, err := curveByOID(info.Algorithm.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPoint(k.name, info.PublicKey.RightAlign())
+}
+
+// MarshalPKCS8PrivateKey 将私钥编码为PKCS#8 DER，内层ECPrivateKey省略曲线OID
+func MarshalPKCS8PrivateKey(priv *ecdsa.PrivateKey) ([]byte, error) {
+	k, err := curveOf(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := marshalECPrivateKey(k, priv, false)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{
+		Algorithm:  pkixAlgorithm{Algorithm: oidPublicKeyECDSA, Curve: k.oid},
+		PrivateKey: inner,
+	})
+}
+
+// ParsePKCS8PrivateKey 解析PKCS#8 DER编码的EC私钥
+func ParsePKCS8PrivateKey(der []byte) (*ecdsa.PrivateKey, error) {
+	var info pkcs8
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if len(rest) != 0 {
+		return nil, errTrailingKeyData
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("%w: not an EC private key", ErrInvalidKey)
+	}
+	k, err := curveByOID(info.Algorithm.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return parseECPrivateKey(info.PrivateKey, &k)
+}
+
+func marshalECPrivateKey(k keyCurve, priv *ecdsa.PrivateKey, withOID bool) ([]byte, error) {
+	if priv.D.Sign() <= 0 || priv.D.Cmp(k.curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("%w: private key out of range", ErrInvalidKey)
+	}
+	point := MarshalPoint(&priv.PublicKey, false)
+	key := ecPrivateKey{
+		Version:    1,
+		PrivateKey: priv.D.FillBytes(make([]byte, k.byteSize())),
+		PublicKey:  asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	}
+	if withOID {
+		key.NamedCurveOID = k.oid
+	}
+	return asn1.Marshal(key)
+}
+
+// parseECPrivateKey 解析SEC1 ECPrivateKey，curve为nil时使用DER中的曲线OID；包含公钥时校验与私钥一致
+func parseECPrivateKey(der []byte, curve *keyCurve) (*ecdsa.PrivateKey, error) {
+	var key ecPrivateKey
+	rest, err := asn1.Unmarshal(der, &key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if len(rest) != 0 {
+		return nil, errTrailingKeyData
+	}
+	if key.Version != 1 {
+		return nil, fmt.Errorf("%w: unknown EC private key version %d", ErrInvalidKey, key.Version)
+	}
+	var k keyCurve
+	switch {
+	case len(key.NamedCurveOID) > 0:
+		if k, err = curveByOID(key.NamedCurveOID); err != nil {
+			return nil, err
+		}
+		if curve != nil && k.name != curve.name {
+			return nil, fmt.Errorf("%w: curve mismatch", ErrInvalidKey)
+		}
+	case curve != nil:
+		k = *curve
+	default:
+		return nil, fmt.Errorf("%w: missing curve", ErrInvalidKey)
+	}
+	priv, err := k.newPrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.PublicKey.Bytes) > 0 {
+		pub, err := UnmarshalPoint(k.name, key.PublicKey.RightAlign())
+		if err != nil {
+			return nil, err
+		}
+		if pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+			return nil, fmt.Errorf("%w: public key does not match private key", ErrInvalidKey)
+		}
+	}
+	return priv, nil
+}
+
+// jwk RFC 7518 EC密钥，坐标和私钥为定长大端字节的base64url(无填充)
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"`
+}
+
+func marshalJWK(k keyCurve, pub *ecdsa.PublicKey, d *big.Int) (string, error) {
+	size := k.byteSize()
+	key := jwk{
+		Kty: "EC",
+		Crv: k.name,
+		X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+		Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+	}
+	if d != nil {
+		key.D = base64.RawURLEncoding.EncodeToString(d.FillBytes(make([]byte, size)))
+	}
+	data, err := json.Marshal(key)
+	return string(data), err
+}
+
+// parseJWK 解析EC JWK，返回曲线、坐标和私钥字节(没有d时为nil)
+func parseJWK(s string) (k keyCurve, x, y *big.Int, d []byte, err error) {
+	var key jwk
+	if err = json.Unmarshal([]byte(s), &key); err != nil {
+		return k, nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if key.Kty != "EC" {
+		return k, nil, nil, nil, fmt.Errorf("%w: JWK kty must be EC", ErrInvalidKey)
+	}
+	if k, err = curveByName(key.Crv); err != nil {
+		return k, nil, nil, nil, err
+	}
+	size := k.byteSize()
+	decode := func(field, value string) ([]byte, error) {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(data) != size {
+			return nil, fmt.Errorf("%w: JWK %s must be %d bytes base64url", ErrInvalidKey, field, size)
+		}
+		return data, nil
+	}
+	xBytes, err := decode("x", key.X)
+	if err != nil {
+		return k, nil, nil, nil, err
+	}
+	yBytes, err := decode("y", key.Y)
+	if err != nil {
+		return k, nil, nil, nil, err
+	}
+	if key.D != "" {
+		if d, err = decode("d", key.D); err != nil {
+			return k, nil, nil, nil, err
+		}
+	}
+	return k, new(big.Int).SetBytes(xBytes), new(big.Int).SetBytes(yBytes), d, nil
+}
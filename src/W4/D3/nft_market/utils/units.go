@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseUnits 将十进制小数形式的代币数量(如1.5)按精度转换为最小单位，小数位数不能超过精度
+func ParseUnits(value string, decimals uint8) (*big.Int, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("%q is not a valid amount", value)
+	}
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("%q has more than %d decimals", value, decimals)
+	}
+	digits := whole + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	if strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("%q is not a valid amount", value)
+	}
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid amount", value)
+	}
+	return amount, nil
+}
+
+// FormatUnits 将最小单位的代币数量按精度格式化为十进制小数，去掉末尾的0
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	result := whole
+	if fraction != "" {
+		result += "." + fraction
+	}
+	if amount.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
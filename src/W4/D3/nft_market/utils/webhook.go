@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webhook签名密钥前缀
+const webhookSecretPrefix = "whsec_"
+
+// GenWebhookSecret 生成webhook的HMAC签名密钥
+func GenWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(buf), nil
+}
+
+// SignWebhook 计算webhook签名请求头，格式为t={unix秒},v1={hex(HMAC-SHA256(secret, "{t}.{body}"))}
+// 时间戳纳入签名，接收方可拒绝过旧的请求防止重放
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, webhookMAC(secret, timestamp, body))
+}
+
+// VerifyWebhook 校验webhook签名请求头，tolerance为允许的时间偏差，为0时不校验时间
+func VerifyWebhook(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.New("invalid webhook signature timestamp")
+			}
+			timestamp = parsed
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return errors.New("malformed webhook signature header")
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return errors.New("webhook signature timestamp outside tolerance")
+		}
+	}
+	expected := webhookMAC(secret, timestamp, body)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return errors.New("webhook signature mismatch")
+}
+
+func webhookMAC(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,223 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNoAvailableSigner 所有钱包余额都低于最低余额
+var ErrNoAvailableSigner = errors.New("no signer wallet with enough balance")
+
+// Backend 钱包池需要的链上接口
+type Backend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// Signer 白名单后端钱包，独立维护自己的nonce序列
+type Signer struct {
+	Address    common.Address
+	privateKey *ecdsa.PrivateKey
+
+	mu       sync.Mutex // 保证同一钱包nonce分配和交易发送的顺序
+	nonce    *uint64    // 本地维护的下一个nonce，nil表示需要从链上重新获取
+	inflight int        // 正在签名和广播的交易数量，广播后即释放
+	balance  *big.Int
+}
+
+// SignerStatus 钱包状态，用于管理接口展示
+type SignerStatus struct {
+	Address    string  `json:"address"`
+	Balance    string  `json:"balance"`
+	Inflight   int     `json:"inflight"`
+	Nonce      *uint64 `json:"nonce"`
+	Available  bool    `json:"available"`
+	NeedRefill bool    `json:"need_refill"`
+}
+
+// Pool 多签名钱包池，结算交易在钱包之间负载均衡
+type Pool struct {
+	backend         Backend
+	chainID         *big.Int
+	signers         []*Signer
+	minBalance      *big.Int // 低于此余额的钱包不参与结算
+	refillThreshold *big.Int // 低于此余额时告警提醒充值
+
+	mu   sync.Mutex
+	next int // 负载相同时轮询的起始位置
+}
+
+// NewPool 根据私钥列表创建钱包池
+func NewPool(backend Backend, chainID *big.Int, privateKeys []string, minBalance, refillThreshold *big.Int) (*Pool, error) {
+	if len(privateKeys) == 0 {
+		return nil, errors.New("no signer private key configured")
+	}
+	pool := &Pool{
+		backend:         backend,
+		chainID:         chainID,
+		minBalance:      minBalance,
+		refillThreshold: refillThreshold,
+	}
+	for _, keyHex := range privateKeys {
+		privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(keyHex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer private key: %w", err)
+		}
+		pool.signers = append(pool.signers, &Signer{
+			Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+			privateKey: privateKey,
+		})
+	}
+	return pool, nil
+}
+
+// Send 选取一个可用钱包，分配nonce后调用send发送交易
+// send失败时会重置该钱包的本地nonce，下次从链上重新获取
+func (p *Pool) Send(ctx context.Context, send func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *Signer, error) {
+	signer, err := p.acquire()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	if signer.nonce == nil {
+		nonce, err := p.backend.PendingNonceAt(ctx, signer.Address)
+		if err != nil {
+			p.release(signer)
+			return nil, nil, fmt.Errorf("failed to get nonce of %s: %w", signer.Address.Hex(), err)
+		}
+		signer.nonce = &nonce
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(signer.privateKey, p.chainID)
+	if err != nil {
+		p.release(signer)
+		return nil, nil, err
+	}
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(*signer.nonce)
+
+	tx, err := send(opts)
+	if err != nil {
+		signer.nonce = nil
+		p.release(signer)
+		return nil, nil, err
+	}
+	*signer.nonce++
+	return tx, signer, nil
+}
+
+// SignTx 使用池中指定钱包重新签名交易，用于以相同nonce替换卡住的交易
+func (p *Pool) SignTx(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	for _, signer := range p.signers {
+		if signer.Address == address {
+			return types.SignTx(tx, types.LatestSignerForChainID(p.chainID), signer.privateKey)
+		}
+	}
+	return nil, fmt.Errorf("signer %s is not in the pool", address.Hex())
+}
+
+// Done 交易广播后释放钱包占用，上链结果由发件箱任务处理
+func (p *Pool) Done(signer *Signer) {
+	p.release(signer)
+}
+
+// acquire 选取进行中交易最少且余额充足的钱包
+func (p *Pool) acquire() (*Signer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var chosen *Signer
+	for i := range p.signers {
+		signer := p.signers[(p.next+i)%len(p.signers)]
+		if signer.balance != nil && signer.balance.Cmp(p.minBalance) < 0 {
+			continue
+		}
+		if chosen == nil || signer.inflight < chosen.inflight {
+			chosen = signer
+		}
+	}
+	if chosen == nil {
+		return nil, ErrNoAvailableSigner
+	}
+	chosen.inflight++
+	p.next = (p.next + 1) % len(p.signers)
+	return chosen, nil
+}
+
+func (p *Pool) release(signer *Signer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if signer.inflight > 0 {
+		signer.inflight--
+	}
+}
+
+// RefreshBalances 刷新所有钱包余额，余额低于充值阈值时打印告警
+func (p *Pool) RefreshBalances(ctx context.Context) {
+	for _, signer := range p.signers {
+		balance, err := p.backend.BalanceAt(ctx, signer.Address, nil)
+		if err != nil {
+			log.Printf("failed to get balance of signer %s: %v", signer.Address.Hex(), err)
+			continue
+		}
+		p.mu.Lock()
+		signer.balance = balance
+		p.mu.Unlock()
+		if balance.Cmp(p.refillThreshold) < 0 {
+			log.Printf("[ALERT] signer %s balance %s wei is below refill threshold %s wei", signer.Address.Hex(), balance, p.refillThreshold)
+		}
+	}
+}
+
+// MonitorBalances 定时刷新钱包余额，直到ctx结束
+func (p *Pool) MonitorBalances(ctx context.Context, interval time.Duration) {
+	p.RefreshBalances(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.RefreshBalances(ctx)
+		}
+	}
+}
+
+// Status 返回所有钱包的当前状态
+func (p *Pool) Status() []SignerStatus {
+	statuses := make([]SignerStatus, 0, len(p.signers))
+	for _, signer := range p.signers {
+		status := SignerStatus{Address: signer.Address.Hex(), Available: true}
+		// 先读nonce再读余额，避免与Send中signer.mu -> p.mu的加锁顺序相反
+		signer.mu.Lock()
+		if signer.nonce != nil {
+			nonce := *signer.nonce
+			status.Nonce = &nonce
+		}
+		signer.mu.Unlock()
+
+		p.mu.Lock()
+		status.Inflight = signer.inflight
+		if signer.balance != nil {
+			status.Balance = signer.balance.String()
+			status.Available = signer.balance.Cmp(p.minBalance) >= 0
+			status.NeedRefill = signer.balance.Cmp(p.refillThreshold) < 0
+		}
+		p.mu.Unlock()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}